@@ -1152,6 +1152,7 @@ func completeSaml(c *Context, w http.ResponseWriter, r *http.Request) {
 	if user, err := samlInterface.DoLogin(encodedXML, relayProps); err != nil {
 		if action == model.OAUTH_ACTION_MOBILE {
 			err.Translate(c.T)
+			err.Params = nil
 			w.Write([]byte(err.ToJson()))
 		} else {
 			c.Err = err
@@ -170,9 +170,10 @@ func (h handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 		c.Err.Where = r.URL.Path
 
-		// Block out detailed error when not in developer mode
+		// Block out detailed error and its params when not in developer mode
 		if !*c.App.Config().ServiceSettings.EnableDeveloper {
 			c.Err.DetailedError = ""
+			c.Err.Params = nil
 		}
 
 		w.WriteHeader(c.Err.StatusCode)
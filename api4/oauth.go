@@ -465,6 +465,7 @@ func completeOAuth(c *Context, w http.ResponseWriter, r *http.Request) {
 		err.Translate(c.T)
 		l4g.Error(err.Error())
 		if action == model.OAUTH_ACTION_MOBILE {
+			err.Params = nil
 			w.Write([]byte(err.ToJson()))
 		} else {
 			utils.RenderWebAppError(w, r, err, c.App.AsymmetricSigningKey())
@@ -477,6 +478,7 @@ func completeOAuth(c *Context, w http.ResponseWriter, r *http.Request) {
 		err.Translate(c.T)
 		l4g.Error(err.Error())
 		if action == model.OAUTH_ACTION_MOBILE {
+			err.Params = nil
 			w.Write([]byte(err.ToJson()))
 		} else {
 			utils.RenderWebAppError(w, r, err, c.App.AsymmetricSigningKey())
@@ -496,6 +498,7 @@ func completeOAuth(c *Context, w http.ResponseWriter, r *http.Request) {
 			err.Translate(c.T)
 			c.Err = err
 			if action == model.OAUTH_ACTION_MOBILE {
+				err.Params = nil
 				w.Write([]byte(err.ToJson()))
 			}
 			return
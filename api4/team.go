@@ -65,6 +65,10 @@ func createTeam(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Team props are only written by the server, so any sent by the client are dropped
+	team.Props = model.StringMap{}
+	team.SetProp(model.TEAM_PROP_CREATION_SOURCE, model.TeamCreationSourceFromRequest(r))
+
 	rteam, err := c.App.CreateTeamWithUser(team, c.Session.UserId)
 	if err != nil {
 		c.Err = err
@@ -41,6 +41,18 @@ func TestCreateTeam(t *testing.T) {
 		t.Fatal("types did not match")
 	}
 
+	withProps := &model.Team{Name: GenerateTestUsername(), DisplayName: "Some Team", Type: model.TEAM_OPEN}
+	withProps.SetProp("parent_team", model.NewId())
+	withProps.SetProp(model.TEAM_PROP_CREATION_SOURCE, model.TEAM_CREATION_SOURCE_CLI)
+	propsTeam, resp := Client.CreateTeam(withProps)
+	CheckNoError(t, resp)
+	if propsTeam.GetProp("parent_team") != "" {
+		t.Fatal("props sent by the client should have been dropped")
+	}
+	if propsTeam.GetProp(model.TEAM_PROP_CREATION_SOURCE) != model.TEAM_CREATION_SOURCE_API {
+		t.Fatal("creation source should have been set by the server")
+	}
+
 	_, resp = Client.CreateTeam(rteam)
 	CheckBadRequestStatus(t, resp)
 
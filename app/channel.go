@@ -112,6 +112,25 @@ func (a *App) JoinDefaultChannels(teamId string, user *model.User, channelRole s
 	return err
 }
 
+// GetDefaultChannels returns the channels that new members of the team automatically join.
+func (a *App) GetDefaultChannels(teamId string) ([]*model.Channel, *model.AppError) {
+	channels := []*model.Channel{}
+
+	if result := <-a.Srv.Store.Channel().GetByName(teamId, "town-square", true); result.Err != nil {
+		return nil, result.Err
+	} else {
+		channels = append(channels, result.Data.(*model.Channel))
+	}
+
+	if result := <-a.Srv.Store.Channel().GetByName(teamId, "off-topic", true); result.Err == nil {
+		if offTopic := result.Data.(*model.Channel); offTopic.Type == model.CHANNEL_OPEN {
+			channels = append(channels, offTopic)
+		}
+	}
+
+	return channels, nil
+}
+
 func (a *App) CreateChannelWithUser(channel *model.Channel, userId string) (*model.Channel, *model.AppError) {
 	if channel.IsGroupOrDirect() {
 		return nil, model.NewAppError("CreateChannelWithUser", "api.channel.create_channel.direct_channel.app_error", nil, "", http.StatusBadRequest)
@@ -918,6 +937,42 @@ func (a *App) GetDeletedChannels(teamId string, offset int, limit int) (*model.C
 	}
 }
 
+// GetArchivedChannelCount returns the number of archived public and private channels in the team.
+func (a *App) GetArchivedChannelCount(teamId string) (int64, *model.AppError) {
+	ochan := a.Srv.Store.Channel().AnalyticsDeletedTypeCount(teamId, model.CHANNEL_OPEN)
+	pchan := a.Srv.Store.Channel().AnalyticsDeletedTypeCount(teamId, model.CHANNEL_PRIVATE)
+
+	count := int64(0)
+	for _, channel := range []store.StoreChannel{ochan, pchan} {
+		if result := <-channel; result.Err != nil {
+			return 0, result.Err
+		} else {
+			count += result.Data.(int64)
+		}
+	}
+
+	return count, nil
+}
+
+// GetArchivedChannelsBefore returns the channels of the team that were archived before the given time in
+// milliseconds, oldest first.
+func (a *App) GetArchivedChannelsBefore(teamId string, before int64) ([]*model.Channel, *model.AppError) {
+	if result := <-a.Srv.Store.Channel().GetDeletedBefore(teamId, before); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.Channel), nil
+	}
+}
+
+// GetAllChannels returns a page of the public and private channels of every team, including archived ones.
+func (a *App) GetAllChannels(offset int, limit int) ([]*model.Channel, *model.AppError) {
+	if result := <-a.Srv.Store.Channel().GetAllChannels(offset, limit); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.Channel), nil
+	}
+}
+
 func (a *App) GetChannelsUserNotIn(teamId string, userId string, offset int, limit int) (*model.ChannelList, *model.AppError) {
 	if result := <-a.Srv.Store.Channel().GetMoreChannels(teamId, userId, offset, limit); result.Err != nil {
 		return nil, result.Err
@@ -9,6 +9,7 @@ import (
 	"github.com/mattermost/mattermost-server/model"
 	"github.com/mattermost/mattermost-server/store"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestPermanentDeleteChannel(t *testing.T) {
@@ -110,6 +111,28 @@ func TestMoveChannel(t *testing.T) {
 	}
 }
 
+func TestGetDefaultChannels(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	channels, err := th.App.GetDefaultChannels(th.BasicTeam.Id)
+	require.Nil(t, err)
+	require.Len(t, channels, 2)
+	assert.Equal(t, "town-square", channels[0].Name)
+	assert.Equal(t, "off-topic", channels[1].Name)
+
+	// a private off-topic channel is not joined automatically
+	offTopic := channels[1]
+	offTopic.Type = model.CHANNEL_PRIVATE
+	store.Must(th.App.Srv.Store.Channel().Update(offTopic))
+	th.App.Srv.Store.Channel().InvalidateChannelByName(th.BasicTeam.Id, offTopic.Name)
+
+	channels, err = th.App.GetDefaultChannels(th.BasicTeam.Id)
+	require.Nil(t, err)
+	require.Len(t, channels, 1)
+	assert.Equal(t, "town-square", channels[0].Name)
+}
+
 func TestJoinDefaultChannelsCreatesChannelMemberHistoryRecordTownSquare(t *testing.T) {
 	th := Setup().InitBasic()
 	defer th.TearDown()
@@ -344,6 +344,7 @@ func (a *App) ImportTeam(data *TeamImportData, dryRun bool) *model.AppError {
 	}
 
 	if team.Id == "" {
+		team.SetProp(model.TEAM_PROP_CREATION_SOURCE, model.TEAM_CREATION_SOURCE_IMPORT)
 		if _, err := a.CreateTeam(team); err != nil {
 			return err
 		}
@@ -372,6 +373,8 @@ func validateTeamImportData(data *TeamImportData) *model.AppError {
 		return model.NewAppError("BulkImport", "app.import.validate_team_import_data.display_name_missing.error", nil, "", http.StatusBadRequest)
 	} else if utf8.RuneCountInString(*data.DisplayName) == 0 || utf8.RuneCountInString(*data.DisplayName) > model.TEAM_DISPLAY_NAME_MAX_RUNES {
 		return model.NewAppError("BulkImport", "app.import.validate_team_import_data.display_name_length.error", nil, "", http.StatusBadRequest)
+	} else if model.LooksLikeMojibake(*data.DisplayName) {
+		return model.NewAppError("BulkImport", "app.import.validate_team_import_data.display_name_mojibake.error", map[string]interface{}{"Suggestion": model.FixMojibake(*data.DisplayName)}, "", http.StatusBadRequest)
 	}
 
 	if data.Type == nil {
@@ -455,6 +458,8 @@ func validateChannelImportData(data *ChannelImportData) *model.AppError {
 		return model.NewAppError("BulkImport", "app.import.validate_channel_import_data.display_name_missing.error", nil, "", http.StatusBadRequest)
 	} else if utf8.RuneCountInString(*data.DisplayName) == 0 || utf8.RuneCountInString(*data.DisplayName) > model.CHANNEL_DISPLAY_NAME_MAX_RUNES {
 		return model.NewAppError("BulkImport", "app.import.validate_channel_import_data.display_name_length.error", nil, "", http.StatusBadRequest)
+	} else if model.LooksLikeMojibake(*data.DisplayName) {
+		return model.NewAppError("BulkImport", "app.import.validate_channel_import_data.display_name_mojibake.error", map[string]interface{}{"Suggestion": model.FixMojibake(*data.DisplayName)}, "", http.StatusBadRequest)
 	}
 
 	if data.Type == nil {
@@ -1704,7 +1709,9 @@ func (a *App) OldImportChannel(channel *model.Channel) *model.Channel {
 
 func (a *App) OldImportFile(timestamp time.Time, file io.Reader, teamId string, channelId string, userId string, fileName string) (*model.FileInfo, error) {
 	buf := bytes.NewBuffer(nil)
-	io.Copy(buf, file)
+	if _, _, err := model.CopyLimitedWithChecksum(buf, file, *a.Config().FileSettings.MaxFileSize); err != nil {
+		return nil, err
+	}
 	data := buf.Bytes()
 
 	fileInfo, err := a.DoUploadFile(timestamp, teamId, channelId, userId, fileName, data)
@@ -138,6 +138,16 @@ func TestImportValidateTeamImportData(t *testing.T) {
 		t.Fatal("Should have failed due to too long display_name.")
 	}
 
+	data.DisplayName = ptrStr("CafÃ©")
+	if err := validateTeamImportData(&data); err == nil {
+		t.Fatal("Should have failed due to double encoded display_name.")
+	}
+
+	data.DisplayName = ptrStr("Café")
+	if err := validateTeamImportData(&data); err != nil {
+		t.Fatal("Should have succeeded with non-ASCII display_name.")
+	}
+
 	// Test with various valid and invalid types.
 	data = TeamImportData{
 		Name:        ptrStr("teamname"),
@@ -248,6 +258,16 @@ func TestImportValidateChannelImportData(t *testing.T) {
 		t.Fatal("Should have failed due to too long display_name.")
 	}
 
+	data.DisplayName = ptrStr("CafÃ©")
+	if err := validateChannelImportData(&data); err == nil {
+		t.Fatal("Should have failed due to double encoded display_name.")
+	}
+
+	data.DisplayName = ptrStr("Café")
+	if err := validateChannelImportData(&data); err != nil {
+		t.Fatal("Should have succeeded with non-ASCII display_name.")
+	}
+
 	// Test with various valid and invalid types.
 	data = ChannelImportData{
 		Team:        ptrStr("teamname"),
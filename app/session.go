@@ -411,3 +411,11 @@ func (a *App) SearchUserAccessTokens(term string) ([]*model.UserAccessToken, *mo
 		return tokens, nil
 	}
 }
+
+func (a *App) GetActiveUserAccessTokenCountsByTeam() (map[string]int64, *model.AppError) {
+	if result := <-a.Srv.Store.UserAccessToken().CountActiveByTeam(); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(map[string]int64), nil
+	}
+}
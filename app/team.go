@@ -114,6 +114,60 @@ func (a *App) UpdateTeam(team *model.Team) (*model.Team, *model.AppError) {
 	return oldTeam, nil
 }
 
+// RenameTeam changes the name used in the URLs of the team along with its display name. Unlike UpdateTeam,
+// which leaves the name alone so that links to the team keep working, it is meant for deliberate migrations.
+func (a *App) RenameTeam(team *model.Team, newName string, newDisplayName string) (*model.Team, *model.AppError) {
+	oldTeam, err := a.GetTeam(team.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Update always keeps the stored name, so the name is changed on its own first
+	if result := <-a.Srv.Store.Team().UpdateName(oldTeam.Id, newName); result.Err != nil {
+		return nil, result.Err
+	}
+
+	oldTeam.DisplayName = newDisplayName
+	if result := <-a.Srv.Store.Team().Update(oldTeam); result.Err != nil {
+		return nil, result.Err
+	}
+
+	renamed, err := a.GetTeam(oldTeam.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	a.sendTeamEvent(renamed, model.WEBSOCKET_EVENT_UPDATE_TEAM)
+
+	return renamed, nil
+}
+
+// UpdateTeamType switches a team between open and invite only. UpdateTeam leaves the type alone, since changing it
+// decides who can join the team. Open invites are turned off when the team becomes invite only.
+func (a *App) UpdateTeamType(team *model.Team, teamType string) (*model.Team, *model.AppError) {
+	oldTeam, err := a.GetTeam(team.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	oldTeam.Type = teamType
+	if teamType == model.TEAM_INVITE {
+		oldTeam.AllowOpenInvite = false
+	}
+
+	if err := model.ValidateTeamInviteConsistency(oldTeam); err != nil {
+		return nil, err
+	}
+
+	if result := <-a.Srv.Store.Team().Update(oldTeam); result.Err != nil {
+		return nil, result.Err
+	}
+
+	a.sendTeamEvent(oldTeam, model.WEBSOCKET_EVENT_UPDATE_TEAM)
+
+	return oldTeam, nil
+}
+
 func (a *App) PatchTeam(teamId string, patch *model.TeamPatch) (*model.Team, *model.AppError) {
 	team, err := a.GetTeam(teamId)
 	if err != nil {
@@ -132,6 +186,61 @@ func (a *App) PatchTeam(teamId string, patch *model.TeamPatch) (*model.Team, *mo
 	return updatedTeam, nil
 }
 
+func (a *App) SetTeamProp(teamId string, key string, value string) (*model.Team, *model.AppError) {
+	return a.SetTeamProps(teamId, map[string]string{key: value})
+}
+
+// SetTeamProps sets every one of props on the team with a single update. All of the keys are checked first, so
+// either every property is saved or none is.
+func (a *App) SetTeamProps(teamId string, props map[string]string) (*model.Team, *model.AppError) {
+	for key := range props {
+		if !model.IsValidAlphaNumHyphenUnderscore(key, false) {
+			return nil, model.NewAppError("SetTeamProps", "app.team.set_prop.invalid_key.app_error", nil, "key="+key, http.StatusBadRequest)
+		}
+	}
+
+	team, err := a.GetTeam(teamId)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range props {
+		team.SetProp(key, value)
+	}
+
+	return a.updateTeamProps(team)
+}
+
+func (a *App) DeleteTeamProp(teamId string, key string) (*model.Team, *model.AppError) {
+	team, err := a.GetTeam(teamId)
+	if err != nil {
+		return nil, err
+	}
+
+	team.DelProp(key)
+
+	return a.updateTeamProps(team)
+}
+
+// GetTeamStorageUsage returns the number of bytes used by the files posted in the team's channels.
+func (a *App) GetTeamStorageUsage(teamId string) (int64, *model.AppError) {
+	if result := <-a.Srv.Store.FileInfo().GetStorageUsageForTeam(teamId); result.Err != nil {
+		return 0, result.Err
+	} else {
+		return result.Data.(int64), nil
+	}
+}
+
+func (a *App) updateTeamProps(team *model.Team) (*model.Team, *model.AppError) {
+	if result := <-a.Srv.Store.Team().Update(team); result.Err != nil {
+		return nil, result.Err
+	}
+
+	a.sendTeamEvent(team, model.WEBSOCKET_EVENT_UPDATE_TEAM)
+
+	return team, nil
+}
+
 func (a *App) sendTeamEvent(team *model.Team, event string) {
 	sanitizedTeam := &model.Team{}
 	*sanitizedTeam = *team
@@ -380,6 +489,178 @@ func (a *App) GetTeamByInviteId(inviteId string) (*model.Team, *model.AppError)
 	}
 }
 
+// GetTeamInviteLink returns the link that users can follow to join the team with its invite id.
+func (a *App) GetTeamInviteLink(team *model.Team) string {
+	siteURL := strings.TrimRight(*a.Config().ServiceSettings.SiteURL, "/")
+	return fmt.Sprintf("%s/signup_user_complete/?id=%s", siteURL, url.QueryEscape(team.InviteId))
+}
+
+// CanMergeTeams returns the reasons, if any, that the channels and members of source can't be moved into dest.
+// The default channels of the two teams are combined by a merge, so they aren't reported as name collisions.
+// Teams don't carry a permission scheme or a data retention policy of their own in this version, so there is
+// no scheme or retention mismatch to report; those checks belong here once teams gain such settings.
+func (a *App) CanMergeTeams(source, dest *model.Team) []string {
+	if source.Id == dest.Id {
+		return []string{"the source and destination are the same team"}
+	}
+
+	reasons := []string{}
+	for _, team := range []*model.Team{source, dest} {
+		if team.DeleteAt > 0 {
+			reasons = append(reasons, "team '"+team.Name+"' is archived")
+		}
+	}
+
+	schan := a.Srv.Store.Channel().GetAll(source.Id)
+	dchan := a.Srv.Store.Channel().GetAll(dest.Id)
+
+	var sourceChannels, destChannels []*model.Channel
+	if result := <-schan; result.Err != nil {
+		reasons = append(reasons, "unable to get the channels of team '"+source.Name+"': "+result.Err.Error())
+	} else {
+		sourceChannels = result.Data.([]*model.Channel)
+	}
+	if result := <-dchan; result.Err != nil {
+		reasons = append(reasons, "unable to get the channels of team '"+dest.Name+"': "+result.Err.Error())
+	} else {
+		destChannels = result.Data.([]*model.Channel)
+	}
+
+	if sourceChannels != nil && destChannels != nil {
+		destNames := make(map[string]bool, len(destChannels))
+		channelCount := int64(0)
+		for _, channel := range destChannels {
+			destNames[channel.Name] = true
+			if channel.DeleteAt == 0 {
+				channelCount++
+			}
+		}
+
+		for _, channel := range sourceChannels {
+			if channel.Name == model.DEFAULT_CHANNEL || channel.Name == model.OFF_TOPIC_CHANNEL {
+				continue
+			}
+
+			if destNames[channel.Name] {
+				reasons = append(reasons, "channel '"+channel.Name+"' exists in both teams")
+			}
+			if channel.DeleteAt == 0 {
+				channelCount++
+			}
+		}
+
+		if limit := *a.Config().TeamSettings.MaxChannelsPerTeam; channelCount > limit {
+			reasons = append(reasons, fmt.Sprintf("the merged team would have %v channels, over the limit of %v", channelCount, limit))
+		}
+	}
+
+	members := map[string]bool{}
+	for _, team := range []*model.Team{source, dest} {
+		for offset := 0; ; offset += 1000 {
+			teamMembers, err := a.GetTeamMembers(team.Id, offset, 1000)
+			if err != nil {
+				return append(reasons, "unable to get the members of team '"+team.Name+"': "+err.Error())
+			}
+			for _, member := range teamMembers {
+				members[member.UserId] = true
+			}
+			if len(teamMembers) < 1000 {
+				break
+			}
+		}
+	}
+
+	if limit := *a.Config().TeamSettings.MaxUsersPerTeam; len(members) > limit {
+		reasons = append(reasons, fmt.Sprintf("the merged team would have %v members, over the limit of %v", len(members), limit))
+	}
+
+	return reasons
+}
+
+// CloneTeam creates a team named name with the type, allowed domains and description of source and an empty
+// copy of each of its active channels. The default channels of the new team are created as usual rather than
+// copied. It returns the new team along with the channels that were copied into it.
+func (a *App) CloneTeam(source *model.Team, name, displayName string) (*model.Team, []*model.Channel, *model.AppError) {
+	team := &model.Team{
+		Name:            name,
+		DisplayName:     displayName,
+		Description:     source.Description,
+		Email:           source.Email,
+		Type:            source.Type,
+		CompanyName:     source.CompanyName,
+		AllowedDomains:  source.AllowedDomains,
+		AllowOpenInvite: source.AllowOpenInvite,
+	}
+
+	var channels []*model.Channel
+	if result := <-a.Srv.Store.Channel().GetAll(source.Id); result.Err != nil {
+		return nil, nil, result.Err
+	} else {
+		channels = result.Data.([]*model.Channel)
+	}
+
+	rteam, err := a.CreateTeam(team)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cloned := []*model.Channel{}
+	for _, channel := range channels {
+		if channel.DeleteAt > 0 || channel.Name == model.DEFAULT_CHANNEL || channel.Name == model.OFF_TOPIC_CHANNEL {
+			continue
+		}
+
+		rchannel, err := a.CreateChannel(&model.Channel{
+			TeamId:      rteam.Id,
+			Type:        channel.Type,
+			Name:        channel.Name,
+			DisplayName: channel.DisplayName,
+			Header:      channel.Header,
+			Purpose:     channel.Purpose,
+		}, false)
+		if err != nil {
+			return rteam, cloned, err
+		}
+		cloned = append(cloned, rchannel)
+	}
+
+	return rteam, cloned, nil
+}
+
+// CloneTeamMembers adds every member of source to dest with the same team roles and returns the number of
+// users that were added.
+func (a *App) CloneTeamMembers(source, dest *model.Team) (int, *model.AppError) {
+	added := 0
+	for offset := 0; ; offset += 1000 {
+		members, err := a.GetTeamMembers(source.Id, offset, 1000)
+		if err != nil {
+			return added, err
+		}
+
+		for _, member := range members {
+			if member.DeleteAt > 0 {
+				continue
+			}
+
+			user, err := a.GetUser(member.UserId)
+			if err != nil {
+				return added, err
+			}
+			if err := a.JoinUserToTeam(dest, user, ""); err != nil {
+				return added, err
+			}
+			if _, err := a.UpdateTeamMemberRoles(dest.Id, user.Id, member.Roles); err != nil {
+				return added, err
+			}
+			added++
+		}
+
+		if len(members) < 1000 {
+			return added, nil
+		}
+	}
+}
+
 func (a *App) GetAllTeams() ([]*model.Team, *model.AppError) {
 	if result := <-a.Srv.Store.Team().GetAll(); result.Err != nil {
 		return nil, result.Err
@@ -396,6 +677,26 @@ func (a *App) GetAllTeamsPage(offset int, limit int) ([]*model.Team, *model.AppE
 	}
 }
 
+// GetAllTeamsPageFiltered returns a page of teams in order of id, leaving out archived teams unless includeDeleted
+// is set and, when teamType isn't empty, teams of any other type.
+func (a *App) GetAllTeamsPageFiltered(teamType string, includeDeleted bool, offset int, limit int) ([]*model.Team, *model.AppError) {
+	if result := <-a.Srv.Store.Team().GetAllPageFiltered(teamType, includeDeleted, offset, limit); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.Team), nil
+	}
+}
+
+// SearchAllTeamsBySubstring returns every team whose name or display name contains term, ignoring case, for admin
+// tools. The team search API keeps using SearchAllTeams, which matches prefixes.
+func (a *App) SearchAllTeamsBySubstring(term string) ([]*model.Team, *model.AppError) {
+	if result := <-a.Srv.Store.Team().SearchAllSubstring(term); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.Team), nil
+	}
+}
+
 func (a *App) GetAllOpenTeams() ([]*model.Team, *model.AppError) {
 	if result := <-a.Srv.Store.Team().GetAllTeamListing(); result.Err != nil {
 		return nil, result.Err
@@ -851,6 +1152,35 @@ func (a *App) SoftDeleteTeam(teamId string) *model.AppError {
 	return nil
 }
 
+// RestoreTeam reverses SoftDeleteTeam by clearing the team's DeleteAt, and sends a team update event so that
+// connected clients show the team again. Members, channels and posts are kept while a team is archived, so
+// nothing else needs to be restored.
+func (a *App) RestoreTeam(teamId string) *model.AppError {
+	team, err := a.GetTeam(teamId)
+	if err != nil {
+		return err
+	}
+
+	team.DeleteAt = 0
+	if result := <-a.Srv.Store.Team().Update(team); result.Err != nil {
+		return result.Err
+	}
+
+	a.sendTeamEvent(team, model.WEBSOCKET_EVENT_UPDATE_TEAM)
+
+	return nil
+}
+
+// GetTeamMemberCounts returns the number of active members of every team with at least one, keyed by team id,
+// with a single query. Teams without active members are left out.
+func (a *App) GetTeamMemberCounts() (map[string]int64, *model.AppError) {
+	if result := <-a.Srv.Store.Team().CountActiveMembersByTeam(); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(map[string]int64), nil
+	}
+}
+
 func (a *App) GetTeamStats(teamId string) (*model.TeamStats, *model.AppError) {
 	tchan := a.Srv.Store.Team().GetTotalMemberCount(teamId)
 	achan := a.Srv.Store.Team().GetActiveMemberCount(teamId)
@@ -873,6 +1203,34 @@ func (a *App) GetTeamStats(teamId string) (*model.TeamStats, *model.AppError) {
 	return stats, nil
 }
 
+// GetTeamEngagement returns the activity of the team along with its engagement score, counting the posts and
+// finding the most recent one across all of its public and private channels, including archived ones. System
+// messages such as join and leave notices aren't counted as activity.
+func (a *App) GetTeamEngagement(team *model.Team) (*model.TeamEngagement, *model.AppError) {
+	stats, err := a.GetTeamStats(team.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	engagement := &model.TeamEngagement{
+		TeamId:        team.Id,
+		TeamName:      team.Name,
+		TotalMembers:  stats.TotalMemberCount,
+		ActiveMembers: stats.ActiveMemberCount,
+	}
+
+	if result := <-a.Srv.Store.Post().AnalyticsTeamPostActivity(team.Id); result.Err != nil {
+		return nil, result.Err
+	} else {
+		activity := result.Data.(*model.TeamPostActivity)
+		engagement.Posts = activity.Posts
+		engagement.LastPostAt = activity.LastPostAt
+	}
+
+	engagement.ComputeScore(model.GetMillis())
+	return engagement, nil
+}
+
 func (a *App) GetTeamIdFromQuery(query url.Values) (string, *model.AppError) {
 	hash := query.Get("h")
 	inviteId := query.Get("id")
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/mattermost/mattermost-server/model"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCreateTeam(t *testing.T) {
@@ -481,3 +482,72 @@ func TestJoinUserToTeam(t *testing.T) {
 		}
 	})
 }
+
+func TestCanMergeTeams(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	t.Run("mergeable", func(t *testing.T) {
+		source := th.CreateTeam()
+		dest := th.CreateTeam()
+		th.CreateChannel(source)
+		th.CreateChannel(dest)
+		th.LinkUserToTeam(th.BasicUser, source)
+		th.LinkUserToTeam(th.BasicUser, dest)
+
+		require.Empty(t, th.App.CanMergeTeams(source, dest))
+	})
+
+	t.Run("same team", func(t *testing.T) {
+		require.Equal(t, []string{"the source and destination are the same team"}, th.App.CanMergeTeams(th.BasicTeam, th.BasicTeam))
+	})
+
+	t.Run("archived", func(t *testing.T) {
+		source := th.CreateTeam()
+		dest := th.CreateTeam()
+		require.Nil(t, th.App.SoftDeleteTeam(dest.Id))
+		dest, err := th.App.GetTeam(dest.Id)
+		require.Nil(t, err)
+
+		require.Equal(t, []string{"team '" + dest.Name + "' is archived"}, th.App.CanMergeTeams(source, dest))
+	})
+
+	t.Run("channel collision", func(t *testing.T) {
+		source := th.CreateTeam()
+		dest := th.CreateTeam()
+		channel := th.CreateChannel(source)
+		_, err := th.App.CreateChannel(&model.Channel{
+			DisplayName: channel.DisplayName,
+			Name:        channel.Name,
+			Type:        model.CHANNEL_OPEN,
+			TeamId:      dest.Id,
+		}, false)
+		require.Nil(t, err)
+
+		require.Equal(t, []string{"channel '" + channel.Name + "' exists in both teams"}, th.App.CanMergeTeams(source, dest))
+	})
+
+	t.Run("limits", func(t *testing.T) {
+		source := th.CreateTeam()
+		dest := th.CreateTeam()
+		th.CreateChannel(source)
+		th.LinkUserToTeam(th.BasicUser, source)
+		th.LinkUserToTeam(th.BasicUser2, dest)
+
+		maxChannels := *th.App.Config().TeamSettings.MaxChannelsPerTeam
+		maxUsers := *th.App.Config().TeamSettings.MaxUsersPerTeam
+		defer th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.TeamSettings.MaxChannelsPerTeam = maxChannels
+			*cfg.TeamSettings.MaxUsersPerTeam = maxUsers
+		})
+		th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.TeamSettings.MaxChannelsPerTeam = 2
+			*cfg.TeamSettings.MaxUsersPerTeam = 1
+		})
+
+		require.Equal(t, []string{
+			"the merged team would have 3 channels, over the limit of 2",
+			"the merged team would have 2 members, over the limit of 1",
+		}, th.App.CanMergeTeams(source, dest))
+	})
+}
@@ -616,6 +616,74 @@ func (a *App) GetUsersByUsernames(usernames []string, asAdmin bool) ([]*model.Us
 	}
 }
 
+// GetUsersByIdentifiers resolves each identifier to the user with that email, username or id, tried in that
+// order, using one query per kind of identifier. The result lines up with identifiers and holds nil for the
+// identifiers that don't match any user. The users aren't sanitized.
+func (a *App) GetUsersByIdentifiers(identifiers []string) ([]*model.User, *model.AppError) {
+	emails := []string{}
+	usernames := []string{}
+	for _, identifier := range identifiers {
+		if model.ClassifyIdentifier(identifier) == model.IDENTIFIER_EMAIL {
+			emails = append(emails, strings.ToLower(identifier))
+		} else {
+			usernames = append(usernames, identifier)
+		}
+	}
+
+	byEmail := map[string]*model.User{}
+	if len(emails) > 0 {
+		result := <-a.Srv.Store.User().GetByEmails(emails)
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		for _, user := range result.Data.([]*model.User) {
+			byEmail[user.Email] = user
+		}
+	}
+
+	byUsername := map[string]*model.User{}
+	if len(usernames) > 0 {
+		result := <-a.Srv.Store.User().GetProfilesByUsernames(usernames, "")
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		for _, user := range result.Data.([]*model.User) {
+			byUsername[user.Username] = user
+		}
+	}
+
+	ids := []string{}
+	for _, username := range usernames {
+		if _, ok := byUsername[username]; !ok && model.ClassifyIdentifier(username) == model.IDENTIFIER_ID {
+			ids = append(ids, username)
+		}
+	}
+
+	byId := map[string]*model.User{}
+	if len(ids) > 0 {
+		result := <-a.Srv.Store.User().GetByIds(ids)
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		for _, user := range result.Data.([]*model.User) {
+			byId[user.Id] = user
+		}
+	}
+
+	users := make([]*model.User, len(identifiers))
+	for i, identifier := range identifiers {
+		if model.ClassifyIdentifier(identifier) == model.IDENTIFIER_EMAIL {
+			users[i] = byEmail[strings.ToLower(identifier)]
+		} else if user, ok := byUsername[identifier]; ok {
+			users[i] = user
+		} else {
+			users[i] = byId[identifier]
+		}
+	}
+
+	return users, nil
+}
+
 func (a *App) sanitizeProfiles(users []*model.User, asAdmin bool) []*model.User {
 	for _, u := range users {
 		a.SanitizeProfile(u, asAdmin)
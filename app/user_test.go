@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/mattermost/mattermost-server/einterfaces"
 	"github.com/mattermost/mattermost-server/model"
@@ -41,6 +42,40 @@ func TestIsUsernameTaken(t *testing.T) {
 	}
 }
 
+func TestGetUsersByIdentifiers(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	user := th.BasicUser
+	user2 := th.BasicUser2
+	missing := "missing" + model.NewId()
+
+	users, err := th.App.GetUsersByIdentifiers([]string{
+		user2.Id,
+		strings.ToUpper(user.Email),
+		missing,
+		user2.Username,
+		model.NewId(),
+		missing + "@example.com",
+		user.Id,
+	})
+	require.Nil(t, err)
+	require.Len(t, users, 7)
+
+	for i, expected := range []*model.User{user2, user, nil, user2, nil, nil, user} {
+		if expected == nil {
+			require.Nil(t, users[i], "identifier %v shouldn't match a user", i)
+		} else {
+			require.NotNil(t, users[i], "identifier %v should match a user", i)
+			require.Equal(t, expected.Id, users[i].Id)
+		}
+	}
+
+	users, err = th.App.GetUsersByIdentifiers([]string{})
+	require.Nil(t, err)
+	require.Empty(t, users)
+}
+
 func TestCheckUserDomain(t *testing.T) {
 	th := Setup().InitBasic()
 	defer th.TearDown()
@@ -363,6 +363,11 @@ func (a *App) GetIncomingWebhook(hookId string) (*model.IncomingWebhook, *model.
 	}
 }
 
+// GetIncomingWebhookURL returns the URL that integrations post to in order to use the webhook.
+func (a *App) GetIncomingWebhookURL(hook *model.IncomingWebhook) string {
+	return strings.TrimRight(*a.Config().ServiceSettings.SiteURL, "/") + "/hooks/" + hook.Id
+}
+
 func (a *App) GetIncomingWebhooksForTeamPage(teamId string, page, perPage int) ([]*model.IncomingWebhook, *model.AppError) {
 	if !a.Config().ServiceSettings.EnableIncomingWebhooks {
 		return nil, model.NewAppError("GetIncomingWebhooksForTeamPage", "api.incoming_webhook.disabled.app_error", nil, "", http.StatusNotImplemented)
@@ -94,6 +94,7 @@ func ReturnWebSocketError(conn *WebConn, r *model.WebSocketRequest, err *model.A
 	l4g.Error(utils.T("api.web_socket_router.log.error"), r.Seq, conn.UserId, err.SystemMessage(utils.T), err.DetailedError)
 
 	err.DetailedError = ""
+	err.Params = nil
 	errorResp := model.NewWebSocketError(r.Seq, err)
 
 	conn.Send <- errorResp
@@ -38,6 +38,16 @@ func CheckCommand(t *testing.T, args ...string) string {
 	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(string(output)), "PASS"))
 }
 
+// CheckCommandFails runs the command, requires it to fail and returns its output, so that tests can check what a
+// failing command reported.
+func CheckCommandFails(t *testing.T, args ...string) string {
+	path, err := os.Executable()
+	require.NoError(t, err)
+	output, err := exec.Command(path, execArgs(t, args)...).CombinedOutput()
+	require.Error(t, err, string(output))
+	return strings.TrimSpace(string(output))
+}
+
 func RunCommand(t *testing.T, args ...string) error {
 	path, err := os.Executable()
 	require.NoError(t, err)
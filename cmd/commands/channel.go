@@ -6,6 +6,7 @@ package commands
 import (
 	"errors"
 	"fmt"
+	"net/http"
 
 	"github.com/mattermost/mattermost-server/app"
 	"github.com/mattermost/mattermost-server/cmd"
@@ -100,6 +101,17 @@ Channel can be specified by [team]:[channel]. ie. myteam:mychannel or by channel
 	RunE:    modifyChannelCmdF,
 }
 
+var CheckOrphansChannelsCmd = &cobra.Command{
+	Use:   "check-orphans",
+	Short: "Find channels whose team no longer exists",
+	Long: `List public and private channels whose team id doesn't belong to any existing team, including archived channels.
+Use --fix to archive the active ones, or --fix with --team to move all of them to an existing team instead.`,
+	Example: `  channel check-orphans
+  channel check-orphans --fix
+  channel check-orphans --fix --team myteam`,
+	RunE: checkOrphansChannelsCmdF,
+}
+
 func init() {
 	ChannelCreateCmd.Flags().String("name", "", "Channel Name")
 	ChannelCreateCmd.Flags().String("display_name", "", "Channel Display Name")
@@ -116,6 +128,10 @@ func init() {
 	ModifyChannelCmd.Flags().Bool("public", false, "Convert the channel to a public channel")
 	ModifyChannelCmd.Flags().String("username", "", "Required. Username who changes the channel privacy.")
 
+	CheckOrphansChannelsCmd.Flags().Bool("fix", false, "Archive the orphaned channels, or move them to --team.")
+	CheckOrphansChannelsCmd.Flags().String("team", "", "Move the orphaned channels to this team instead of archiving them.")
+	CheckOrphansChannelsCmd.Flags().Bool("confirm", false, "Confirm you really want to update the channels.")
+
 	ChannelCmd.AddCommand(
 		ChannelCreateCmd,
 		RemoveChannelUsersCmd,
@@ -126,6 +142,7 @@ func init() {
 		MoveChannelsCmd,
 		RestoreChannelsCmd,
 		ModifyChannelCmd,
+		CheckOrphansChannelsCmd,
 	)
 
 	cmd.RootCmd.AddCommand(ChannelCmd)
@@ -195,7 +212,10 @@ func removeChannelUsersCmdF(command *cobra.Command, args []string) error {
 		return errors.New("Unable to find channel '" + args[0] + "'")
 	}
 
-	users := getUsersFromUserArgs(a, args[1:])
+	users, appErr := getUsersFromUserArgs(a, args[1:])
+	if appErr != nil {
+		return errors.New("Unable to look up the users. Error: " + appErr.Error())
+	}
 	for i, user := range users {
 		removeUserFromChannel(a, channel, user, args[i+1])
 	}
@@ -228,7 +248,10 @@ func addChannelUsersCmdF(command *cobra.Command, args []string) error {
 		return errors.New("Unable to find channel '" + args[0] + "'")
 	}
 
-	users := getUsersFromUserArgs(a, args[1:])
+	users, appErr := getUsersFromUserArgs(a, args[1:])
+	if appErr != nil {
+		return errors.New("Unable to look up the users. Error: " + appErr.Error())
+	}
 	for i, user := range users {
 		addUserToChannel(a, channel, user, args[i+1])
 	}
@@ -485,3 +508,100 @@ func modifyChannelCmdF(command *cobra.Command, args []string) error {
 
 	return nil
 }
+
+func checkOrphansChannelsCmdF(command *cobra.Command, args []string) error {
+	a, err := cmd.InitDBCommandContextCobra(command)
+	if err != nil {
+		return err
+	}
+
+	fixFlag, _ := command.Flags().GetBool("fix")
+	teamArg, _ := command.Flags().GetString("team")
+	if teamArg != "" && !fixFlag {
+		return errors.New("--team can only be used with --fix.")
+	}
+
+	var team *model.Team
+	if teamArg != "" {
+		if team = getTeamFromTeamArg(a, teamArg); team == nil {
+			return errors.New("Unable to find team '" + teamArg + "'")
+		}
+	}
+
+	ctx, cancel := cmd.InterruptContext()
+	defer cancel()
+
+	teamExists := map[string]bool{}
+	orphans := []*model.Channel{}
+	scanned, err := cmd.ForEachChannel(ctx, a, cmd.DEFAULT_SCAN_PAGE_SIZE, func(channel *model.Channel) error {
+		exists, ok := teamExists[channel.TeamId]
+		if !ok {
+			if _, err := a.GetTeam(channel.TeamId); err == nil {
+				exists = true
+			} else if err.StatusCode != http.StatusNotFound {
+				return err
+			}
+			teamExists[channel.TeamId] = exists
+		}
+		if exists {
+			return nil
+		}
+
+		orphans = append(orphans, channel)
+		line := fmt.Sprintf("%v (%v) references missing team %v", channel.Name, channel.Id, channel.TeamId)
+		if channel.DeleteAt > 0 {
+			line += " (archived)"
+		}
+		cmd.CommandPrintln(line)
+		return nil
+	})
+	if err := cmd.ScanError(err, "Scan interrupted after %v channels, %v orphaned channels found so far.", scanned, len(orphans)); err != nil {
+		return err
+	}
+
+	if len(orphans) == 0 {
+		cmd.CommandPrettyPrintln("No orphaned channels found.")
+		return nil
+	}
+
+	if !fixFlag {
+		return nil
+	}
+
+	confirmFlag, _ := command.Flags().GetBool("confirm")
+	if !confirmFlag {
+		var confirm string
+		if team != nil {
+			cmd.CommandPrettyPrintln("Are you sure you want to move the channels listed above to team '" + team.Name + "'? (YES/NO): ")
+		} else {
+			cmd.CommandPrettyPrintln("Are you sure you want to archive the channels listed above? (YES/NO): ")
+		}
+		fmt.Scanln(&confirm)
+		if confirm != "YES" {
+			return errors.New("ABORTED: You did not answer YES exactly, in all capitals.")
+		}
+	}
+
+	errorLog := cmd.NewDefaultThrottledLogger(cmd.CommandPrintErrorln)
+	progressLog := cmd.NewDefaultThrottledLogger(cmd.CommandPrettyPrintln)
+	for _, channel := range orphans {
+		if team != nil {
+			channel.TeamId = team.Id
+			if _, err := a.UpdateChannel(channel); err != nil {
+				errorLog.Println("Unable to move channel '" + channel.Name + "' error: " + err.Error())
+			} else {
+				progressLog.Println("Moved channel '" + channel.Name + "' to team '" + team.Name + "'")
+			}
+		} else if channel.DeleteAt == 0 {
+			if err := a.DeleteChannel(channel, ""); err != nil {
+				errorLog.Println("Unable to archive channel '" + channel.Name + "' error: " + err.Error())
+			} else {
+				progressLog.Println("Archived channel '" + channel.Name + "'")
+			}
+		}
+	}
+	errorLog.Flush()
+	progressLog.Flush()
+
+	return nil
+}
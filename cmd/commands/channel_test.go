@@ -115,3 +115,47 @@ func TestCreateChannel(t *testing.T) {
 	name = name + "-private"
 	cmd.CheckCommand(t, "channel", "create", "--display_name", name, "--team", th.BasicTeam.Name, "--private", "--name", name)
 }
+
+func TestCheckOrphansChannels(t *testing.T) {
+	th := api.Setup().InitBasic()
+	defer th.TearDown()
+
+	newOrphan := func() *model.Channel {
+		result := <-th.App.Srv.Store.Channel().Save(&model.Channel{
+			TeamId:      model.NewId(),
+			DisplayName: "Orphan",
+			Name:        "orphan" + model.NewId(),
+			Type:        model.CHANNEL_OPEN,
+		}, -1)
+		require.Nil(t, result.Err)
+		return result.Data.(*model.Channel)
+	}
+
+	orphan := newOrphan()
+
+	output := cmd.CheckCommand(t, "channel", "check-orphans")
+	require.Contains(t, output, orphan.Name)
+	require.Contains(t, output, orphan.TeamId)
+	require.NotContains(t, output, th.BasicChannel.Name)
+
+	require.Error(t, cmd.RunCommand(t, "channel", "check-orphans", "--team", th.BasicTeam.Name))
+
+	cmd.CheckCommand(t, "channel", "check-orphans", "--fix", "--confirm")
+
+	archived, err := th.App.GetChannel(orphan.Id)
+	require.Nil(t, err)
+	require.NotEqual(t, int64(0), archived.DeleteAt)
+
+	moved := newOrphan()
+	cmd.CheckCommand(t, "channel", "check-orphans", "--fix", "--team", th.BasicTeam.Name, "--confirm")
+
+	for _, id := range []string{orphan.Id, moved.Id} {
+		channel, err := th.App.GetChannel(id)
+		require.Nil(t, err)
+		require.Equal(t, th.BasicTeam.Id, channel.TeamId)
+	}
+
+	output = cmd.CheckCommand(t, "channel", "check-orphans")
+	require.NotContains(t, output, orphan.Name)
+	require.NotContains(t, output, moved.Name)
+}
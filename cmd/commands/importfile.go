@@ -0,0 +1,214 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package commands
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+// ValidateImportFile reads the whole of a CSV import file and checks that its header has every one of
+// requiredCols, that no row is missing a required value and that the ids, emails and usernames in it are
+// well formed. Every problem found is returned, so that commands can refuse to apply a file before
+// making any changes. Column names are matched case-insensitively, and lines starting with # are ignored.
+func ValidateImportFile(path string, requiredCols []string) (rowCount int, errs []string) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, []string{err.Error()}
+	}
+	defer file.Close()
+
+	reader := newImportReader(file)
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return 0, []string{"the file is empty"}
+	} else if err != nil {
+		return 0, []string{err.Error()}
+	}
+	header = normalizeImportHeader(header)
+
+	if missing := model.RequireKeys(header, lowerImportColumns(requiredCols)...); len(missing) > 0 {
+		return 0, []string{"missing required columns: " + strings.Join(missing, ", ")}
+	}
+
+	records, errs := readImportRecords(reader, header, requiredCols, nil, false)
+	return len(records), errs
+}
+
+// readImportRecords reads the rest of an import file as rows under header, checking each of them like
+// ValidateImportFile does. first is a row that was already read from reader, or nil. With allowShort, rows may
+// leave out trailing columns, which are then empty. Every row is returned, along with every problem found.
+func readImportRecords(reader *csv.Reader, header, requiredCols []string, first []string, allowShort bool) (records []importRecord, errs []string) {
+	isRequired := make(map[string]bool, len(requiredCols))
+	for _, col := range lowerImportColumns(requiredCols) {
+		isRequired[col] = true
+	}
+
+	for record := first; ; record = nil {
+		if record == nil {
+			var err error
+			if record, err = reader.Read(); err == io.EOF {
+				break
+			} else if err != nil {
+				errs = append(errs, err.Error())
+				break
+			}
+		}
+
+		row := len(records) + 1
+		line, _ := reader.FieldPos(0)
+		values := make(map[string]string, len(header))
+		records = append(records, importRecord{Line: line, Values: values})
+
+		if allowShort && len(record) < len(header) {
+			record = append(record, make([]string, len(header)-len(record))...)
+		}
+		if len(record) != len(header) {
+			errs = append(errs, fmt.Sprintf("row %v: expected %v columns but found %v", row, len(header), len(record)))
+			continue
+		}
+
+		for i, value := range record {
+			value = strings.TrimSpace(value)
+			values[header[i]] = value
+			if value == "" {
+				if isRequired[header[i]] {
+					errs = append(errs, fmt.Sprintf("row %v: missing value for column %v", row, header[i]))
+				}
+				continue
+			}
+
+			if err := validateImportValue(header[i], value); err != "" {
+				errs = append(errs, fmt.Sprintf("row %v: %v", row, err))
+			}
+		}
+	}
+
+	return records, errs
+}
+
+func newImportReader(r io.Reader) *csv.Reader {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	return reader
+}
+
+func normalizeImportHeader(header []string) []string {
+	for i, col := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(col))
+	}
+	return header
+}
+
+func lowerImportColumns(cols []string) []string {
+	lower := make([]string, len(cols))
+	for i, col := range cols {
+		lower[i] = strings.ToLower(col)
+	}
+	return lower
+}
+
+// isImportHeader reports whether record is a header row, which is the case when every one of its values is one
+// of columns.
+func isImportHeader(record []string, columns []string) bool {
+	known := make(map[string]bool, len(columns))
+	for _, col := range lowerImportColumns(columns) {
+		known[col] = true
+	}
+
+	for _, value := range record {
+		if !known[strings.ToLower(strings.TrimSpace(value))] {
+			return false
+		}
+	}
+
+	return len(record) > 0
+}
+
+// importRecord is a row of a CSV import file keyed by lowercased column name, along with the line it starts on.
+type importRecord struct {
+	Line   int
+	Values map[string]string
+}
+
+// readImportFile reads a CSV import file whose columns are among columns and returns its rows only if
+// readImportRecords finds no problems, so that a command can refuse a bad file before making any changes. If the
+// first row is a header, it gives the order of the columns. Otherwise the rows are read in the order of columns
+// and may leave out trailing columns. The whole file is read with one CSV reader, so quoted values can span lines.
+func readImportFile(path string, columns, requiredCols []string) ([]importRecord, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := newImportReader(file)
+	first, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("Invalid file %v:\nthe file is empty", path)
+	} else if err != nil {
+		return nil, fmt.Errorf("Invalid file %v:\n%v", path, err.Error())
+	}
+
+	header, allowShort := lowerImportColumns(columns), true
+	if isImportHeader(first, columns) {
+		header, first, allowShort = normalizeImportHeader(first), nil, false
+		if missing := model.RequireKeys(header, lowerImportColumns(requiredCols)...); len(missing) > 0 {
+			return nil, fmt.Errorf("Invalid file %v:\nmissing required columns: %v", path, strings.Join(missing, ", "))
+		}
+	}
+
+	records, errs := readImportRecords(reader, header, requiredCols, first, allowShort)
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("Invalid file %v:\n%v", path, strings.Join(errs, "\n"))
+	}
+
+	return records, nil
+}
+
+func validateImportValue(col, value string) string {
+	switch {
+	case col == "email":
+		if !model.IsValidEmail(strings.ToLower(value)) {
+			return fmt.Sprintf("invalid email %q", value)
+		}
+	case col == "username":
+		if !model.IsValidUsername(strings.ToLower(value)) {
+			return fmt.Sprintf("invalid username %q", value)
+		}
+	case col == "id" || strings.HasSuffix(col, "_id"):
+		if !model.IsValidId(value) {
+			return fmt.Sprintf("invalid %v %q", col, value)
+		}
+	}
+
+	return ""
+}
+
+// ValidateReferences returns the ids for which exists returns false, in the order they first appear and
+// without duplicates, so that an import can report every dangling reference before applying anything.
+func ValidateReferences(ids []string, exists func(id string) bool) (missing []string) {
+	missing = []string{}
+	checked := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if checked[id] {
+			continue
+		}
+		checked[id] = true
+
+		if !exists(id) {
+			missing = append(missing, id)
+		}
+	}
+
+	return missing
+}
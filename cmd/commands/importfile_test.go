@@ -0,0 +1,152 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateImportFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "import-file")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	writeFile := func(name, contents string) string {
+		path := filepath.Join(dir, name)
+		require.Nil(t, ioutil.WriteFile(path, []byte(contents), 0600))
+		return path
+	}
+
+	t.Run("valid file", func(t *testing.T) {
+		path := writeFile("valid.csv", "# users to import\nEmail,Username,User_Id\nuser1@example.com,user1,"+model.NewId()+"\nUser2@Example.com,user2,\n")
+
+		rowCount, errs := ValidateImportFile(path, []string{"email", "username"})
+		require.Equal(t, 2, rowCount)
+		require.Empty(t, errs)
+	})
+
+	t.Run("row errors", func(t *testing.T) {
+		path := writeFile("errors.csv", "email,username,user_id\n"+
+			"user1@example.com,user1,\n"+
+			"not-an-email,user2,\n"+
+			",user3,\n"+
+			"user4@example.com,user 4,\n"+
+			"user5@example.com,user5,not-an-id\n"+
+			"user6@example.com\n")
+
+		rowCount, errs := ValidateImportFile(path, []string{"email", "username"})
+		require.Equal(t, 6, rowCount)
+		require.Equal(t, []string{
+			`row 2: invalid email "not-an-email"`,
+			"row 3: missing value for column email",
+			`row 4: invalid username "user 4"`,
+			`row 5: invalid user_id "not-an-id"`,
+			"row 6: expected 3 columns but found 1",
+		}, errs)
+	})
+
+	t.Run("missing columns", func(t *testing.T) {
+		path := writeFile("columns.csv", "username\nuser1\n")
+
+		rowCount, errs := ValidateImportFile(path, []string{"email", "username", "team"})
+		require.Equal(t, 0, rowCount)
+		require.Equal(t, []string{"missing required columns: email, team"}, errs)
+	})
+
+	t.Run("empty file", func(t *testing.T) {
+		path := writeFile("empty.csv", "")
+
+		_, errs := ValidateImportFile(path, []string{"email"})
+		require.Len(t, errs, 1)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, errs := ValidateImportFile(filepath.Join(dir, "missing.csv"), []string{"email"})
+		require.Len(t, errs, 1)
+	})
+}
+
+func TestReadImportFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "import-file")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	writeFile := func(name, contents string) string {
+		path := filepath.Join(dir, name)
+		require.Nil(t, ioutil.WriteFile(path, []byte(contents), 0600))
+		return path
+	}
+
+	columns := []string{"name", "display_name", "type", "email"}
+	required := []string{"name", "display_name"}
+
+	t.Run("header", func(t *testing.T) {
+		path := writeFile("header.csv", "# teams\nDisplay_Name, Name\nSales Team,sales\n\"Two\nLines\",support\n")
+
+		records, err := readImportFile(path, columns, required)
+		require.Nil(t, err)
+		require.Equal(t, []importRecord{
+			{Line: 3, Values: map[string]string{"name": "sales", "display_name": "Sales Team"}},
+			{Line: 4, Values: map[string]string{"name": "support", "display_name": "Two\nLines"}},
+		}, records)
+	})
+
+	t.Run("no header", func(t *testing.T) {
+		path := writeFile("headerless.csv", "sales,Sales Team,invite,sales@example.com\nsupport,Support\n")
+
+		records, err := readImportFile(path, columns, required)
+		require.Nil(t, err)
+		require.Equal(t, []importRecord{
+			{Line: 1, Values: map[string]string{"name": "sales", "display_name": "Sales Team", "type": "invite", "email": "sales@example.com"}},
+			{Line: 2, Values: map[string]string{"name": "support", "display_name": "Support", "type": "", "email": ""}},
+		}, records)
+	})
+
+	t.Run("row errors", func(t *testing.T) {
+		path := writeFile("errors.csv", "sales,Sales Team,open,not-an-email\nsupport\nhr,HR,open,,extra\n")
+
+		_, err := readImportFile(path, columns, required)
+		require.EqualError(t, err, "Invalid file "+path+":\n"+
+			`row 1: invalid email "not-an-email"`+"\n"+
+			"row 2: missing value for column display_name\n"+
+			"row 3: expected 4 columns but found 5")
+	})
+
+	t.Run("missing columns", func(t *testing.T) {
+		path := writeFile("columns.csv", "name,type\nsales,open\n")
+
+		_, err := readImportFile(path, columns, required)
+		require.EqualError(t, err, "Invalid file "+path+":\nmissing required columns: display_name")
+	})
+
+	t.Run("empty file", func(t *testing.T) {
+		_, err := readImportFile(writeFile("empty.csv", "# nothing yet\n"), columns, required)
+		require.NotNil(t, err)
+	})
+}
+
+func TestValidateReferences(t *testing.T) {
+	team1, team2 := model.NewId(), model.NewId()
+	existing := map[string]bool{team1: true, team2: true}
+
+	calls := 0
+	exists := func(id string) bool {
+		calls++
+		return existing[id]
+	}
+
+	missing1, missing2 := model.NewId(), model.NewId()
+	missing := ValidateReferences([]string{team1, missing1, team2, missing2, missing1, team1}, exists)
+	require.Equal(t, []string{missing1, missing2}, missing)
+	require.Equal(t, 4, calls, "each id should only be looked up once")
+
+	require.Equal(t, []string{}, ValidateReferences([]string{team1, team2}, exists))
+	require.Equal(t, []string{}, ValidateReferences(nil, exists))
+}
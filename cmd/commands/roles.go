@@ -49,7 +49,10 @@ func makeSystemAdminCmdF(command *cobra.Command, args []string) error {
 		return errors.New("Enter at least one user.")
 	}
 
-	users := getUsersFromUserArgs(a, args)
+	users, appErr := getUsersFromUserArgs(a, args)
+	if appErr != nil {
+		return errors.New("Unable to look up the users. Error: " + appErr.Error())
+	}
 	for i, user := range users {
 		if user == nil {
 			return errors.New("Unable to find user '" + args[i] + "'")
@@ -73,7 +76,10 @@ func makeMemberCmdF(command *cobra.Command, args []string) error {
 		return errors.New("Enter at least one user.")
 	}
 
-	users := getUsersFromUserArgs(a, args)
+	users, appErr := getUsersFromUserArgs(a, args)
+	if appErr != nil {
+		return errors.New("Unable to look up the users. Error: " + appErr.Error())
+	}
 	for i, user := range users {
 		if user == nil {
 			return errors.New("Unable to find user '" + args[i] + "'")
@@ -4,6 +4,8 @@
 package commands
 
 import (
+	"encoding/json"
+	"errors"
 	"net"
 	"os"
 	"os/signal"
@@ -36,7 +38,18 @@ var serverCmd = &cobra.Command{
 	SilenceUsage: true,
 }
 
+var ServerIpsCmd = &cobra.Command{
+	Use:     "ips",
+	Short:   "Print the server's IP addresses",
+	Long:    "Print the IPv4 and IPv6 addresses of the server's network interfaces, skipping loopback and link-local addresses.",
+	Example: "  server ips --json",
+	RunE:    serverIpsCmdF,
+}
+
 func init() {
+	ServerIpsCmd.Flags().Bool("json", false, "Print the addresses as JSON.")
+
+	serverCmd.AddCommand(ServerIpsCmd)
 	cmd.RootCmd.AddCommand(serverCmd)
 	cmd.RootCmd.RunE = serverCmdF
 }
@@ -53,6 +66,30 @@ func serverCmdF(command *cobra.Command, args []string) error {
 	return runServer(config, disableConfigWatch, interruptChan)
 }
 
+func serverIpsCmdF(command *cobra.Command, args []string) error {
+	addresses := model.GetServerInterfaceAddresses()
+
+	jsonFlag, _ := command.Flags().GetBool("json")
+	if jsonFlag {
+		b, err := json.Marshal(addresses)
+		if err != nil {
+			return err
+		}
+		cmd.CommandPrintln(string(b))
+		return nil
+	}
+
+	if len(addresses) == 0 {
+		return errors.New("No addresses found.")
+	}
+
+	for _, address := range addresses {
+		cmd.CommandPrintln(address.Interface + ": " + address.Address)
+	}
+
+	return nil
+}
+
 func runServer(configFileLocation string, disableConfigWatch bool, interruptChan chan os.Signal) error {
 	options := []app.Option{app.ConfigFile(configFileLocation)}
 	if disableConfigWatch {
@@ -10,6 +10,7 @@ import (
 	"syscall"
 	"testing"
 
+	"github.com/mattermost/mattermost-server/cmd"
 	"github.com/mattermost/mattermost-server/jobs"
 	"github.com/mattermost/mattermost-server/utils"
 	"github.com/stretchr/testify/require"
@@ -134,3 +135,11 @@ func TestRunServerNoSystemd(t *testing.T) {
 	err := runServer(th.configPath, th.disableConfigWatch, th.interruptChan)
 	require.NoError(t, err)
 }
+
+func TestServerIps(t *testing.T) {
+	output := cmd.CheckCommand(t, "server", "ips")
+	require.Regexp(t, `(?m)^\S+: \S+$`, output)
+
+	output = cmd.CheckCommand(t, "server", "ips", "--json")
+	require.Contains(t, output, `[{"interface":"`)
+}
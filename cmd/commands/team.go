@@ -4,12 +4,26 @@
 package commands
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
 
 	"github.com/mattermost/mattermost-server/app"
 	"github.com/mattermost/mattermost-server/cmd"
 	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/utils"
+	"github.com/mattermost/rsc/qr"
 	"github.com/spf13/cobra"
 )
 
@@ -21,12 +35,35 @@ var TeamCmd = &cobra.Command{
 var TeamCreateCmd = &cobra.Command{
 	Use:   "create",
 	Short: "Create a team",
-	Long:  `Create a team.`,
+	Long: `Create a team.
+With --bulk-file, create every team listed in a CSV file with the columns name,display_name,type,email instead. The type is open or invite and defaults to open, and the email is optional. A header row naming the columns is optional, and lets them come in any order. Blank lines and lines starting with # are skipped.
+The whole file is checked before any team is created, and the command fails if any team couldn't be created.`,
 	Example: `  team create --name mynewteam --display_name "My New Team"
-  team create --name private --display_name "My New Private Team" --private`,
+  team create --name private --display_name "My New Private Team" --private
+  team create --bulk-file teams.csv`,
 	RunE: createTeamCmdF,
 }
 
+var RenameTeamCmd = &cobra.Command{
+	Use:   "rename [team]",
+	Short: "Rename a team",
+	Long: `Change the name used in the URLs of a team, and optionally its display name.
+Existing links to the team stop working once it's renamed.`,
+	Example: `  team rename myteam --new_name mynewteam
+  team rename myteam --new_name mynewteam --display_name "My New Team"`,
+	RunE: renameTeamCmdF,
+}
+
+var ModifyTeamCmd = &cobra.Command{
+	Use:   "modify [team]",
+	Short: "Make a team private or public",
+	Long: `Change a team to invite only with --private, or open with --public.
+Making a team private also turns off open invites for it.`,
+	Example: `  team modify myteam --private
+  team modify myteam --public`,
+	RunE: modifyTeamCmdF,
+}
+
 var RemoveUsersCmd = &cobra.Command{
 	Use:     "remove [team] [users]",
 	Short:   "Remove users from team",
@@ -36,28 +73,311 @@ var RemoveUsersCmd = &cobra.Command{
 }
 
 var AddUsersCmd = &cobra.Command{
-	Use:     "add [team] [users]",
-	Short:   "Add users to team",
-	Long:    "Add some users to team",
-	Example: "  team add myteam user@example.com username",
-	RunE:    addUsersCmdF,
+	Use:   "add [team] [users]",
+	Short: "Add users to team",
+	Long: `Add some users to team.
+With --users-file, the users are also read from a file listing one email or username per line. Blank lines and lines starting with # are skipped.
+Users who are already members of the team are skipped, unless --fail-on-existing is given.`,
+	Example: `  team add myteam user@example.com username
+  team add myteam --users-file new-hires.txt`,
+	RunE: addUsersCmdF,
 }
 
 var DeleteTeamsCmd = &cobra.Command{
 	Use:   "delete [teams]",
 	Short: "Delete teams",
 	Long: `Permanently delete some teams.
-Permanently deletes a team along with all related information including posts from the database.`,
-	Example: "  team delete myteam",
-	RunE:    deleteTeamsCmdF,
+Permanently deletes a team along with all related information including posts from the database.
+Use team archive instead to hide a team while keeping its data.
+With --dry-run, the teams are listed with their member and post counts and nothing is deleted.`,
+	Example: `  team delete myteam
+  team delete myteam otherteam --dry-run`,
+	RunE: deleteTeamsCmdF,
+}
+
+var ArchiveTeamCmd = &cobra.Command{
+	Use:   "archive [team]",
+	Short: "Archive a team",
+	Long: `Archive a team, hiding it from its members and from active team lists while keeping all of its data.
+Archived teams can be brought back with team restore.`,
+	Example: "  team archive myteam",
+	RunE:    archiveTeamCmdF,
+}
+
+var RestoreTeamCmd = &cobra.Command{
+	Use:   "restore [team]",
+	Short: "Restore an archived team",
+	Long: `Restore a team that was archived, making it available to its members again.
+Teams removed with team delete are permanently deleted and can't be restored.`,
+	Example: "  team restore myteam",
+	RunE:    restoreTeamCmdF,
 }
 
 var ListTeamsCmd = &cobra.Command{
-	Use:     "list",
-	Short:   "List all teams.",
-	Long:    `List all teams on the server.`,
-	Example: "  team list",
-	RunE:    listTeamsCmdF,
+	Use:   "list",
+	Short: "List all teams.",
+	Long: `List all teams on the server, one per line.
+Archived teams are only listed with --include-deleted. With --per_page, only the given page of the matching teams is listed.
+With --show-source, each name is followed by how the team was created: cli, api, import, ui, or unknown for teams created before this was recorded.
+With --with-members, each name is followed by the number of members that haven't left the team or been deactivated, such as (0 members).
+With --format, each team is printed with a Go template instead, such as {{.Name}}\t{{.DisplayName}}. \t and \n in the template are replaced with a tab and a newline.`,
+	Example: `  team list
+  team list --type invite --page 2 --per_page 100
+  team list --include-deleted
+  team list --show-source
+  team list --with-members
+  team list --format '{{.Name}}\t{{.Type}}\t{{.GetCreationSource}}'`,
+	RunE: listTeamsCmdF,
+}
+
+var CheckWhitespaceTeamsCmd = &cobra.Command{
+	Use:   "check-whitespace",
+	Short: "Find teams with stray whitespace in their display names",
+	Long: `List teams whose display name has leading or trailing whitespace, or repeated internal whitespace.
+Use --fix to trim and collapse the whitespace in the affected display names.`,
+	Example: `  team check-whitespace
+  team check-whitespace --fix`,
+	RunE: checkWhitespaceTeamsCmdF,
+}
+
+var SetTeamPropCmd = &cobra.Command{
+	Use:   "set-prop [team] [key] [value] | [team] [key=value...]",
+	Short: "Set a team property",
+	Long: `Set a metadata property, such as a cost center or region, on a team.
+Several properties can be set at once by passing key=value pairs.
+Keys may only contain letters, numbers, hyphens and underscores. Use --delete to remove a property.`,
+	Example: `  team set-prop myteam cost_center 1234
+  team set-prop myteam cost_center=1234 region="North America"
+  team set-prop myteam cost_center --delete`,
+	RunE: setTeamPropCmdF,
+}
+
+var GetTeamPropCmd = &cobra.Command{
+	Use:     "get-prop [team] [key]",
+	Short:   "Get a team property",
+	Long:    "Print the value of a metadata property set on a team.",
+	Example: "  team get-prop myteam cost_center",
+	RunE:    getTeamPropCmdF,
+}
+
+var ListTeamTokensCmd = &cobra.Command{
+	Use:   "list-tokens",
+	Short: "List the number of active access tokens per team",
+	Long: `List how many active personal access tokens are held by the members of each team.
+Teams whose count is above --threshold are flagged.`,
+	Example: `  team list-tokens
+  team list-tokens --threshold 20 --json`,
+	RunE: listTeamTokensCmdF,
+}
+
+var SyncTeamMembersCmd = &cobra.Command{
+	Use:   "sync-members [team]",
+	Short: "Reconcile team membership against a roster file",
+	Long: `Treat a CSV roster as the source of truth for the membership of a team.
+The first column of each row holds a user's email, username or ID. Blank lines, lines starting with # and a header row are ignored.
+Users in the roster who aren't on the team are added, and members of the team who aren't in the roster are removed unless --no-remove is given.
+Removals have to be confirmed, and are skipped entirely when any row of the roster can't be resolved to a user, so that a typo can't remove a member.
+The command fails if any row couldn't be synced.`,
+	Example: `  team sync-members myteam --file roster.csv --confirm
+  team sync-members myteam --file roster.csv --no-remove --dry-run --format table`,
+	RunE: syncTeamMembersCmdF,
+}
+
+var DefaultChannelsTeamCmd = &cobra.Command{
+	Use:   "default-channels [teams]",
+	Short: "List the channels new team members join automatically",
+	Long:  "List the default channels that new members of the given teams, or of every team with --all, join automatically.",
+	Example: `  team default-channels myteam
+  team default-channels --all --format json`,
+	RunE: defaultChannelsTeamCmdF,
+}
+
+var CheckInviteFormatTeamsCmd = &cobra.Command{
+	Use:   "check-invite-format",
+	Short: "Find teams with malformed invite ids",
+	Long: `List teams whose invite id doesn't have the format of the ids generated for new teams, which may stop users from joining them.
+Use --fix to generate a new invite id for the affected teams.`,
+	Example: `  team check-invite-format
+  team check-invite-format --fix`,
+	RunE: checkInviteFormatTeamsCmdF,
+}
+
+var InviteHealthTeamsCmd = &cobra.Command{
+	Use:   "invite-health",
+	Short: "Find teams whose invite links don't work",
+	Long: `Build the invite link of every team from the configured SiteURL and list the teams whose link isn't a valid URL,
+along with the reason: an invalid SiteURL, a malformed invite id or both.`,
+	Example: `  team invite-health
+  team invite-health --json`,
+	RunE: inviteHealthTeamsCmdF,
+}
+
+var MemberHealthTeamsCmd = &cobra.Command{
+	Use:   "member-health [teams]",
+	Short: "Show the share of active members of teams",
+	Long: `Show how many members of the given teams, or of every team with --all, are active and how many are deactivated.
+With --all, teams are sorted by their share of active members, lowest first, unless --sort is given.`,
+	Example: `  team member-health myteam
+  team member-health --all --json
+  team member-health --all --sort -deactivated`,
+	RunE: memberHealthTeamsCmdF,
+}
+
+var SetQuotaTeamCmd = &cobra.Command{
+	Use:   "set-quota [team]",
+	Short: "Set the file storage quota of a team",
+	Long: `Set the most file storage, such as 500MB or 10GiB, that the members of a team may use. A quota of 0 removes the quota.
+A warning is printed if the team already uses more than the new quota.`,
+	Example: `  team set-quota myteam --max-storage 10GB
+  team set-quota myteam --max-storage 0`,
+	RunE: setQuotaTeamCmdF,
+}
+
+var ShowQuotaTeamCmd = &cobra.Command{
+	Use:     "show-quota [teams]",
+	Short:   "Show the file storage used by teams",
+	Long:    "Show the file storage used by the members of teams along with their quotas.",
+	Example: "  team show-quota myteam otherteam",
+	RunE:    showQuotaTeamCmdF,
+}
+
+var CheckPlaceholderTeamsCmd = &cobra.Command{
+	Use:   "check-placeholder",
+	Short: "Find teams that still have a placeholder display name",
+	Long: `List teams whose display name matches a placeholder pattern, such as the name given to teams created by automation.
+The pattern is a regular expression matched against the whole of each display name, ignoring case.`,
+	Example: `  team check-placeholder
+  team check-placeholder --pattern 'New Team( \d+)?' --json`,
+	RunE: checkPlaceholderTeamsCmdF,
+}
+
+var CloneTeamCmd = &cobra.Command{
+	Use:   "clone [source]",
+	Short: "Create a team from the settings of another team",
+	Long: `Create a new team with the type, allowed domains and description of an existing team along with an empty copy of each of its channels.
+Members and posts aren't copied unless --with-members is given, in which case the members of the source team are added to the new team with the same roles.`,
+	Example: `  team clone myteam --name mynewteam --display_name "My New Team"
+  team clone myteam --name mynewteam --display_name "My New Team" --with-members`,
+	RunE: cloneTeamCmdF,
+}
+
+var AdminActivityTeamsCmd = &cobra.Command{
+	Use:   "admin-activity [teams]",
+	Short: "Show when the admins of teams were last active",
+	Long: `List the admins of teams along with the time they were last active, flagging the ones that have been inactive for longer than --inactive.
+Admins without any recorded activity are always flagged.`,
+	Example: `  team admin-activity myteam
+  team admin-activity --all --inactive 90d --json`,
+	RunE: adminActivityTeamsCmdF,
+}
+
+var BulkArchiveTeamsCmd = &cobra.Command{
+	Use:   "bulk-archive",
+	Short: "Archive the teams listed in a file",
+	Long: `Archive every team listed in a file, which holds one team name or id per line. Blank lines and lines starting with # are ignored.
+Archived teams keep their channels, posts and members and can be restored.`,
+	Example: `  team bulk-archive --file teams.txt --dry-run
+  team bulk-archive --file teams.txt --confirm`,
+	RunE: bulkArchiveTeamsCmdF,
+}
+
+var CheckArchivedChannelsTeamsCmd = &cobra.Command{
+	Use:   "check-archived-channels",
+	Short: "Find teams with many archived channels",
+	Long: `List the teams with more archived channels than --max, most first.
+With --purge-older-than, the channels of those teams that were archived longer ago than the given age are permanently deleted along with their posts.`,
+	Example: `  team check-archived-channels --max 500
+  team check-archived-channels --max 500 --concurrency 4
+  team check-archived-channels --max 500 --purge-older-than 365d`,
+	RunE: checkArchivedChannelsTeamsCmdF,
+}
+
+var FixDefaultMembershipTeamCmd = &cobra.Command{
+	Use:   "fix-default-membership [team]",
+	Short: "Add team members to missing default channels",
+	Long: `Add every active member of a team to the team's default channels, such as town-square, that they aren't a member of.
+Members that left a default channel on purpose are added back too.`,
+	Example: `  team fix-default-membership myteam --dry-run
+  team fix-default-membership myteam`,
+	RunE: fixDefaultMembershipTeamCmdF,
+}
+
+var ListWebhooksTeamCmd = &cobra.Command{
+	Use:   "list-webhooks [team]",
+	Short: "List the webhooks of a team and check their URLs",
+	Long: `List the incoming and outgoing webhooks of a team along with their URLs, flagging the ones that aren't valid HTTP URLs.
+The URLs of incoming webhooks are the address integrations post to and the icon they post with. The URLs of outgoing webhooks are their callbacks.`,
+	Example: `  team list-webhooks myteam
+  team list-webhooks myteam --json`,
+	RunE: listWebhooksTeamCmdF,
+}
+
+var CheckPropCyclesTeamsCmd = &cobra.Command{
+	Use:   "check-prop-cycles",
+	Short: "Check team properties that reference other teams",
+	Long: `Check a team property whose value is the name or id of another team, such as a parent team, across every team.
+Reports teams that reference a team that doesn't exist, and chains of references that loop back on themselves, including teams that reference themselves.`,
+	Example: `  team check-prop-cycles --prop parent_team
+  team check-prop-cycles --prop parent_team --json`,
+	RunE: checkPropCyclesTeamsCmdF,
+}
+
+var InviteQRTeamCmd = &cobra.Command{
+	Use:   "invite-qr [team]",
+	Short: "Save the invite link of a team as a QR code",
+	Long: `Build the invite link of a team from the configured SiteURL and save it as a PNG image of a QR code, for example to print for an event.
+The image is at most --size pixels wide. It may be a little smaller, since each square of the code is drawn with a whole number of pixels.`,
+	Example: `  team invite-qr myteam --output invite.png
+  team invite-qr myteam --output invite.png --size 512`,
+	RunE: inviteQRTeamCmdF,
+}
+
+var CheckChannelsTeamsCmd = &cobra.Command{
+	Use:   "check-channels",
+	Short: "Find teams without any channels",
+	Long: `List active teams that have no channels at all, not even archived ones or the default channels, which leaves their members with nowhere to go.
+Use --fix to create the default channels, such as town-square, for the affected teams. Run team fix-default-membership afterwards to add the existing members to them.`,
+	Example: `  team check-channels
+  team check-channels --fix`,
+	RunE: checkChannelsTeamsCmdF,
+}
+
+var SearchTeamsCmd = &cobra.Command{
+	Use:   "search [terms]",
+	Short: "Search teams by name or display name",
+	Long: `Search for teams whose name or display name contains any of the terms, ignoring case.
+Archived teams are included and marked as archived.`,
+	Example: `  team search engineering
+  team search eng sales`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: searchTeamsCmdF,
+}
+
+var MembersTeamCmd = &cobra.Command{
+	Use:   "members [team]",
+	Short: "List the members of a team",
+	Long: `List the username, email and team roles of the members of a team, a page at a time.
+Members that left the team aren't listed. Deactivated users are marked with (deactivated).`,
+	Example: `  team members myteam
+  team members myteam --page 1 --per_page 100
+  team members myteam --json`,
+	RunE: membersTeamCmdF,
+}
+
+var EngagementTeamCmd = &cobra.Command{
+	Use:   "engagement [team]",
+	Short: "Score how engaged teams are",
+	Long: `Print the activity of a team, or with --all of every active team, along with an engagement score from 0 to 100:
+
+  score = 100 * (0.3 * active + 0.4 * posts + 0.3 * recency)
+
+where active is the share of the team members whose accounts aren't deactivated, posts is p / (p + 10) with p the number
+of posts per active member, and recency is 30 / (30 + d) with d the days since the last post, or 0 if the team has never posted.
+Posts in archived channels count. With --all the teams are sorted by score, least engaged first.`,
+	Example: `  team engagement myteam
+  team engagement --all
+  team engagement --all --json`,
+	RunE: engagementTeamCmdF,
 }
 
 func init() {
@@ -65,15 +385,141 @@ func init() {
 	TeamCreateCmd.Flags().String("display_name", "", "Team Display Name")
 	TeamCreateCmd.Flags().Bool("private", false, "Create a private team.")
 	TeamCreateCmd.Flags().String("email", "", "Administrator Email (anyone with this email is automatically a team admin)")
+	TeamCreateCmd.Flags().Bool("auto-suffix", false, "If the name is taken, add a numbered suffix such as -2 to it instead of failing.")
+	TeamCreateCmd.Flags().String("bulk-file", "", "Path to a CSV file listing the teams to create.")
+
+	RenameTeamCmd.Flags().String("new_name", "", "Required. The new name of the team.")
+	RenameTeamCmd.Flags().String("display_name", "", "The new display name of the team. Defaults to the current display name.")
+
+	ModifyTeamCmd.Flags().Bool("private", false, "Make the team invite only.")
+	ModifyTeamCmd.Flags().Bool("public", false, "Make the team open.")
+
+	AddUsersCmd.Flags().String("users-file", "", "Path to a file listing the users to add, one per line.")
+	AddUsersCmd.Flags().Bool("fail-on-existing", false, "Treat users who are already members of the team as failures instead of skipping them.")
 
 	DeleteTeamsCmd.Flags().Bool("confirm", false, "Confirm you really want to delete the team and a DB backup has been performed.")
+	DeleteTeamsCmd.Flags().Bool("dry-run", false, "Print the teams that would be deleted, with their member and post counts, without deleting them.")
+
+	ListTeamsCmd.Flags().Int("page", 0, "Page number to list, starting from 0. Only used with --per_page.")
+	ListTeamsCmd.Flags().Int("per_page", 0, "Number of teams per page, at most 10000. 0 lists every team.")
+	ListTeamsCmd.Flags().String("type", "", "Only list teams of this type, either open or invite.")
+	ListTeamsCmd.Flags().Bool("include-deleted", false, "Also list archived teams, marked with (archived).")
+	ListTeamsCmd.Flags().Bool("json", false, "Print the teams as a JSON array.")
+	ListTeamsCmd.Flags().Bool("show-source", false, "Show how each team was created, one of cli, api, import, ui or unknown.")
+	ListTeamsCmd.Flags().Bool("with-members", false, "Show the number of active members of each team.")
+	ListTeamsCmd.Flags().String("format", "", "Go template used to print each team, such as {{.Name}}\\t{{.DisplayName}}.")
+
+	CheckWhitespaceTeamsCmd.Flags().Bool("fix", false, "Trim and collapse the whitespace in the affected display names.")
+	CheckWhitespaceTeamsCmd.Flags().Bool("confirm", false, "Confirm you really want to update the display names.")
+
+	SetTeamPropCmd.Flags().Bool("delete", false, "Remove the property from the team.")
+
+	ListTeamTokensCmd.Flags().Bool("json", false, "Print the results as JSON.")
+	ListTeamTokensCmd.Flags().Int64("threshold", 0, "Flag teams with more active tokens than this. 0 disables flagging.")
+	ListTeamTokensCmd.Flags().Bool("no-headers", false, "Don't print the column headers.")
+
+	SyncTeamMembersCmd.Flags().String("file", "", "Required. Path to the CSV roster.")
+	SyncTeamMembersCmd.Flags().Bool("no-remove", false, "Only add missing members, never remove anyone from the team.")
+	SyncTeamMembersCmd.Flags().Bool("dry-run", false, "Print the changes that would be made without applying them.")
+	SyncTeamMembersCmd.Flags().String("format", "plain", "Output format, one of plain, table or json.")
+	SyncTeamMembersCmd.Flags().Bool("confirm", false, "Confirm you really want to remove the members who aren't in the roster.")
+
+	DefaultChannelsTeamCmd.Flags().Bool("all", false, "List the default channels of every team.")
+	DefaultChannelsTeamCmd.Flags().String("format", "plain", "Output format, either plain or json.")
+	DefaultChannelsTeamCmd.Flags().Bool("no-headers", false, "Don't print the column headers.")
+
+	CheckInviteFormatTeamsCmd.Flags().Bool("fix", false, "Generate a new invite id for the affected teams.")
+	CheckInviteFormatTeamsCmd.Flags().Bool("confirm", false, "Confirm you really want to replace the invite ids.")
+
+	InviteHealthTeamsCmd.Flags().Bool("json", false, "Print the results as JSON.")
+
+	MemberHealthTeamsCmd.Flags().Bool("all", false, "Show every team.")
+	MemberHealthTeamsCmd.Flags().Bool("json", false, "Print the results as JSON.")
+	MemberHealthTeamsCmd.Flags().Bool("no-headers", false, "Don't print the column headers.")
+	MemberHealthTeamsCmd.Flags().String("sort", "", "Sort by name, active, deactivated or active_percent. Prefix with - to sort in descending order.")
+
+	SetQuotaTeamCmd.Flags().String("max-storage", "", "Required. The storage quota, such as 500MB or 10GiB. 0 removes the quota.")
+
+	CheckPlaceholderTeamsCmd.Flags().String("pattern", DEFAULT_PLACEHOLDER_TEAM_PATTERN, "Regular expression matching placeholder display names.")
+	CheckPlaceholderTeamsCmd.Flags().Bool("json", false, "Print the results as JSON.")
+
+	CloneTeamCmd.Flags().String("name", "", "Required. Name of the new team.")
+	CloneTeamCmd.Flags().String("display_name", "", "Required. Display name of the new team.")
+	CloneTeamCmd.Flags().Bool("with-members", false, "Also add the members of the source team to the new team.")
+	CloneTeamCmd.Flags().Bool("auto-suffix", false, "If the name is taken, add a numbered suffix such as -2 to it instead of failing.")
+
+	AdminActivityTeamsCmd.Flags().Bool("all", false, "Show the admins of every team.")
+	AdminActivityTeamsCmd.Flags().String("inactive", "90d", "Flag admins that haven't been active for this long, such as 30d, 2w or 12h.")
+	AdminActivityTeamsCmd.Flags().Bool("json", false, "Print the results as JSON.")
+	AdminActivityTeamsCmd.Flags().Bool("no-headers", false, "Don't print the column headers.")
+
+	BulkArchiveTeamsCmd.Flags().String("file", "", "Required. Path to the file listing the teams to archive.")
+	BulkArchiveTeamsCmd.Flags().Bool("dry-run", false, "Print the teams that would be archived without archiving them.")
+	BulkArchiveTeamsCmd.Flags().Bool("confirm", false, "Confirm you really want to archive the teams.")
+
+	CheckArchivedChannelsTeamsCmd.Flags().Int64("max", 500, "Flag teams with more archived channels than this.")
+	CheckArchivedChannelsTeamsCmd.Flags().Bool("json", false, "Print the results as JSON.")
+	CheckArchivedChannelsTeamsCmd.Flags().Bool("no-headers", false, "Don't print the column headers.")
+	CheckArchivedChannelsTeamsCmd.Flags().String("purge-older-than", "", "Permanently delete the channels of the flagged teams that were archived longer ago than this, such as 365d.")
+	CheckArchivedChannelsTeamsCmd.Flags().Bool("confirm", false, "Confirm you really want to permanently delete the channels.")
+	cmd.AddConcurrencyFlag(CheckArchivedChannelsTeamsCmd, "Number of teams to count the archived channels of at once.")
+
+	FixDefaultMembershipTeamCmd.Flags().Bool("dry-run", false, "Print the memberships that would be added without adding them.")
+
+	ListWebhooksTeamCmd.Flags().Bool("json", false, "Print the results as JSON.")
+	ListWebhooksTeamCmd.Flags().Bool("no-headers", false, "Don't print the column headers.")
+
+	CheckPropCyclesTeamsCmd.Flags().String("prop", "", "Required. The property holding the name or id of another team.")
+	CheckPropCyclesTeamsCmd.Flags().Bool("json", false, "Print the results as JSON.")
+
+	InviteQRTeamCmd.Flags().String("output", "", "Required. Path of the PNG file to write.")
+	InviteQRTeamCmd.Flags().Int("size", 256, "Width and height of the image in pixels.")
+
+	CheckChannelsTeamsCmd.Flags().Bool("fix", false, "Create the default channels for the affected teams.")
+	CheckChannelsTeamsCmd.Flags().Bool("confirm", false, "Confirm you really want to create the channels.")
+
+	MembersTeamCmd.Flags().Int("page", 0, "Page number to list, starting from 0.")
+	MembersTeamCmd.Flags().Int("per_page", cmd.DEFAULT_LIST_LIMIT, "Number of members per page, at most 10000.")
+	MembersTeamCmd.Flags().Bool("json", false, "Print the members as JSON.")
+	MembersTeamCmd.Flags().Bool("no-headers", false, "Don't print the column headers.")
+	EngagementTeamCmd.Flags().Bool("all", false, "Score every active team.")
+	EngagementTeamCmd.Flags().Bool("json", false, "Print the results as JSON.")
+	EngagementTeamCmd.Flags().Bool("no-headers", false, "Don't print the column headers.")
 
 	TeamCmd.AddCommand(
 		TeamCreateCmd,
+		RenameTeamCmd,
+		ModifyTeamCmd,
 		RemoveUsersCmd,
 		AddUsersCmd,
 		DeleteTeamsCmd,
+		ArchiveTeamCmd,
+		RestoreTeamCmd,
 		ListTeamsCmd,
+		CheckWhitespaceTeamsCmd,
+		SetTeamPropCmd,
+		GetTeamPropCmd,
+		ListTeamTokensCmd,
+		SyncTeamMembersCmd,
+		DefaultChannelsTeamCmd,
+		CheckInviteFormatTeamsCmd,
+		InviteHealthTeamsCmd,
+		MemberHealthTeamsCmd,
+		SetQuotaTeamCmd,
+		ShowQuotaTeamCmd,
+		CheckPlaceholderTeamsCmd,
+		CloneTeamCmd,
+		AdminActivityTeamsCmd,
+		BulkArchiveTeamsCmd,
+		CheckArchivedChannelsTeamsCmd,
+		FixDefaultMembershipTeamCmd,
+		ListWebhooksTeamCmd,
+		CheckPropCyclesTeamsCmd,
+		InviteQRTeamCmd,
+		CheckChannelsTeamsCmd,
+		SearchTeamsCmd,
+		MembersTeamCmd,
+		EngagementTeamCmd,
 	)
 	cmd.RootCmd.AddCommand(TeamCmd)
 }
@@ -84,15 +530,37 @@ func createTeamCmdF(command *cobra.Command, args []string) error {
 		return err
 	}
 
+	if bulkFile, _ := command.Flags().GetString("bulk-file"); bulkFile != "" {
+		for _, flag := range []string{"name", "display_name", "email", "private", "auto-suffix"} {
+			if err := cmd.RequireMutuallyExclusive(command, "bulk-file", flag); err != nil {
+				return err
+			}
+		}
+		return createTeamsFromFile(a, bulkFile)
+	}
+
 	name, errn := command.Flags().GetString("name")
-	if errn != nil || name == "" {
+	if errn != nil || model.IsBlank(name) {
 		return errors.New("Name is required")
 	}
+	name = canonicalizeTeamName(name)
+	if name, err = resolveTeamNameCollision(a, command, name); err != nil {
+		return err
+	}
 	displayname, errdn := command.Flags().GetString("display_name")
-	if errdn != nil || displayname == "" {
+	if errdn != nil || model.IsBlank(displayname) {
 		return errors.New("Display Name is required")
 	}
 	email, _ := command.Flags().GetString("email")
+	email = strings.TrimSpace(email)
+	if email != "" {
+		if !model.IsValidEmail(email) {
+			return errors.New("Invalid email '" + email + "'")
+		}
+		if user, _ := a.GetUserByEmail(email); user == nil {
+			cmd.CommandPrettyPrintln("Warning: no user has the email '" + email + "', so it won't give anyone admin rights until one does.")
+		}
+	}
 	useprivate, _ := command.Flags().GetBool("private")
 
 	teamType := model.TEAM_OPEN
@@ -106,6 +574,11 @@ func createTeamCmdF(command *cobra.Command, args []string) error {
 		Email:       email,
 		Type:        teamType,
 	}
+	team.SetProp(model.TEAM_PROP_CREATION_SOURCE, model.TEAM_CREATION_SOURCE_CLI)
+
+	if err := model.ValidateTeamInviteConsistency(team); err != nil {
+		return errors.New("Team creation failed: " + err.Error())
+	}
 
 	if _, err := a.CreateTeam(team); err != nil {
 		return errors.New("Team creation failed: " + err.Error())
@@ -114,6 +587,237 @@ func createTeamCmdF(command *cobra.Command, args []string) error {
 	return nil
 }
 
+const teamCreated = "created"
+
+type teamRow struct {
+	Line        int
+	Name        string
+	DisplayName string
+	Type        string
+	Email       string
+}
+
+// readTeamsFile returns the rows of a CSV file listing teams as name,display_name,type,email along with the line
+// each starts on. A header row naming the columns is optional. The whole file is checked first, so a malformed
+// file is rejected as a whole.
+func readTeamsFile(path string) ([]teamRow, error) {
+	records, err := readImportFile(path, []string{"name", "display_name", "type", "email"}, []string{"name", "display_name"})
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]teamRow, len(records))
+	for i, record := range records {
+		rows[i] = teamRow{
+			Line:        record.Line,
+			Name:        record.Values["name"],
+			DisplayName: record.Values["display_name"],
+			Type:        record.Values["type"],
+			Email:       record.Values["email"],
+		}
+	}
+
+	return rows, nil
+}
+
+func createTeamsFromFile(a *app.App, path string) error {
+	rows, err := readTeamsFile(path)
+	if err != nil {
+		return err
+	}
+
+	// Names are compared and saved in canonical form, like the name given to a single team create
+	names := make([]string, len(rows))
+	for i := range rows {
+		rows[i].Name = model.CanonicalizeSlug(rows[i].Name)
+		names[i] = rows[i].Name
+	}
+	duplicateOf := map[int]int{}
+	for _, indexes := range model.FindSlugConflicts(names) {
+		for _, i := range indexes[1:] {
+			duplicateOf[i] = indexes[0]
+		}
+	}
+
+	results := cmd.RowResults{}
+	for i, row := range rows {
+		var err error
+		if first, ok := duplicateOf[i]; ok {
+			err = fmt.Errorf("Team '%v' is already listed on line %v", row.Name, rows[first].Line)
+		} else {
+			err = createTeamFromRow(a, row)
+		}
+		results.Add(row.Line, row.Name, teamCreated, err)
+		if err != nil {
+			cmd.CommandPrintErrorln(fmt.Sprintf("Line %v: %v", row.Line, err.Error()))
+		} else {
+			cmd.CommandPrettyPrintln("Created team '" + row.Name + "'")
+		}
+	}
+
+	cmd.CommandPrintln(fmt.Sprintf("Created %v teams from %v, %v failed.", results.Count(teamCreated), path, results.Count(cmd.ROW_STATUS_ERROR)))
+
+	if failed := results.Count(cmd.ROW_STATUS_ERROR); failed > 0 {
+		return fmt.Errorf("Unable to create %v teams from %v.", failed, path)
+	}
+
+	return nil
+}
+
+func createTeamFromRow(a *app.App, row teamRow) error {
+	if !model.IsValidAlphaNumHyphenUnderscore(row.Name, true) {
+		return errors.New("Invalid team name '" + row.Name + "'")
+	}
+	if row.DisplayName == "" {
+		return errors.New("Display name is required for team '" + row.Name + "'")
+	}
+
+	var teamType string
+	switch strings.ToLower(row.Type) {
+	case "", "open", strings.ToLower(model.TEAM_OPEN):
+		teamType = model.TEAM_OPEN
+	case "invite", strings.ToLower(model.TEAM_INVITE):
+		teamType = model.TEAM_INVITE
+	default:
+		return errors.New("Type of team '" + row.Name + "' must be either open or invite")
+	}
+
+	if existing, _ := a.GetTeamByName(row.Name); existing != nil {
+		return errors.New("A team named '" + row.Name + "' already exists")
+	}
+
+	team := &model.Team{
+		Name:        row.Name,
+		DisplayName: row.DisplayName,
+		Email:       row.Email,
+		Type:        teamType,
+	}
+	team.SetProp(model.TEAM_PROP_CREATION_SOURCE, model.TEAM_CREATION_SOURCE_CLI)
+
+	if _, err := a.CreateTeam(team); err != nil {
+		return errors.New("Unable to create team '" + row.Name + "'. Error: " + err.Error())
+	}
+
+	return nil
+}
+
+func renameTeamCmdF(command *cobra.Command, args []string) error {
+	a, err := cmd.InitDBCommandContextCobra(command)
+	if err != nil {
+		return err
+	}
+
+	if len(args) != 1 {
+		return errors.New("Expected exactly one team.")
+	}
+
+	team := getTeamFromTeamArg(a, args[0])
+	if team == nil {
+		return errors.New("Unable to find team '" + args[0] + "'")
+	}
+
+	newName, _ := command.Flags().GetString("new_name")
+	if model.IsBlank(newName) {
+		return errors.New("New name is required")
+	}
+	newName = canonicalizeTeamName(newName)
+	if !model.IsValidAlphaNumHyphenUnderscore(newName, true) {
+		return errors.New("Invalid team name '" + newName + "'")
+	}
+
+	displayName, _ := command.Flags().GetString("display_name")
+	if model.IsBlank(displayName) {
+		displayName = team.DisplayName
+	}
+
+	if existing, _ := a.GetTeamByName(newName); existing != nil && existing.Id != team.Id {
+		return errors.New("A team named '" + newName + "' already exists")
+	}
+
+	oldName, oldDisplayName := team.Name, team.DisplayName
+	if _, err := a.RenameTeam(team, newName, displayName); err != nil {
+		return errors.New("Unable to rename team '" + oldName + "'. Error: " + err.Error())
+	}
+
+	cmd.CommandPrintln(fmt.Sprintf("Renamed team '%v' (%v) to '%v' (%v)", oldName, oldDisplayName, newName, displayName))
+
+	return nil
+}
+
+func modifyTeamCmdF(command *cobra.Command, args []string) error {
+	a, err := cmd.InitDBCommandContextCobra(command)
+	if err != nil {
+		return err
+	}
+
+	if len(args) != 1 {
+		return errors.New("Expected exactly one team.")
+	}
+
+	if err := cmd.RequireMutuallyExclusive(command, "private", "public"); err != nil {
+		return err
+	}
+
+	privateFlag, _ := command.Flags().GetBool("private")
+	publicFlag, _ := command.Flags().GetBool("public")
+	if !privateFlag && !publicFlag {
+		return errors.New("Either --private or --public is required.")
+	}
+
+	team := getTeamFromTeamArg(a, args[0])
+	if team == nil {
+		return errors.New("Unable to find team '" + args[0] + "'")
+	}
+
+	teamType, description := model.TEAM_OPEN, "public"
+	if privateFlag {
+		teamType, description = model.TEAM_INVITE, "private"
+	}
+
+	if team.Type == teamType {
+		cmd.CommandPrintln("Team '" + team.Name + "' is already " + description + ".")
+		return nil
+	}
+
+	if _, err := a.UpdateTeamType(team, teamType); err != nil {
+		return errors.New("Unable to update team '" + team.Name + "'. Error: " + err.Error())
+	}
+
+	cmd.CommandPrintln("Team '" + team.Name + "' is now " + description + ".")
+
+	return nil
+}
+
+// canonicalizeTeamName returns the canonical form of a team name given on the command line, telling the user
+// when it differs from what they typed so that they know which name the team ends up with.
+func canonicalizeTeamName(name string) string {
+	canonical := model.CanonicalizeSlug(name)
+	if canonical != name {
+		cmd.CommandPrettyPrintln("Using team name '" + canonical + "' instead of '" + name + "'.")
+	}
+	return canonical
+}
+
+// resolveTeamNameCollision returns name if no team has it yet. Otherwise it fails, or with --auto-suffix returns
+// the name with the first free numbered suffix.
+func resolveTeamNameCollision(a *app.App, command *cobra.Command, name string) (string, error) {
+	exists := func(slug string) bool {
+		team, _ := a.GetTeamByName(slug)
+		return team != nil
+	}
+	if !exists(name) {
+		return name, nil
+	}
+
+	if autoSuffix, _ := command.Flags().GetBool("auto-suffix"); !autoSuffix {
+		return "", errors.New("A team named '" + name + "' already exists")
+	}
+
+	unique := model.UniqueSlug(name, exists)
+	cmd.CommandPrettyPrintln("A team named '" + name + "' already exists, using '" + unique + "' instead.")
+	return unique, nil
+}
+
 func removeUsersCmdF(command *cobra.Command, args []string) error {
 	a, err := cmd.InitDBCommandContextCobra(command)
 	if err != nil {
@@ -129,7 +833,10 @@ func removeUsersCmdF(command *cobra.Command, args []string) error {
 		return errors.New("Unable to find team '" + args[0] + "'")
 	}
 
-	users := getUsersFromUserArgs(a, args[1:])
+	users, appErr := getUsersFromUserArgs(a, args[1:])
+	if appErr != nil {
+		return errors.New("Unable to look up the users. Error: " + appErr.Error())
+	}
 	for i, user := range users {
 		removeUserFromTeam(a, team, user, args[i+1])
 	}
@@ -153,7 +860,8 @@ func addUsersCmdF(command *cobra.Command, args []string) error {
 		return err
 	}
 
-	if len(args) < 2 {
+	usersFile, _ := command.Flags().GetString("users-file")
+	if len(args) < 1 || (len(args) < 2 && usersFile == "") {
 		return errors.New("Not enough arguments.")
 	}
 
@@ -162,22 +870,90 @@ func addUsersCmdF(command *cobra.Command, args []string) error {
 		return errors.New("Unable to find team '" + args[0] + "'")
 	}
 
-	users := getUsersFromUserArgs(a, args[1:])
+	var entries []listEntry
+	if usersFile != "" {
+		if entries, err = readListFile(usersFile); err != nil {
+			return err
+		}
+	}
+
+	failOnExisting, _ := command.Flags().GetBool("fail-on-existing")
+
+	users, appErr := getUsersFromUserArgs(a, args[1:])
+	if appErr != nil {
+		return errors.New("Unable to look up the users. Error: " + appErr.Error())
+	}
+	failed := 0
 	for i, user := range users {
-		addUserToTeam(a, team, user, args[i+1])
+		if status, err := addUserToTeam(a, team, user, args[i+1], failOnExisting); err != nil {
+			cmd.CommandPrintErrorln(err.Error())
+			failed++
+		} else if status == cmd.ROW_STATUS_SKIPPED {
+			cmd.CommandPrettyPrintln("'" + args[i+1] + "' is already a member of " + team.Name + ", skipping")
+		}
+	}
+
+	if usersFile != "" {
+		fileFailed, err := addUsersFromFile(a, team, usersFile, entries, failOnExisting)
+		if err != nil {
+			return err
+		}
+		failed += fileFailed
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("Unable to add %v users to team '%v'.", failed, team.Name)
 	}
 
 	return nil
 }
 
-func addUserToTeam(a *app.App, team *model.Team, user *model.User, userArg string) {
+// addUsersFromFile adds the users listed in a --users-file and prints a summary, returning how many rows failed.
+func addUsersFromFile(a *app.App, team *model.Team, usersFile string, entries []listEntry, failOnExisting bool) (int, error) {
+	userArgs := make([]string, len(entries))
+	for i, entry := range entries {
+		userArgs[i] = entry.Value
+	}
+	fileUsers, appErr := getUsersFromUserArgs(a, userArgs)
+	if appErr != nil {
+		return 0, errors.New("Unable to look up the users of " + usersFile + ". Error: " + appErr.Error())
+	}
+
+	results := cmd.RowResults{}
+	for i, entry := range entries {
+		status, err := addUserToTeam(a, team, fileUsers[i], entry.Value, failOnExisting)
+		results.Add(entry.Line, entry.Value, status, err)
+	}
+
+	cmd.CommandPrintln(fmt.Sprintf("Added %v users from %v to team '%v', %v skipped, %v failed.", results.Count(TEAM_MEMBER_ADDED), usersFile, team.Name, results.Count(cmd.ROW_STATUS_SKIPPED), results.Count(cmd.ROW_STATUS_ERROR)))
+	for _, result := range results {
+		if result.Status == cmd.ROW_STATUS_ERROR {
+			cmd.CommandPrintErrorln(fmt.Sprintf("Line %v: %v", result.Line, result.Error.Error()))
+		}
+	}
+
+	return results.Count(cmd.ROW_STATUS_ERROR), nil
+}
+
+const TEAM_MEMBER_ADDED = "added"
+
+// addUserToTeam adds the user to the team and returns TEAM_MEMBER_ADDED, or cmd.ROW_STATUS_SKIPPED if the user
+// is already an active member, so that provisioning scripts can be re-run safely. With failOnExisting, an
+// existing membership is an error instead. It doesn't print anything, so callers decide how to report the result.
+func addUserToTeam(a *app.App, team *model.Team, user *model.User, userArg string, failOnExisting bool) (string, error) {
 	if user == nil {
-		cmd.CommandPrintErrorln("Can't find user '" + userArg + "'")
-		return
+		return "", errors.New("Can't find user '" + userArg + "'")
+	}
+	if member, err := a.GetTeamMember(team.Id, user.Id); err == nil && member.DeleteAt == 0 {
+		if failOnExisting {
+			return "", errors.New("'" + userArg + "' is already a member of " + team.Name)
+		}
+		return cmd.ROW_STATUS_SKIPPED, nil
 	}
 	if err := a.JoinUserToTeam(team, user, ""); err != nil {
-		cmd.CommandPrintErrorln("Unable to add '" + userArg + "' to " + team.Name)
+		return "", errors.New("Unable to add '" + userArg + "' to " + team.Name + ". Error: " + err.Error())
 	}
+	return TEAM_MEMBER_ADDED, nil
 }
 
 func deleteTeamsCmdF(command *cobra.Command, args []string) error {
@@ -190,6 +966,10 @@ func deleteTeamsCmdF(command *cobra.Command, args []string) error {
 		return errors.New("Not enough arguments.")
 	}
 
+	if dryRun, _ := command.Flags().GetBool("dry-run"); dryRun {
+		return printTeamsToDelete(a, args)
+	}
+
 	confirmFlag, _ := command.Flags().GetBool("confirm")
 	if !confirmFlag {
 		var confirm string
@@ -222,24 +1002,2226 @@ func deleteTeamsCmdF(command *cobra.Command, args []string) error {
 	return nil
 }
 
+// printTeamsToDelete lists the teams that team delete would remove, with their member and post counts, without
+// deleting anything.
+func printTeamsToDelete(a *app.App, args []string) error {
+	table := cmd.NewTablePrinter("TEAM", "ID", "MEMBERS", "POSTS")
+	found := 0
+	teams := getTeamsFromTeamArgs(a, args)
+	for i, team := range teams {
+		if team == nil {
+			cmd.CommandPrintErrorln("Unable to find team '" + args[i] + "'")
+			continue
+		}
+
+		stats, appErr := a.GetTeamStats(team.Id)
+		if appErr != nil {
+			return errors.New("Unable to count the members of team '" + team.Name + "'. Error: " + appErr.Error())
+		}
+		// Every post is deleted with the team, so system messages are counted here too
+		result := <-a.Srv.Store.Post().AnalyticsPostCount(team.Id, false, false)
+		if result.Err != nil {
+			return errors.New("Unable to count the posts of team '" + team.Name + "'. Error: " + result.Err.Error())
+		}
+		table.AddRow(team.Name, team.Id, stats.TotalMemberCount, result.Data.(int64))
+		found++
+	}
+
+	if found > 0 {
+		cmd.CommandPrint(table.String())
+	}
+	cmd.CommandPrintln(fmt.Sprintf("Would delete %v teams, %v not found.", found, len(teams)-found))
+	return nil
+}
+
 func deleteTeam(a *app.App, team *model.Team) *model.AppError {
 	return a.PermanentDeleteTeam(team)
 }
 
-func listTeamsCmdF(command *cobra.Command, args []string) error {
+func archiveTeam(a *app.App, team *model.Team) *model.AppError {
+	return a.SoftDeleteTeam(team.Id)
+}
+
+func archiveTeamCmdF(command *cobra.Command, args []string) error {
 	a, err := cmd.InitDBCommandContextCobra(command)
 	if err != nil {
 		return err
 	}
 
-	teams, err2 := a.GetAllTeams()
-	if err2 != nil {
-		return err2
+	if len(args) != 1 {
+		return errors.New("Expected exactly one team.")
 	}
 
-	for _, team := range teams {
-		cmd.CommandPrettyPrintln(team.Name)
+	team := getTeamFromTeamArg(a, args[0])
+	if team == nil {
+		return errors.New("Unable to find team '" + args[0] + "'")
+	}
+
+	if team.DeleteAt > 0 {
+		cmd.CommandPrintln("Team '" + team.Name + "' is already archived.")
+		return nil
+	}
+
+	if err := archiveTeam(a, team); err != nil {
+		return errors.New("Unable to archive team '" + team.Name + "'. Error: " + err.Error())
+	}
+
+	cmd.CommandPrintln("Archived team '" + team.Name + "'. Use team restore to bring it back.")
+
+	return nil
+}
+
+func restoreTeamCmdF(command *cobra.Command, args []string) error {
+	a, err := cmd.InitDBCommandContextCobra(command)
+	if err != nil {
+		return err
+	}
+
+	if len(args) != 1 {
+		return errors.New("Expected exactly one team.")
+	}
+
+	team := getTeamFromTeamArg(a, args[0])
+	if team == nil {
+		return errors.New("Unable to find team '" + args[0] + "'. Permanently deleted teams can't be restored.")
+	}
+
+	if team.DeleteAt == 0 {
+		cmd.CommandPrintln("Team '" + team.Name + "' is already active.")
+		return nil
+	}
+
+	if err := a.RestoreTeam(team.Id); err != nil {
+		return errors.New("Unable to restore team '" + team.Name + "'. Error: " + err.Error())
+	}
+
+	cmd.CommandPrintln("Restored team '" + team.Name + "'.")
+
+	return nil
+}
+
+func listTeamsCmdF(command *cobra.Command, args []string) error {
+	a, err := cmd.InitDBCommandContextCobra(command)
+	if err != nil {
+		return err
+	}
+
+	page, _ := command.Flags().GetInt("page")
+	perPage, _ := command.Flags().GetInt("per_page")
+	if page < 0 || perPage < 0 {
+		return errors.New("Page and per page can't be negative.")
+	}
+	if perPage > 0 {
+		perPage = cmd.ResolveLimit(perPage, cmd.DEFAULT_LIST_LIMIT, cmd.MAX_LIST_LIMIT)
+	}
+	includeDeleted, _ := command.Flags().GetBool("include-deleted")
+	jsonFlag, _ := command.Flags().GetBool("json")
+	showSource, _ := command.Flags().GetBool("show-source")
+	withMembers, _ := command.Flags().GetBool("with-members")
+	if err := cmd.RequireMutuallyExclusive(command, "json", "with-members"); err != nil {
+		return err
+	}
+	for _, flag := range []string{"json", "show-source", "with-members"} {
+		if err := cmd.RequireMutuallyExclusive(command, "format", flag); err != nil {
+			return err
+		}
+	}
+
+	var tmpl *template.Template
+	if format, _ := command.Flags().GetString("format"); format != "" {
+		if tmpl, err = parseTeamTemplate(format); err != nil {
+			return err
+		}
+	}
+
+	var memberCounts map[string]int64
+	if withMembers {
+		counts, appErr := a.GetTeamMemberCounts()
+		if appErr != nil {
+			return errors.New("Unable to count the team members. Error: " + appErr.Error())
+		}
+		memberCounts = counts
+	}
+
+	teamType, _ := command.Flags().GetString("type")
+	switch strings.ToLower(teamType) {
+	case "":
+	case "open":
+		teamType = model.TEAM_OPEN
+	case "invite":
+		teamType = model.TEAM_INVITE
+	default:
+		return errors.New("Type must be either open or invite.")
+	}
+
+	teams := []*model.Team{}
+	printTeam := func(team *model.Team) error {
+		if jsonFlag {
+			teams = append(teams, team)
+		} else if tmpl != nil {
+			var buf bytes.Buffer
+			if err := tmpl.Execute(&buf, team); err != nil {
+				return errors.New("Unable to format team '" + team.Name + "'. Error: " + err.Error())
+			}
+			cmd.CommandPrintln(buf.String())
+		} else {
+			line := team.Name
+			if showSource {
+				line += " " + team.GetCreationSource()
+			}
+			if withMembers {
+				line += fmt.Sprintf(" (%v members)", memberCounts[team.Id])
+			}
+			if team.DeleteAt > 0 {
+				line += " (archived)"
+			}
+			cmd.CommandPrettyPrintln(line)
+		}
+		return nil
+	}
+
+	if perPage > 0 {
+		pageTeams, appErr := a.GetAllTeamsPageFiltered(teamType, includeDeleted, page*perPage, perPage)
+		if appErr != nil {
+			return errors.New("Unable to list the teams. Error: " + appErr.Error())
+		}
+		for _, team := range pageTeams {
+			if err := printTeam(team); err != nil {
+				return err
+			}
+		}
+	} else {
+		ctx, cancel := cmd.InterruptContext()
+		defer cancel()
+
+		fetch := func(offset, limit int) ([]interface{}, error) {
+			pageTeams, appErr := a.GetAllTeamsPageFiltered(teamType, includeDeleted, offset, limit)
+			if appErr != nil {
+				return nil, appErr
+			}
+
+			items := make([]interface{}, len(pageTeams))
+			for i, team := range pageTeams {
+				items[i] = team
+			}
+			return items, nil
+		}
+
+		if _, err := cmd.ForEachPage(ctx, cmd.DEFAULT_SCAN_PAGE_SIZE, fetch, func(item interface{}) error {
+			return printTeam(item.(*model.Team))
+		}); err != nil {
+			return err
+		}
+	}
+
+	if jsonFlag {
+		cmd.CommandPrintln(model.TeamListToJson(teams))
+	}
+
+	return nil
+}
+
+// parseTeamTemplate parses a --format template for printing teams, replacing the \t and \n escapes that shells
+// pass through literally. The template is tried on an empty team so that unknown fields are reported before
+// any team is printed.
+func parseTeamTemplate(format string) (*template.Template, error) {
+	format = strings.NewReplacer(`\t`, "\t", `\n`, "\n").Replace(format)
+
+	tmpl, err := template.New("team").Parse(format)
+	if err != nil {
+		return nil, errors.New("Invalid --format template: " + err.Error())
+	}
+
+	if err := tmpl.Execute(ioutil.Discard, &model.Team{}); err != nil {
+		return nil, errors.New("Invalid --format template: " + err.Error())
+	}
+
+	return tmpl, nil
+}
+
+func checkWhitespaceTeamsCmdF(command *cobra.Command, args []string) error {
+	a, err := cmd.InitDBCommandContextCobra(command)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := cmd.InterruptContext()
+	defer cancel()
+
+	affected := []*model.Team{}
+	scanned, err := cmd.ForEachTeam(ctx, a, cmd.DEFAULT_SCAN_PAGE_SIZE, func(team *model.Team) error {
+		if model.CollapseWhitespace(team.DisplayName) != team.DisplayName {
+			affected = append(affected, team)
+			cmd.CommandPrintln(fmt.Sprintf("%v: %q", team.Name, team.DisplayName))
+		}
+		return nil
+	})
+	if err := cmd.ScanError(err, "Scan interrupted after %v teams, %v with stray whitespace found so far.", scanned, len(affected)); err != nil {
+		return err
+	}
+
+	if len(affected) == 0 {
+		cmd.CommandPrettyPrintln("No teams with stray whitespace found.")
+		return nil
+	}
+
+	fixFlag, _ := command.Flags().GetBool("fix")
+	if !fixFlag {
+		return nil
+	}
+
+	confirmFlag, _ := command.Flags().GetBool("confirm")
+	if !confirmFlag {
+		var confirm string
+		cmd.CommandPrettyPrintln("Are you sure you want to update the display names of the teams listed above? (YES/NO): ")
+		fmt.Scanln(&confirm)
+		if confirm != "YES" {
+			return errors.New("ABORTED: You did not answer YES exactly, in all capitals.")
+		}
+	}
+
+	errorLog := cmd.NewDefaultThrottledLogger(cmd.CommandPrintErrorln)
+	progressLog := cmd.NewDefaultThrottledLogger(cmd.CommandPrettyPrintln)
+	for _, team := range affected {
+		team.DisplayName = model.CollapseWhitespace(team.DisplayName)
+		if _, err := a.UpdateTeam(team); err != nil {
+			errorLog.Println("Unable to update team '" + team.Name + "' error: " + err.Error())
+		} else {
+			progressLog.Println("Updated team '" + team.Name + "'")
+		}
+	}
+	errorLog.Flush()
+	progressLog.Flush()
+
+	return nil
+}
+
+func setTeamPropCmdF(command *cobra.Command, args []string) error {
+	a, err := cmd.InitDBCommandContextCobra(command)
+	if err != nil {
+		return err
+	}
+
+	deleteFlag, _ := command.Flags().GetBool("delete")
+	if deleteFlag && len(args) != 2 {
+		return errors.New("Expected the team and the key of the property to delete.")
+	} else if len(args) < 2 {
+		return errors.New("Expected the team and the properties to set.")
+	}
+
+	team := getTeamFromTeamArg(a, args[0])
+	if team == nil {
+		return errors.New("Unable to find team '" + args[0] + "'")
+	}
+
+	if deleteFlag {
+		key := args[1]
+		if _, err := a.DeleteTeamProp(team.Id, key); err != nil {
+			return errors.New("Unable to delete property '" + key + "' from team '" + team.Name + "'. Error: " + err.Error())
+		}
+		cmd.CommandPrettyPrintln("Deleted property '" + key + "' from team '" + team.Name + "'")
+		return nil
+	}
+
+	var props map[string]string
+	if len(args) == 3 && !strings.Contains(args[1], "=") {
+		props = map[string]string{args[1]: args[2]}
+	} else {
+		var err error
+		if props, err = model.ParseKeyValuePairs(args[1:]); err != nil {
+			return err
+		}
+	}
+
+	keys := make([]string, 0, len(props))
+	for key := range props {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	if _, err := a.SetTeamProps(team.Id, props); err != nil {
+		return errors.New("Unable to set the properties " + strings.Join(keys, ", ") + " on team '" + team.Name + "'. Error: " + err.Error())
+	}
+	for _, key := range keys {
+		cmd.CommandPrettyPrintln("Set property '" + key + "' on team '" + team.Name + "'")
+	}
+
+	return nil
+}
+
+func getTeamPropCmdF(command *cobra.Command, args []string) error {
+	a, err := cmd.InitDBCommandContextCobra(command)
+	if err != nil {
+		return err
+	}
+
+	if len(args) != 2 {
+		return errors.New("Expected the team and the key of the property.")
+	}
+
+	team := getTeamFromTeamArg(a, args[0])
+	if team == nil {
+		return errors.New("Unable to find team '" + args[0] + "'")
+	}
+
+	value, ok := team.Props[args[1]]
+	if !ok {
+		return errors.New("Property '" + args[1] + "' is not set on team '" + team.Name + "'")
+	}
+
+	cmd.CommandPrintln(value)
+
+	return nil
+}
+
+type teamTokenCount struct {
+	TeamId         string `json:"team_id"`
+	TeamName       string `json:"team_name"`
+	ActiveTokens   int64  `json:"active_tokens"`
+	AboveThreshold bool   `json:"above_threshold"`
+}
+
+func listTeamTokensCmdF(command *cobra.Command, args []string) error {
+	a, err := cmd.InitDBCommandContextCobra(command)
+	if err != nil {
+		return err
+	}
+
+	jsonFlag, _ := command.Flags().GetBool("json")
+	threshold, _ := command.Flags().GetInt64("threshold")
+	if threshold < 0 {
+		return errors.New("Threshold must not be negative.")
+	}
+
+	teams, err2 := a.GetAllTeams()
+	if err2 != nil {
+		return err2
+	}
+
+	counts, err2 := a.GetActiveUserAccessTokenCountsByTeam()
+	if err2 != nil {
+		return err2
+	}
+
+	results := []*teamTokenCount{}
+	for _, team := range teams {
+		if team.DeleteAt > 0 {
+			continue
+		}
+
+		results = append(results, &teamTokenCount{
+			TeamId:         team.Id,
+			TeamName:       team.Name,
+			ActiveTokens:   counts[team.Id],
+			AboveThreshold: threshold > 0 && counts[team.Id] > threshold,
+		})
+	}
+
+	if jsonFlag {
+		page := cmd.NewCompletePage(results, len(results))
+		cmd.CommandPrintln(page.ToJson())
+		return nil
+	}
+
+	table := cmd.NewTablePrinter("TEAM", "ACTIVE TOKENS", "ABOVE THRESHOLD")
+	table.NoHeaders, _ = command.Flags().GetBool("no-headers")
+	for _, result := range results {
+		aboveThreshold := ""
+		if result.AboveThreshold {
+			aboveThreshold = "yes"
+		}
+		table.AddRow(result.TeamName, result.ActiveTokens, aboveThreshold)
+	}
+	cmd.CommandPrint(table.String())
+
+	return nil
+}
+
+const (
+	SYNC_MEMBER_ADDED        = "added"
+	SYNC_MEMBER_REMOVED      = "removed"
+	SYNC_MEMBER_WOULD_ADD    = "would add"
+	SYNC_MEMBER_WOULD_REMOVE = "would remove"
+)
+
+func syncTeamMembersCmdF(command *cobra.Command, args []string) error {
+	a, err := cmd.InitDBCommandContextCobra(command)
+	if err != nil {
+		return err
+	}
+
+	if len(args) != 1 {
+		return errors.New("Expected exactly one team.")
+	}
+
+	path, _ := command.Flags().GetString("file")
+	if path == "" {
+		return errors.New("File is required")
+	}
+	noRemove, _ := command.Flags().GetBool("no-remove")
+	dryRun, _ := command.Flags().GetBool("dry-run")
+	format, _ := command.Flags().GetString("format")
+	if format != "plain" && format != "table" && format != "json" {
+		return errors.New("Format must be one of plain, table or json.")
+	}
+
+	team := getTeamFromTeamArg(a, args[0])
+	if team == nil {
+		return errors.New("Unable to find team '" + args[0] + "'")
+	}
+
+	entries, err := readRosterFile(path)
+	if err != nil {
+		return err
+	}
+
+	userArgs := make([]string, len(entries))
+	for i, entry := range entries {
+		userArgs[i] = entry.UserArg
+	}
+
+	results := cmd.RowResults{}
+
+	desired := map[string]*model.User{}
+	desiredIds := []string{}
+	desiredLines := map[string]int{}
+	users, appErr := getUsersFromUserArgs(a, userArgs)
+	if appErr != nil {
+		return errors.New("Unable to look up the users of " + path + ". Error: " + appErr.Error())
+	}
+	resolved := map[string]bool{}
+	for i, user := range users {
+		if user == nil {
+			results.Add(entries[i].Line, entries[i].UserArg, cmd.ROW_STATUS_ERROR, errors.New("user not found"))
+			continue
+		}
+		resolved[entries[i].UserArg] = true
+		if _, ok := desired[user.Id]; !ok {
+			desired[user.Id] = user
+			desiredLines[user.Id] = entries[i].Line
+		}
+		desiredIds = append(desiredIds, user.Id)
+	}
+
+	currentIds := []string{}
+	for offset := 0; ; offset += cmd.DEFAULT_SCAN_PAGE_SIZE {
+		members, err := a.GetTeamMembers(team.Id, offset, cmd.DEFAULT_SCAN_PAGE_SIZE)
+		if err != nil {
+			return err
+		}
+		for _, member := range members {
+			currentIds = append(currentIds, member.UserId)
+		}
+		if len(members) < cmd.DEFAULT_SCAN_PAGE_SIZE {
+			break
+		}
+	}
+
+	toAdd, toRemove := model.DiffIdSlices(currentIds, desiredIds)
+	if noRemove {
+		toRemove = []string{}
+	} else if failed := results.Count(cmd.ROW_STATUS_ERROR); failed > 0 && len(toRemove) > 0 {
+		missing := ValidateReferences(userArgs, func(userArg string) bool { return resolved[userArg] })
+		cmd.CommandPrettyPrintln(fmt.Sprintf("Warning: %v rows of %v couldn't be resolved (%v), so no members will be removed.", failed, path, strings.Join(missing, ", ")))
+		toRemove = []string{}
+	}
+
+	confirmFlag, _ := command.Flags().GetBool("confirm")
+	if !dryRun && len(toRemove) > 0 && !confirmFlag {
+		var confirm string
+		cmd.CommandPrettyPrintln(fmt.Sprintf("Are you sure you want to remove %v members who aren't in %v from team '%v'? (YES/NO): ", len(toRemove), path, team.Name))
+		fmt.Scanln(&confirm)
+		if confirm != "YES" {
+			return errors.New("ABORTED: You did not answer YES exactly, in all capitals.")
+		}
+	}
+
+	for _, userId := range toAdd {
+		user := desired[userId]
+		if dryRun {
+			results.Add(desiredLines[userId], user.Username, SYNC_MEMBER_WOULD_ADD, nil)
+		} else if err := a.JoinUserToTeam(team, user, ""); err != nil {
+			results.Add(desiredLines[userId], user.Username, SYNC_MEMBER_ADDED, err)
+		} else {
+			results.Add(desiredLines[userId], user.Username, SYNC_MEMBER_ADDED, nil)
+		}
+	}
+
+	for _, userId := range toRemove {
+		user, err := a.GetUser(userId)
+		if err != nil {
+			results.Add(0, userId, SYNC_MEMBER_REMOVED, err)
+			continue
+		}
+		if dryRun {
+			results.Add(0, user.Username, SYNC_MEMBER_WOULD_REMOVE, nil)
+		} else if err := a.LeaveTeam(team, user, ""); err != nil {
+			results.Add(0, user.Username, SYNC_MEMBER_REMOVED, err)
+		} else {
+			results.Add(0, user.Username, SYNC_MEMBER_REMOVED, nil)
+		}
+	}
+
+	switch format {
+	case "json":
+		cmd.CommandPrintln(results.ToJson())
+	case "table":
+		cmd.CommandPrint(results.ToTable())
+	default:
+		printSyncTeamMembersResults(results, team)
+	}
+
+	if format != "json" {
+		if dryRun {
+			cmd.CommandPrettyPrintln(fmt.Sprintf("Dry run: %v members would be added and %v removed.", results.Count(SYNC_MEMBER_WOULD_ADD), results.Count(SYNC_MEMBER_WOULD_REMOVE)))
+		} else {
+			cmd.CommandPrettyPrintln(fmt.Sprintf("%v members added and %v removed.", results.Count(SYNC_MEMBER_ADDED), results.Count(SYNC_MEMBER_REMOVED)))
+		}
+	}
+
+	if failed := results.Count(cmd.ROW_STATUS_ERROR); failed > 0 {
+		return fmt.Errorf("Unable to sync %v users with team '%v'.", failed, team.Name)
+	}
+
+	return nil
+}
+
+func printSyncTeamMembersResults(results cmd.RowResults, team *model.Team) {
+	for _, result := range results {
+		switch result.Status {
+		case SYNC_MEMBER_ADDED:
+			cmd.CommandPrintln("Added '" + result.Identifier + "'")
+		case SYNC_MEMBER_REMOVED:
+			cmd.CommandPrintln("Removed '" + result.Identifier + "'")
+		case SYNC_MEMBER_WOULD_ADD:
+			cmd.CommandPrintln("Would add '" + result.Identifier + "'")
+		case SYNC_MEMBER_WOULD_REMOVE:
+			cmd.CommandPrintln("Would remove '" + result.Identifier + "'")
+		case cmd.ROW_STATUS_ERROR:
+			cmd.CommandPrintErrorln(fmt.Sprintf("Unable to sync '%v' with %v. Error: %v", result.Identifier, team.Name, result.Error.Error()))
+		}
+	}
+}
+
+type rosterEntry struct {
+	Line    int
+	UserArg string
+}
+
+// readRosterFile returns the users listed in the first column of a roster file along with the line each was
+// found on. Rows are read a line at a time, so quoted values can't span lines.
+func readRosterFile(path string) ([]rosterEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	entries := []rosterEntry{}
+	scanner := bufio.NewScanner(file)
+	for line, header := 1, true; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		reader := csv.NewReader(strings.NewReader(text))
+		reader.TrimLeadingSpace = true
+		record, err := reader.Read()
+		if err != nil {
+			return nil, fmt.Errorf("line %v: %v", line, err.Error())
+		}
+
+		userArg := strings.TrimSpace(record[0])
+		if userArg == "" {
+			continue
+		}
+
+		if header {
+			header = false
+			switch strings.ToLower(userArg) {
+			case "email", "username", "user", "id":
+				continue
+			}
+		}
+
+		entries = append(entries, rosterEntry{Line: line, UserArg: userArg})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+type teamDefaultChannels struct {
+	TeamId   string   `json:"team_id"`
+	TeamName string   `json:"team_name"`
+	Channels []string `json:"channels"`
+}
+
+func defaultChannelsTeamCmdF(command *cobra.Command, args []string) error {
+	a, err := cmd.InitDBCommandContextCobra(command)
+	if err != nil {
+		return err
+	}
+
+	allFlag, _ := command.Flags().GetBool("all")
+	format, _ := command.Flags().GetString("format")
+	if format != "plain" && format != "json" {
+		return errors.New("Format must be either plain or json.")
+	}
+
+	if err := cmd.RequireArgsOrFlag(command, args, "all", "teams"); err != nil {
+		return err
+	}
+
+	var teams []*model.Team
+	if allFlag {
+		var err *model.AppError
+		if teams, err = a.GetAllTeams(); err != nil {
+			return err
+		}
+	} else {
+		teams = getTeamsFromTeamArgs(a, args)
+	}
+
+	results := []*teamDefaultChannels{}
+	for i, team := range teams {
+		if team == nil {
+			cmd.CommandPrintErrorln("Unable to find team '" + args[i] + "'")
+			continue
+		}
+		if team.DeleteAt > 0 && allFlag {
+			continue
+		}
+
+		channels, err := a.GetDefaultChannels(team.Id)
+		if err != nil {
+			cmd.CommandPrintErrorln("Unable to get the default channels of team '" + team.Name + "'. Error: " + err.Error())
+			continue
+		}
+
+		result := &teamDefaultChannels{TeamId: team.Id, TeamName: team.Name, Channels: []string{}}
+		for _, channel := range channels {
+			result.Channels = append(result.Channels, channel.Name)
+		}
+		results = append(results, result)
+	}
+
+	if format == "json" {
+		page := cmd.NewCompletePage(results, len(results))
+		cmd.CommandPrintln(page.ToJson())
+		return nil
+	}
+
+	table := cmd.NewTablePrinter("TEAM", "CHANNELS")
+	table.NoHeaders, _ = command.Flags().GetBool("no-headers")
+	for _, result := range results {
+		table.AddRow(result.TeamName, strings.Join(result.Channels, ", "))
+	}
+	cmd.CommandPrint(table.String())
+
+	return nil
+}
+
+func checkInviteFormatTeamsCmdF(command *cobra.Command, args []string) error {
+	a, err := cmd.InitDBCommandContextCobra(command)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := cmd.InterruptContext()
+	defer cancel()
+
+	affected := []*model.Team{}
+	scanned, err := cmd.ForEachTeam(ctx, a, cmd.DEFAULT_SCAN_PAGE_SIZE, func(team *model.Team) error {
+		if !model.IsValidInviteId(team.InviteId) {
+			affected = append(affected, team)
+			cmd.CommandPrintln(fmt.Sprintf("%v: %q", team.Name, team.InviteId))
+		}
+		return nil
+	})
+	if err := cmd.ScanError(err, "Scan interrupted after %v teams, %v with malformed invite ids found so far.", scanned, len(affected)); err != nil {
+		return err
+	}
+
+	if len(affected) == 0 {
+		cmd.CommandPrettyPrintln("No teams with malformed invite ids found.")
+		return nil
+	}
+
+	fixFlag, _ := command.Flags().GetBool("fix")
+	if !fixFlag {
+		return nil
+	}
+
+	confirmFlag, _ := command.Flags().GetBool("confirm")
+	if !confirmFlag {
+		var confirm string
+		cmd.CommandPrettyPrintln("Are you sure you want to replace the invite ids of the teams listed above? Existing invite links for them will stop working. (YES/NO): ")
+		fmt.Scanln(&confirm)
+		if confirm != "YES" {
+			return errors.New("ABORTED: You did not answer YES exactly, in all capitals.")
+		}
+	}
+
+	errorLog := cmd.NewDefaultThrottledLogger(cmd.CommandPrintErrorln)
+	progressLog := cmd.NewDefaultThrottledLogger(cmd.CommandPrettyPrintln)
+	for _, team := range affected {
+		team.InviteId = model.NewId()
+		if _, err := a.UpdateTeam(team); err != nil {
+			errorLog.Println("Unable to update team '" + team.Name + "' error: " + err.Error())
+		} else {
+			progressLog.Println("Generated a new invite id for team '" + team.Name + "'")
+		}
+	}
+	errorLog.Flush()
+	progressLog.Flush()
+
+	return nil
+}
+
+type teamInviteHealth struct {
+	TeamId     string   `json:"team_id"`
+	TeamName   string   `json:"team_name"`
+	InviteLink string   `json:"invite_link"`
+	Reasons    []string `json:"reasons"`
+}
+
+func inviteHealthTeamsCmdF(command *cobra.Command, args []string) error {
+	a, err := cmd.InitDBCommandContextCobra(command)
+	if err != nil {
+		return err
+	}
+
+	jsonFlag, _ := command.Flags().GetBool("json")
+
+	ctx, cancel := cmd.InterruptContext()
+	defer cancel()
+
+	siteURLValid := model.IsValidHttpUrl(*a.Config().ServiceSettings.SiteURL)
+
+	results := []*teamInviteHealth{}
+	scanned, err := cmd.ForEachTeam(ctx, a, cmd.DEFAULT_SCAN_PAGE_SIZE, func(team *model.Team) error {
+		if team.DeleteAt > 0 {
+			return nil
+		}
+
+		result := &teamInviteHealth{
+			TeamId:     team.Id,
+			TeamName:   team.Name,
+			InviteLink: a.GetTeamInviteLink(team),
+			Reasons:    []string{},
+		}
+		if !siteURLValid {
+			result.Reasons = append(result.Reasons, "invalid SiteURL")
+		}
+		if !model.IsValidInviteId(team.InviteId) {
+			result.Reasons = append(result.Reasons, "malformed invite id")
+		}
+		if len(result.Reasons) == 0 && !model.IsValidHttpUrl(result.InviteLink) {
+			result.Reasons = append(result.Reasons, "invalid invite link")
+		}
+
+		if len(result.Reasons) > 0 {
+			results = append(results, result)
+		}
+		return nil
+	})
+	if err := cmd.ScanError(err, "Scan interrupted after %v teams, %v with broken invite links found so far.", scanned, len(results)); err != nil {
+		return err
+	}
+
+	if jsonFlag {
+		page := cmd.NewCompletePage(results, len(results))
+		cmd.CommandPrintln(page.ToJson())
+		return nil
+	}
+
+	if len(results) == 0 {
+		cmd.CommandPrettyPrintln("All teams have valid invite links.")
+		return nil
+	}
+
+	for _, result := range results {
+		cmd.CommandPrintln(result.TeamName + ": " + strings.Join(result.Reasons, ", "))
+	}
+
+	return nil
+}
+
+type teamMemberHealth struct {
+	TeamId             string `json:"team_id"`
+	TeamName           string `json:"team_name"`
+	ActiveMembers      int64  `json:"active_members"`
+	DeactivatedMembers int64  `json:"deactivated_members"`
+	ActivePercent      string `json:"active_percent"`
+}
+
+var memberHealthSortFields = []string{"name", "active", "deactivated", "active_percent"}
+
+func memberHealthTeamsCmdF(command *cobra.Command, args []string) error {
+	a, err := cmd.InitDBCommandContextCobra(command)
+	if err != nil {
+		return err
+	}
+
+	allFlag, _ := command.Flags().GetBool("all")
+	jsonFlag, _ := command.Flags().GetBool("json")
+
+	sortFlag, _ := command.Flags().GetString("sort")
+	sortField, sortDesc := "", false
+	if sortFlag != "" {
+		if sortField, sortDesc, err = model.ParseSortSpec(sortFlag, memberHealthSortFields); err != nil {
+			return err
+		}
+	} else if allFlag {
+		sortField = "active_percent"
+	}
+
+	if err := cmd.RequireArgsOrFlag(command, args, "all", "teams"); err != nil {
+		return err
+	}
+
+	var teams []*model.Team
+	if allFlag {
+		var err *model.AppError
+		if teams, err = a.GetAllTeams(); err != nil {
+			return err
+		}
+	} else {
+		teams = getTeamsFromTeamArgs(a, args)
+	}
+
+	results := []*teamMemberHealth{}
+	ratios := map[string]float64{}
+	for i, team := range teams {
+		if team == nil {
+			cmd.CommandPrintErrorln("Unable to find team '" + args[i] + "'")
+			continue
+		}
+		if team.DeleteAt > 0 && allFlag {
+			continue
+		}
+
+		stats, err := a.GetTeamStats(team.Id)
+		if err != nil {
+			cmd.CommandPrintErrorln("Unable to get the members of team '" + team.Name + "'. Error: " + err.Error())
+			continue
+		}
+
+		results = append(results, &teamMemberHealth{
+			TeamId:             team.Id,
+			TeamName:           team.Name,
+			ActiveMembers:      stats.ActiveMemberCount,
+			DeactivatedMembers: stats.TotalMemberCount - stats.ActiveMemberCount,
+			ActivePercent:      model.FormatPercent(stats.ActiveMemberCount, stats.TotalMemberCount),
+		})
+		if stats.TotalMemberCount > 0 {
+			ratios[team.Id] = float64(stats.ActiveMemberCount) / float64(stats.TotalMemberCount)
+		}
+	}
+
+	if sortField != "" {
+		less := map[string]func(x, y *teamMemberHealth) bool{
+			"name":           func(x, y *teamMemberHealth) bool { return x.TeamName < y.TeamName },
+			"active":         func(x, y *teamMemberHealth) bool { return x.ActiveMembers < y.ActiveMembers },
+			"deactivated":    func(x, y *teamMemberHealth) bool { return x.DeactivatedMembers < y.DeactivatedMembers },
+			"active_percent": func(x, y *teamMemberHealth) bool { return ratios[x.TeamId] < ratios[y.TeamId] },
+		}[sortField]
+		sort.SliceStable(results, func(i, j int) bool {
+			if sortDesc {
+				return less(results[j], results[i])
+			}
+			return less(results[i], results[j])
+		})
+	}
+
+	if jsonFlag {
+		page := cmd.NewCompletePage(results, len(results))
+		cmd.CommandPrintln(page.ToJson())
+		return nil
+	}
+
+	table := cmd.NewTablePrinter("TEAM", "ACTIVE", "DEACTIVATED", "ACTIVE %")
+	table.NoHeaders, _ = command.Flags().GetBool("no-headers")
+	for _, result := range results {
+		table.AddRow(result.TeamName, result.ActiveMembers, result.DeactivatedMembers, result.ActivePercent)
+	}
+	cmd.CommandPrint(table.String())
+
+	return nil
+}
+
+func setQuotaTeamCmdF(command *cobra.Command, args []string) error {
+	a, err := cmd.InitDBCommandContextCobra(command)
+	if err != nil {
+		return err
+	}
+
+	if len(args) != 1 {
+		return errors.New("Expected exactly one team.")
+	}
+
+	maxStorage, _ := command.Flags().GetString("max-storage")
+	if maxStorage == "" {
+		return errors.New("Max storage is required")
+	}
+	quota, err := model.ParseByteSize(maxStorage)
+	if err != nil {
+		return err
+	}
+
+	team := getTeamFromTeamArg(a, args[0])
+	if team == nil {
+		return errors.New("Unable to find team '" + args[0] + "'")
+	}
+
+	if quota == 0 {
+		if _, err := a.DeleteTeamProp(team.Id, model.TEAM_PROP_MAX_STORAGE); err != nil {
+			return errors.New("Unable to remove the storage quota of team '" + team.Name + "'. Error: " + err.Error())
+		}
+		cmd.CommandPrettyPrintln("Removed the storage quota of team '" + team.Name + "'")
+		return nil
+	}
+
+	if _, err := a.SetTeamProp(team.Id, model.TEAM_PROP_MAX_STORAGE, strconv.FormatInt(quota, 10)); err != nil {
+		return errors.New("Unable to set the storage quota of team '" + team.Name + "'. Error: " + err.Error())
+	}
+	cmd.CommandPrettyPrintln("Set the storage quota of team '" + team.Name + "' to " + model.FormatBytes(quota))
+
+	if usage, err := a.GetTeamStorageUsage(team.Id); err != nil {
+		cmd.CommandPrintErrorln("Unable to get the storage used by team '" + team.Name + "'. Error: " + err.Error())
+	} else if usage > quota {
+		cmd.CommandPrettyPrintln("Warning: team '" + team.Name + "' already uses " + model.FormatBytes(usage) + ", which is over the new quota.")
+	}
+
+	return nil
+}
+
+func showQuotaTeamCmdF(command *cobra.Command, args []string) error {
+	a, err := cmd.InitDBCommandContextCobra(command)
+	if err != nil {
+		return err
+	}
+
+	if len(args) < 1 {
+		return errors.New("Enter at least one team.")
+	}
+
+	teams := getTeamsFromTeamArgs(a, args)
+	for i, team := range teams {
+		if team == nil {
+			cmd.CommandPrintErrorln("Unable to find team '" + args[i] + "'")
+			continue
+		}
+
+		usage, err := a.GetTeamStorageUsage(team.Id)
+		if err != nil {
+			cmd.CommandPrintErrorln("Unable to get the storage used by team '" + team.Name + "'. Error: " + err.Error())
+			continue
+		}
+
+		quota := team.GetStorageQuota()
+		if quota == 0 {
+			cmd.CommandPrintln(fmt.Sprintf("%v: %v used, no quota", team.Name, model.FormatBytes(usage)))
+			continue
+		}
+
+		line := fmt.Sprintf("%v: %v used of %v (%v)", team.Name, model.FormatBytes(usage), model.FormatBytes(quota), model.FormatPercent(usage, quota))
+		if usage > quota {
+			line += " (over quota)"
+		}
+		cmd.CommandPrintln(line)
+	}
+
+	return nil
+}
+
+const DEFAULT_PLACEHOLDER_TEAM_PATTERN = `(new|my|test|untitled) team( \d+)?|untitled`
+
+type teamPlaceholder struct {
+	TeamId      string `json:"team_id"`
+	TeamName    string `json:"team_name"`
+	DisplayName string `json:"display_name"`
+}
+
+func checkPlaceholderTeamsCmdF(command *cobra.Command, args []string) error {
+	a, err := cmd.InitDBCommandContextCobra(command)
+	if err != nil {
+		return err
+	}
+
+	pattern, _ := command.Flags().GetString("pattern")
+	placeholder, err := model.CompileUserRegex(`(?i)^(?:` + pattern + `)$`)
+	if err != nil {
+		return errors.New("Invalid pattern: " + err.Error())
+	}
+	jsonFlag, _ := command.Flags().GetBool("json")
+
+	ctx, cancel := cmd.InterruptContext()
+	defer cancel()
+
+	results := []*teamPlaceholder{}
+	scanned, err := cmd.ForEachTeam(ctx, a, cmd.DEFAULT_SCAN_PAGE_SIZE, func(team *model.Team) error {
+		if team.DeleteAt == 0 && placeholder.MatchString(strings.TrimSpace(team.DisplayName)) {
+			results = append(results, &teamPlaceholder{TeamId: team.Id, TeamName: team.Name, DisplayName: team.DisplayName})
+		}
+		return nil
+	})
+	if err := cmd.ScanError(err, "Scan interrupted after %v teams, %v with placeholder display names found so far.", scanned, len(results)); err != nil {
+		return err
+	}
+
+	if jsonFlag {
+		page := cmd.NewCompletePage(results, len(results))
+		cmd.CommandPrintln(page.ToJson())
+		return nil
+	}
+
+	if len(results) == 0 {
+		cmd.CommandPrettyPrintln("No teams with placeholder display names found.")
+		return nil
+	}
+
+	for _, result := range results {
+		cmd.CommandPrintln(fmt.Sprintf("%v: %q", result.TeamName, result.DisplayName))
+	}
+
+	return nil
+}
+
+func cloneTeamCmdF(command *cobra.Command, args []string) error {
+	a, err := cmd.InitDBCommandContextCobra(command)
+	if err != nil {
+		return err
+	}
+
+	if len(args) != 1 {
+		return errors.New("Expected exactly one source team.")
+	}
+
+	source := getTeamFromTeamArg(a, args[0])
+	if source == nil {
+		return errors.New("Unable to find team '" + args[0] + "'")
+	}
+
+	name, _ := command.Flags().GetString("name")
+	if model.IsBlank(name) {
+		return errors.New("Name is required")
+	}
+	name = canonicalizeTeamName(name)
+	if !model.IsValidTeamName(name) || model.IsReservedTeamName(name) {
+		return errors.New("Invalid team name '" + name + "'")
+	}
+	if name, err = resolveTeamNameCollision(a, command, name); err != nil {
+		return err
+	}
+	displayName, _ := command.Flags().GetString("display_name")
+	if model.IsBlank(displayName) {
+		return errors.New("Display Name is required")
+	}
+	withMembers, _ := command.Flags().GetBool("with-members")
+
+	team, channels, appErr := a.CloneTeam(source, name, displayName)
+	if team == nil {
+		return errors.New("Team clone failed, nothing was created: " + appErr.Error())
+	}
+
+	channelNames := make([]string, len(channels))
+	for i, channel := range channels {
+		channelNames[i] = channel.Name
+	}
+	cmd.CommandPrintln(fmt.Sprintf("Created team '%v' from '%v' with type %v.", team.Name, source.Name, team.Type))
+	if len(channelNames) > 0 {
+		cmd.CommandPrintln(fmt.Sprintf("Copied %v channels: %v", len(channelNames), strings.Join(channelNames, ", ")))
+	} else {
+		cmd.CommandPrintln("Copied 0 channels.")
+	}
+	if appErr != nil {
+		message := fmt.Sprintf("Team '%v' was created, but copying its channels stopped after %v of them", team.Name, len(channels))
+		if withMembers {
+			message += " and no members were copied"
+		}
+		return errors.New(message + ". Error: " + appErr.Error())
+	}
+
+	if withMembers {
+		added, appErr := a.CloneTeamMembers(source, team)
+		cmd.CommandPrintln(fmt.Sprintf("Copied %v members.", added))
+		if appErr != nil {
+			return fmt.Errorf("Team '%v' and its channels were created, but copying its members stopped after %v of them. Error: %v", team.Name, added, appErr.Error())
+		}
+	}
+
+	return nil
+}
+
+type teamAdminActivity struct {
+	TeamId         string `json:"team_id"`
+	TeamName       string `json:"team_name"`
+	UserId         string `json:"user_id"`
+	Username       string `json:"username"`
+	LastActivityAt int64  `json:"last_activity_at"`
+	Inactive       bool   `json:"inactive"`
+}
+
+func adminActivityTeamsCmdF(command *cobra.Command, args []string) error {
+	a, err := cmd.InitDBCommandContextCobra(command)
+	if err != nil {
+		return err
+	}
+
+	allFlag, _ := command.Flags().GetBool("all")
+	jsonFlag, _ := command.Flags().GetBool("json")
+	inactiveFlag, _ := command.Flags().GetString("inactive")
+	inactive, err := model.ParseExtendedDuration(inactiveFlag)
+	if err != nil {
+		return errors.New("Invalid --inactive: " + err.Error())
+	}
+	cutoff := utils.MillisFromTime(time.Now().Add(-inactive))
+
+	if err := cmd.RequireArgsOrFlag(command, args, "all", "teams"); err != nil {
+		return err
+	}
+
+	var teams []*model.Team
+	if allFlag {
+		var err *model.AppError
+		if teams, err = a.GetAllTeams(); err != nil {
+			return err
+		}
+	} else {
+		teams = getTeamsFromTeamArgs(a, args)
+	}
+
+	results := []*teamAdminActivity{}
+	for i, team := range teams {
+		if team == nil {
+			cmd.CommandPrintErrorln("Unable to find team '" + args[i] + "'")
+			continue
+		}
+		if team.DeleteAt > 0 && allFlag {
+			continue
+		}
+
+		admins, err := getTeamAdminActivity(a, team)
+		if err != nil {
+			cmd.CommandPrintErrorln("Unable to get the admins of team '" + team.Name + "'. Error: " + err.Error())
+			continue
+		}
+		for _, admin := range admins {
+			admin.Inactive = admin.LastActivityAt < cutoff
+		}
+		results = append(results, admins...)
+	}
+
+	if jsonFlag {
+		page := cmd.NewCompletePage(results, len(results))
+		cmd.CommandPrintln(page.ToJson())
+		return nil
+	}
+
+	table := cmd.NewTablePrinter("TEAM", "ADMIN", "LAST ACTIVE", "INACTIVE")
+	table.NoHeaders, _ = command.Flags().GetBool("no-headers")
+	for _, result := range results {
+		lastActive := "never"
+		if result.LastActivityAt > 0 {
+			lastActive = utils.TimeFromMillis(result.LastActivityAt).UTC().Format(time.RFC3339)
+		}
+		inactiveColumn := ""
+		if result.Inactive {
+			inactiveColumn = "yes"
+		}
+		table.AddRow(result.TeamName, result.Username, lastActive, inactiveColumn)
+	}
+	cmd.CommandPrint(table.String())
+
+	return nil
+}
+
+// getTeamAdminActivity returns the active admins of team along with the time they were last active according to
+// the status store, or 0 if they have no status.
+func getTeamAdminActivity(a *app.App, team *model.Team) ([]*teamAdminActivity, *model.AppError) {
+	admins := []*teamAdminActivity{}
+	for offset := 0; ; offset += 1000 {
+		members, err := a.GetTeamMembers(team.Id, offset, 1000)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, member := range members {
+			if member.DeleteAt > 0 || !model.IsInRole(member.Roles, model.TEAM_ADMIN_ROLE_ID) {
+				continue
+			}
+
+			user, err := a.GetUser(member.UserId)
+			if err != nil {
+				return nil, err
+			}
+			if user.DeleteAt > 0 {
+				continue
+			}
+			admins = append(admins, &teamAdminActivity{TeamId: team.Id, TeamName: team.Name, UserId: user.Id, Username: user.Username})
+		}
+
+		if len(members) < 1000 {
+			break
+		}
+	}
+
+	if len(admins) == 0 {
+		return admins, nil
+	}
+
+	userIds := make([]string, len(admins))
+	for i, admin := range admins {
+		userIds[i] = admin.UserId
+	}
+	statuses, err := a.GetUserStatusesByIds(userIds)
+	if err != nil {
+		return nil, err
+	}
+
+	lastActivity := make(map[string]int64, len(statuses))
+	for _, status := range statuses {
+		lastActivity[status.UserId] = status.LastActivityAt
+	}
+	for _, admin := range admins {
+		admin.LastActivityAt = lastActivity[admin.UserId]
+	}
+
+	return admins, nil
+}
+
+const (
+	TEAM_ARCHIVED      = "archived"
+	TEAM_WOULD_ARCHIVE = "would archive"
+	TEAM_NOT_FOUND     = "not found"
+)
+
+func bulkArchiveTeamsCmdF(command *cobra.Command, args []string) error {
+	a, err := cmd.InitDBCommandContextCobra(command)
+	if err != nil {
+		return err
+	}
+
+	path, _ := command.Flags().GetString("file")
+	if path == "" {
+		return errors.New("File is required")
+	}
+	dryRun, _ := command.Flags().GetBool("dry-run")
+
+	entries, err := readListFile(path)
+	if err != nil {
+		return err
+	}
+
+	results := cmd.RowResults{}
+	teams := map[int]*model.Team{}
+	for _, entry := range entries {
+		team := getTeamFromTeamArg(a, entry.Value)
+		if team == nil {
+			results.Add(entry.Line, entry.Value, TEAM_NOT_FOUND, nil)
+			cmd.CommandPrintErrorln(fmt.Sprintf("Line %v: unable to find team '%v'", entry.Line, entry.Value))
+			continue
+		}
+		if team.DeleteAt > 0 {
+			results.Add(entry.Line, entry.Value, cmd.ROW_STATUS_SKIPPED, nil)
+			cmd.CommandPrettyPrintln("Team '" + team.Name + "' is already archived")
+			continue
+		}
+
+		teams[entry.Line] = team
+		results.Add(entry.Line, entry.Value, TEAM_WOULD_ARCHIVE, nil)
+	}
+
+	if !dryRun && results.Count(TEAM_WOULD_ARCHIVE) > 0 {
+		confirmFlag, _ := command.Flags().GetBool("confirm")
+		if !confirmFlag {
+			var confirm string
+			cmd.CommandPrettyPrintln(fmt.Sprintf("Are you sure you want to archive %v teams? (YES/NO): ", results.Count(TEAM_WOULD_ARCHIVE)))
+			fmt.Scanln(&confirm)
+			if confirm != "YES" {
+				return errors.New("ABORTED: You did not answer YES exactly, in all capitals.")
+			}
+		}
+	}
+
+	for i, result := range results {
+		if result.Status != TEAM_WOULD_ARCHIVE {
+			continue
+		}
+
+		team := teams[result.Line]
+		if dryRun {
+			cmd.CommandPrintln("Would archive '" + team.Name + "'")
+			continue
+		}
+
+		if err := archiveTeam(a, team); err != nil {
+			results[i].Status, results[i].Error = cmd.ROW_STATUS_ERROR, err
+			cmd.CommandPrintErrorln("Unable to archive team '" + team.Name + "' error: " + err.Error())
+		} else {
+			results[i].Status = TEAM_ARCHIVED
+			cmd.CommandPrintln("Archived '" + team.Name + "'")
+		}
+	}
+
+	if dryRun {
+		cmd.CommandPrintln(fmt.Sprintf("Would archive %v teams, %v already archived, %v not found.", results.Count(TEAM_WOULD_ARCHIVE), results.Count(cmd.ROW_STATUS_SKIPPED), results.Count(TEAM_NOT_FOUND)))
+		return nil
+	}
+
+	cmd.CommandPrintln(fmt.Sprintf("Archived %v teams, %v already archived, %v not found, %v failed.", results.Count(TEAM_ARCHIVED), results.Count(cmd.ROW_STATUS_SKIPPED), results.Count(TEAM_NOT_FOUND), results.Count(cmd.ROW_STATUS_ERROR)))
+	if failed := results.Count(cmd.ROW_STATUS_ERROR); failed > 0 {
+		return fmt.Errorf("Unable to archive %v teams.", failed)
+	}
+
+	return nil
+}
+
+type listEntry struct {
+	Line  int
+	Value string
+}
+
+// readListFile returns the non-empty lines of a file that lists one value per line along with their line
+// numbers. Lines starting with # are comments.
+func readListFile(path string) ([]listEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	entries := []listEntry{}
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		entries = append(entries, listEntry{Line: line, Value: text})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+type teamArchivedChannels struct {
+	TeamId           string `json:"team_id"`
+	TeamName         string `json:"team_name"`
+	ArchivedChannels int64  `json:"archived_channels"`
+}
+
+func checkArchivedChannelsTeamsCmdF(command *cobra.Command, args []string) error {
+	a, err := cmd.InitDBCommandContextCobra(command)
+	if err != nil {
+		return err
+	}
+
+	max, _ := command.Flags().GetInt64("max")
+	jsonFlag, _ := command.Flags().GetBool("json")
+	purgeFlag, _ := command.Flags().GetString("purge-older-than")
+	var purgeBefore int64
+	if purgeFlag != "" {
+		age, err := model.ParseExtendedDuration(purgeFlag)
+		if err != nil {
+			return errors.New("Invalid --purge-older-than: " + err.Error())
+		}
+		purgeBefore = utils.MillisFromTime(time.Now().Add(-age))
+	}
+
+	ctx, cancel := cmd.InterruptContext()
+	defer cancel()
+
+	limiter := cmd.GetConcurrencyLimiter(command)
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+
+	results := []*teamArchivedChannels{}
+	scanned, err := cmd.ForEachTeam(ctx, a, cmd.DEFAULT_SCAN_PAGE_SIZE, func(team *model.Team) error {
+		if team.DeleteAt > 0 {
+			return nil
+		}
+
+		limiter.Acquire()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer limiter.Release()
+
+			count, err := a.GetArchivedChannelCount(team.Id)
+			if err != nil {
+				cmd.CommandPrintErrorln("Unable to count the archived channels of team '" + team.Name + "'. Error: " + err.Error())
+				return
+			}
+			if count > max {
+				mutex.Lock()
+				results = append(results, &teamArchivedChannels{TeamId: team.Id, TeamName: team.Name, ArchivedChannels: count})
+				mutex.Unlock()
+			}
+		}()
+		return nil
+	})
+	wg.Wait()
+	if err := cmd.ScanError(err, "Scan interrupted after %v teams, %v with too many archived channels found so far.", scanned, len(results)); err != nil {
+		return err
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].ArchivedChannels != results[j].ArchivedChannels {
+			return results[i].ArchivedChannels > results[j].ArchivedChannels
+		}
+		return results[i].TeamName < results[j].TeamName
+	})
+
+	if jsonFlag {
+		page := cmd.NewCompletePage(results, len(results))
+		cmd.CommandPrintln(page.ToJson())
+	} else if len(results) == 0 {
+		cmd.CommandPrettyPrintln(fmt.Sprintf("No teams with more than %v archived channels found.", max))
+	} else {
+		table := cmd.NewTablePrinter("TEAM", "ARCHIVED CHANNELS")
+		table.NoHeaders, _ = command.Flags().GetBool("no-headers")
+		for _, result := range results {
+			table.AddRow(result.TeamName, result.ArchivedChannels)
+		}
+		cmd.CommandPrint(table.String())
+	}
+
+	if purgeFlag == "" || len(results) == 0 {
+		return nil
+	}
+
+	confirmFlag, _ := command.Flags().GetBool("confirm")
+	if !confirmFlag {
+		var confirm string
+		cmd.CommandPrettyPrintln("Have you performed a database backup? (YES/NO): ")
+		fmt.Scanln(&confirm)
+		if confirm != "YES" {
+			return errors.New("ABORTED: You did not answer YES exactly, in all capitals.")
+		}
+		cmd.CommandPrettyPrintln(fmt.Sprintf("Are you sure you want to permanently delete the channels of the teams listed above that were archived more than %v ago? All of their posts will be deleted. (YES/NO): ", purgeFlag))
+		fmt.Scanln(&confirm)
+		if confirm != "YES" {
+			return errors.New("ABORTED: You did not answer YES exactly, in all capitals.")
+		}
+	}
+
+	errorLog := cmd.NewDefaultThrottledLogger(cmd.CommandPrintErrorln)
+	defer errorLog.Flush()
+	for _, result := range results {
+		channels, err := a.GetArchivedChannelsBefore(result.TeamId, purgeBefore)
+		if err != nil {
+			errorLog.Println("Unable to get the archived channels of team '" + result.TeamName + "'. Error: " + err.Error())
+			continue
+		}
+
+		deleted := 0
+		for _, channel := range channels {
+			if err := a.PermanentDeleteChannel(channel); err != nil {
+				errorLog.Println("Unable to delete channel '" + channel.Name + "' of team '" + result.TeamName + "'. Error: " + err.Error())
+				continue
+			}
+			deleted++
+		}
+		cmd.CommandPrettyPrintln(fmt.Sprintf("Deleted %v archived channels from team '%v'", deleted, result.TeamName))
+	}
+
+	return nil
+}
+
+func fixDefaultMembershipTeamCmdF(command *cobra.Command, args []string) error {
+	a, err := cmd.InitDBCommandContextCobra(command)
+	if err != nil {
+		return err
+	}
+
+	if len(args) != 1 {
+		return errors.New("Expected exactly one team.")
+	}
+
+	team := getTeamFromTeamArg(a, args[0])
+	if team == nil {
+		return errors.New("Unable to find team '" + args[0] + "'")
+	}
+	dryRun, _ := command.Flags().GetBool("dry-run")
+
+	channels, appErr := a.GetDefaultChannels(team.Id)
+	if appErr != nil {
+		return errors.New("Unable to get the default channels of team '" + team.Name + "'. Error: " + appErr.Error())
+	}
+
+	channelMembers := make(map[string]map[string]bool, len(channels))
+	for _, channel := range channels {
+		channelMembers[channel.Id] = map[string]bool{}
+		for page := 0; ; page++ {
+			members, err := a.GetChannelMembersPage(channel.Id, page, cmd.DEFAULT_SCAN_PAGE_SIZE)
+			if err != nil {
+				return errors.New("Unable to get the members of channel '" + channel.Name + "'. Error: " + err.Error())
+			}
+			for _, member := range *members {
+				channelMembers[channel.Id][member.UserId] = true
+			}
+			if len(*members) < cmd.DEFAULT_SCAN_PAGE_SIZE {
+				break
+			}
+		}
+	}
+
+	added, failed, affected := 0, 0, 0
+	for offset := 0; ; offset += cmd.DEFAULT_SCAN_PAGE_SIZE {
+		members, err := a.GetTeamMembers(team.Id, offset, cmd.DEFAULT_SCAN_PAGE_SIZE)
+		if err != nil {
+			return errors.New("Unable to get the members of team '" + team.Name + "'. Error: " + err.Error())
+		}
+
+		for _, member := range members {
+			if member.DeleteAt > 0 {
+				continue
+			}
+
+			var user *model.User
+			missing := false
+			for _, channel := range channels {
+				if channelMembers[channel.Id][member.UserId] {
+					continue
+				}
+
+				if user == nil {
+					if user, err = a.GetUser(member.UserId); err != nil {
+						cmd.CommandPrintErrorln("Unable to find user '" + member.UserId + "'. Error: " + err.Error())
+						failed++
+						break
+					}
+				}
+				if user.DeleteAt > 0 {
+					break
+				}
+				missing = true
+
+				if dryRun {
+					cmd.CommandPrintln("Would add '" + user.Username + "' to " + channel.Name)
+					added++
+				} else if _, err := a.AddUserToChannel(user, channel); err != nil {
+					cmd.CommandPrintErrorln("Unable to add '" + user.Username + "' to " + channel.Name + ". Error: " + err.Error())
+					failed++
+				} else {
+					cmd.CommandPrintln("Added '" + user.Username + "' to " + channel.Name)
+					added++
+				}
+			}
+			if missing {
+				affected++
+			}
+		}
+
+		if len(members) < cmd.DEFAULT_SCAN_PAGE_SIZE {
+			break
+		}
+	}
+
+	if dryRun {
+		cmd.CommandPrintln(fmt.Sprintf("Would add %v missing memberships for %v members of team '%v'.", added, affected, team.Name))
+	} else {
+		cmd.CommandPrintln(fmt.Sprintf("Added %v missing memberships for %v members of team '%v', %v failed.", added, affected, team.Name, failed))
+	}
+
+	return nil
+}
+
+type teamWebhook struct {
+	Id          string   `json:"id"`
+	Type        string   `json:"type"`
+	DisplayName string   `json:"display_name"`
+	ChannelId   string   `json:"channel_id"`
+	URLs        []string `json:"urls"`
+	InvalidURLs []string `json:"invalid_urls"`
+}
+
+func newTeamWebhook(id, hookType, displayName, channelId string, urls []string) *teamWebhook {
+	hook := &teamWebhook{
+		Id:          id,
+		Type:        hookType,
+		DisplayName: displayName,
+		ChannelId:   channelId,
+		URLs:        urls,
+		InvalidURLs: []string{},
+	}
+	for _, url := range urls {
+		if !model.IsValidHttpUrl(url) {
+			hook.InvalidURLs = append(hook.InvalidURLs, url)
+		}
+	}
+	return hook
+}
+
+func listWebhooksTeamCmdF(command *cobra.Command, args []string) error {
+	a, err := cmd.InitDBCommandContextCobra(command)
+	if err != nil {
+		return err
+	}
+
+	if len(args) != 1 {
+		return errors.New("Expected exactly one team.")
+	}
+
+	team := getTeamFromTeamArg(a, args[0])
+	if team == nil {
+		return errors.New("Unable to find team '" + args[0] + "'")
+	}
+	jsonFlag, _ := command.Flags().GetBool("json")
+
+	// The webhooks are read from the store directly, since the app layer refuses to list them while webhooks are
+	// disabled and an admin may want to review them before turning webhooks back on.
+	results := []*teamWebhook{}
+	for offset := 0; ; offset += cmd.DEFAULT_SCAN_PAGE_SIZE {
+		result := <-a.Srv.Store.Webhook().GetIncomingByTeam(team.Id, offset, cmd.DEFAULT_SCAN_PAGE_SIZE)
+		if result.Err != nil {
+			return errors.New("Unable to get the incoming webhooks of team '" + team.Name + "'. Error: " + result.Err.Error())
+		}
+		hooks := result.Data.([]*model.IncomingWebhook)
+
+		for _, hook := range hooks {
+			urls := []string{a.GetIncomingWebhookURL(hook)}
+			if hook.IconURL != "" {
+				urls = append(urls, hook.IconURL)
+			}
+			results = append(results, newTeamWebhook(hook.Id, "incoming", hook.DisplayName, hook.ChannelId, urls))
+		}
+
+		if len(hooks) < cmd.DEFAULT_SCAN_PAGE_SIZE {
+			break
+		}
+	}
+
+	for offset := 0; ; offset += cmd.DEFAULT_SCAN_PAGE_SIZE {
+		result := <-a.Srv.Store.Webhook().GetOutgoingByTeam(team.Id, offset, cmd.DEFAULT_SCAN_PAGE_SIZE)
+		if result.Err != nil {
+			return errors.New("Unable to get the outgoing webhooks of team '" + team.Name + "'. Error: " + result.Err.Error())
+		}
+		hooks := result.Data.([]*model.OutgoingWebhook)
+
+		for _, hook := range hooks {
+			results = append(results, newTeamWebhook(hook.Id, "outgoing", hook.DisplayName, hook.ChannelId, hook.CallbackURLs))
+		}
+
+		if len(hooks) < cmd.DEFAULT_SCAN_PAGE_SIZE {
+			break
+		}
+	}
+
+	if jsonFlag {
+		page := cmd.NewCompletePage(results, len(results))
+		cmd.CommandPrintln(page.ToJson())
+		return nil
+	}
+
+	if len(results) == 0 {
+		cmd.CommandPrettyPrintln("No webhooks found for team '" + team.Name + "'.")
+		return nil
+	}
+
+	table := cmd.NewTablePrinter("TYPE", "ID", "NAME", "URL", "INVALID")
+	table.NoHeaders, _ = command.Flags().GetBool("no-headers")
+	for _, result := range results {
+		invalid := make(map[string]bool, len(result.InvalidURLs))
+		for _, url := range result.InvalidURLs {
+			invalid[url] = true
+		}
+
+		for _, url := range result.URLs {
+			invalidColumn := ""
+			if invalid[url] {
+				invalidColumn = "yes"
+			}
+			table.AddRow(result.Type, result.Id, result.DisplayName, url, invalidColumn)
+		}
+	}
+	cmd.CommandPrint(table.String())
+
+	if invalid := countInvalidWebhooks(results); invalid > 0 {
+		cmd.CommandPrettyPrintln(fmt.Sprintf("%v of %v webhooks have invalid URLs.", invalid, len(results)))
+	}
+
+	return nil
+}
+
+func countInvalidWebhooks(hooks []*teamWebhook) int {
+	count := 0
+	for _, hook := range hooks {
+		if len(hook.InvalidURLs) > 0 {
+			count++
+		}
+	}
+	return count
+}
+
+const (
+	TEAM_PROP_ISSUE_MISSING = "missing"
+	TEAM_PROP_ISSUE_CYCLE   = "cycle"
+)
+
+// teamPropIssue is a problem with the references made by a team property. For missing references, Teams holds
+// the referencing team and Value what it references. For cycles, Teams holds the teams in the cycle in order.
+type teamPropIssue struct {
+	Type  string   `json:"type"`
+	Teams []string `json:"teams"`
+	Value string   `json:"value,omitempty"`
+}
+
+func checkPropCyclesTeamsCmdF(command *cobra.Command, args []string) error {
+	a, err := cmd.InitDBCommandContextCobra(command)
+	if err != nil {
+		return err
+	}
+
+	prop, _ := command.Flags().GetString("prop")
+	if model.IsBlank(prop) {
+		return errors.New("Prop is required")
+	}
+	jsonFlag, _ := command.Flags().GetBool("json")
+
+	ctx, cancel := cmd.InterruptContext()
+	defer cancel()
+
+	teams := []*model.Team{}
+	scanned, err := cmd.ForEachTeam(ctx, a, cmd.DEFAULT_SCAN_PAGE_SIZE, func(team *model.Team) error {
+		teams = append(teams, team)
+		return nil
+	})
+	if err := cmd.ScanError(err, "Scan interrupted after %v teams.", scanned); err != nil {
+		return err
+	}
+
+	issues := findTeamPropIssues(teams, prop)
+
+	if jsonFlag {
+		page := cmd.NewCompletePage(issues, len(issues))
+		cmd.CommandPrintln(page.ToJson())
+		return nil
+	}
+
+	if len(issues) == 0 {
+		cmd.CommandPrettyPrintln("No missing or circular references found in " + prop + ".")
+		return nil
+	}
+
+	for _, issue := range issues {
+		switch {
+		case issue.Type == TEAM_PROP_ISSUE_MISSING:
+			cmd.CommandPrintln(fmt.Sprintf("Team '%v' references missing team '%v' in %v.", issue.Teams[0], issue.Value, prop))
+		case len(issue.Teams) == 1:
+			cmd.CommandPrintln(fmt.Sprintf("Team '%v' references itself in %v.", issue.Teams[0], prop))
+		default:
+			cmd.CommandPrintln(fmt.Sprintf("Cycle in %v: %v -> %v", prop, strings.Join(issue.Teams, " -> "), issue.Teams[0]))
+		}
+	}
+
+	return nil
+}
+
+// findTeamPropIssues follows the team referenced by prop from each team, matched by name or id, and returns
+// the references to missing teams followed by each cycle, reported once.
+func findTeamPropIssues(teams []*model.Team, prop string) []*teamPropIssue {
+	byRef := make(map[string]*model.Team, 2*len(teams))
+	for _, team := range teams {
+		byRef[team.Id] = team
+		byRef[team.Name] = team
+	}
+
+	issues := []*teamPropIssue{}
+	next := make(map[string]*model.Team, len(teams))
+	for _, team := range teams {
+		value := team.GetProp(prop)
+		if value == "" {
+			continue
+		}
+
+		if target, ok := byRef[value]; ok {
+			next[team.Id] = target
+		} else {
+			issues = append(issues, &teamPropIssue{Type: TEAM_PROP_ISSUE_MISSING, Teams: []string{team.Name}, Value: value})
+		}
+	}
+
+	// Each team references at most one other, so following the references from a team either ends or reaches
+	// a team already seen. When that team was first seen during the same walk, the walk has gone around a cycle.
+	walkOf := make(map[string]int, len(teams))
+	for i, team := range teams {
+		path := []*model.Team{}
+		for current := team; current != nil; current = next[current.Id] {
+			if walk, seen := walkOf[current.Id]; seen {
+				if walk == i+1 {
+					issues = append(issues, &teamPropIssue{Type: TEAM_PROP_ISSUE_CYCLE, Teams: teamNamesFrom(path, current)})
+				}
+				break
+			}
+
+			walkOf[current.Id] = i + 1
+			path = append(path, current)
+		}
+	}
+
+	return issues
+}
+
+// teamNamesFrom returns the names of the teams in path starting from first.
+func teamNamesFrom(path []*model.Team, first *model.Team) []string {
+	names := []string{}
+	for _, team := range path {
+		if team.Id == first.Id || len(names) > 0 {
+			names = append(names, team.Name)
+		}
+	}
+	return names
+}
+
+func inviteQRTeamCmdF(command *cobra.Command, args []string) error {
+	a, err := cmd.InitDBCommandContextCobra(command)
+	if err != nil {
+		return err
+	}
+
+	if len(args) != 1 {
+		return errors.New("Expected exactly one team.")
+	}
+
+	output, _ := command.Flags().GetString("output")
+	if output == "" {
+		return errors.New("Output is required")
+	}
+	size, _ := command.Flags().GetInt("size")
+	if size <= 0 {
+		return errors.New("Size must be a positive number of pixels.")
+	}
+
+	team := getTeamFromTeamArg(a, args[0])
+	if team == nil {
+		return errors.New("Unable to find team '" + args[0] + "'")
+	}
+
+	link := a.GetTeamInviteLink(team)
+	if !model.IsValidHttpUrl(link) {
+		return errors.New("The invite link '" + link + "' isn't a valid URL. Check that ServiceSettings.SiteURL is set.")
+	}
+
+	code, err := qr.Encode(link, qr.M)
+	if err != nil {
+		return errors.New("Unable to encode the invite link. Error: " + err.Error())
+	}
+
+	// The image includes a 4 module wide quiet zone on each side of the code.
+	modules := code.Size + 8
+	if size < modules {
+		return fmt.Errorf("Size must be at least %v pixels to fit the code.", modules)
+	}
+	code.Scale = size / modules
+
+	if err := ioutil.WriteFile(output, code.PNG(), 0600); err != nil {
+		return err
+	}
+
+	width := modules * code.Scale
+	cmd.CommandPrintln(fmt.Sprintf("Saved a %vx%v QR code of %v to %v", width, width, link, output))
+
+	return nil
+}
+
+func checkChannelsTeamsCmdF(command *cobra.Command, args []string) error {
+	a, err := cmd.InitDBCommandContextCobra(command)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := cmd.InterruptContext()
+	defer cancel()
+
+	affected := []*model.Team{}
+	scanned, err := cmd.ForEachTeam(ctx, a, cmd.DEFAULT_SCAN_PAGE_SIZE, func(team *model.Team) error {
+		if team.DeleteAt > 0 {
+			return nil
+		}
+
+		if _, err := a.GetNumberOfChannelsOnTeam(team.Id); err == nil {
+			return nil
+		} else if err.StatusCode != http.StatusNotFound {
+			cmd.CommandPrintErrorln("Unable to get the channels of team '" + team.Name + "'. Error: " + err.Error())
+			return nil
+		}
+
+		affected = append(affected, team)
+		cmd.CommandPrintln(team.Name)
+		return nil
+	})
+	if err := cmd.ScanError(err, "Scan interrupted after %v teams, %v without channels found so far.", scanned, len(affected)); err != nil {
+		return err
+	}
+
+	if len(affected) == 0 {
+		cmd.CommandPrettyPrintln("No teams without channels found.")
+		return nil
+	}
+
+	fixFlag, _ := command.Flags().GetBool("fix")
+	if !fixFlag {
+		return nil
+	}
+
+	confirmFlag, _ := command.Flags().GetBool("confirm")
+	if !confirmFlag {
+		var confirm string
+		cmd.CommandPrettyPrintln("Are you sure you want to create the default channels of the teams listed above? (YES/NO): ")
+		fmt.Scanln(&confirm)
+		if confirm != "YES" {
+			return errors.New("ABORTED: You did not answer YES exactly, in all capitals.")
+		}
+	}
+
+	errorLog := cmd.NewDefaultThrottledLogger(cmd.CommandPrintErrorln)
+	progressLog := cmd.NewDefaultThrottledLogger(cmd.CommandPrettyPrintln)
+	for _, team := range affected {
+		if _, err := a.CreateDefaultChannels(team.Id); err != nil {
+			errorLog.Println("Unable to create the default channels of team '" + team.Name + "' error: " + err.Error())
+		} else {
+			progressLog.Println("Created the default channels of team '" + team.Name + "'")
+		}
+	}
+	errorLog.Flush()
+	progressLog.Flush()
+
+	return nil
+}
+
+func searchTeamsCmdF(command *cobra.Command, args []string) error {
+	a, err := cmd.InitDBCommandContextCobra(command)
+	if err != nil {
+		return err
+	}
+
+	teams := []*model.Team{}
+	found := map[string]bool{}
+	for _, term := range args {
+		matches, err := a.SearchAllTeamsBySubstring(term)
+		if err != nil {
+			return errors.New("Unable to search for teams matching '" + term + "'. Error: " + err.Error())
+		}
+
+		for _, team := range matches {
+			if !found[team.Id] {
+				found[team.Id] = true
+				teams = append(teams, team)
+			}
+		}
+	}
+
+	if len(teams) == 0 {
+		cmd.CommandPrettyPrintln("No teams found")
+		return nil
+	}
+
+	sort.Slice(teams, func(i, j int) bool {
+		return teams[i].Name < teams[j].Name
+	})
+
+	table := cmd.NewTablePrinter("NAME", "DISPLAY NAME", "TYPE")
+	for _, team := range teams {
+		name := team.Name
+		if team.DeleteAt > 0 {
+			name += " (archived)"
+		}
+		table.AddRow(name, team.DisplayName, team.Type)
+	}
+	cmd.CommandPrint(table.String())
+
+	return nil
+}
+
+type teamMemberInfo struct {
+	UserId      string `json:"user_id"`
+	Username    string `json:"username"`
+	Email       string `json:"email"`
+	Roles       string `json:"roles"`
+	Deactivated bool   `json:"deactivated"`
+}
+
+func membersTeamCmdF(command *cobra.Command, args []string) error {
+	a, err := cmd.InitDBCommandContextCobra(command)
+	if err != nil {
+		return err
+	}
+
+	if len(args) != 1 {
+		return errors.New("Expected exactly one team.")
+	}
+
+	page, _ := command.Flags().GetInt("page")
+	perPage, _ := command.Flags().GetInt("per_page")
+	if page < 0 || perPage < 0 {
+		return errors.New("Page and per page can't be negative.")
+	}
+	perPage = cmd.ResolveLimit(perPage, cmd.DEFAULT_LIST_LIMIT, cmd.MAX_LIST_LIMIT)
+	jsonFlag, _ := command.Flags().GetBool("json")
+
+	team := getTeamFromTeamArg(a, args[0])
+	if team == nil {
+		return errors.New("Unable to find team '" + args[0] + "'")
+	}
+
+	members, appErr := a.GetTeamMembers(team.Id, page*perPage, perPage)
+	if appErr != nil {
+		return errors.New("Unable to get the members of team '" + team.Name + "'. Error: " + appErr.Error())
+	}
+
+	userIds := make([]string, len(members))
+	for i, member := range members {
+		userIds[i] = member.UserId
+	}
+
+	users := map[string]*model.User{}
+	if len(userIds) > 0 {
+		profiles, appErr := a.GetUsersByIds(userIds, true)
+		if appErr != nil {
+			return errors.New("Unable to get the members of team '" + team.Name + "'. Error: " + appErr.Error())
+		}
+		for _, user := range profiles {
+			users[user.Id] = user
+		}
+	}
+
+	results := []*teamMemberInfo{}
+	for _, member := range members {
+		result := &teamMemberInfo{UserId: member.UserId, Roles: member.Roles}
+		if user, ok := users[member.UserId]; ok {
+			result.Username = user.Username
+			result.Email = user.Email
+			result.Deactivated = user.DeleteAt > 0
+		}
+		results = append(results, result)
+	}
+
+	if jsonFlag {
+		stats, appErr := a.GetTeamStats(team.Id)
+		if appErr != nil {
+			return errors.New("Unable to count the members of team '" + team.Name + "'. Error: " + appErr.Error())
+		}
+		page := &model.Page{Items: results, Offset: page * perPage, Limit: perPage, Total: int(stats.TotalMemberCount)}
+		cmd.CommandPrintln(page.ToJson())
+		return nil
+	}
+
+	if len(results) == 0 {
+		cmd.CommandPrettyPrintln("No members found.")
+		return nil
+	}
+
+	table := cmd.NewTablePrinter("USERNAME", "EMAIL", "ROLES")
+	table.NoHeaders, _ = command.Flags().GetBool("no-headers")
+	for _, result := range results {
+		username := result.Username
+		if result.Deactivated {
+			username += " (deactivated)"
+		}
+		table.AddRow(username, result.Email, result.Roles)
+	}
+	cmd.CommandPrint(table.String())
+
+	return nil
+}
+
+func engagementTeamCmdF(command *cobra.Command, args []string) error {
+	a, err := cmd.InitDBCommandContextCobra(command)
+	if err != nil {
+		return err
+	}
+
+	if err := cmd.RequireArgsOrFlag(command, args, "all", "a team"); err != nil {
+		return err
+	} else if len(args) > 1 {
+		return errors.New("Expected exactly one team, or --all.")
+	}
+	allFlag, _ := command.Flags().GetBool("all")
+	jsonFlag, _ := command.Flags().GetBool("json")
+
+	results := []*model.TeamEngagement{}
+	if allFlag {
+		ctx, cancel := cmd.InterruptContext()
+		defer cancel()
+
+		errorLog := cmd.NewDefaultThrottledLogger(cmd.CommandPrintErrorln)
+		defer errorLog.Flush()
+
+		scanned, err := cmd.ForEachTeam(ctx, a, cmd.DEFAULT_SCAN_PAGE_SIZE, func(team *model.Team) error {
+			if team.DeleteAt > 0 {
+				return nil
+			}
+
+			engagement, appErr := a.GetTeamEngagement(team)
+			if appErr != nil {
+				errorLog.Println("Unable to score team '" + team.Name + "'. Error: " + appErr.Error())
+				return nil
+			}
+			results = append(results, engagement)
+			return nil
+		})
+		if err := cmd.ScanError(err, "Scan interrupted after %v teams.", scanned); err != nil {
+			return err
+		}
+
+		sort.SliceStable(results, func(i, j int) bool {
+			if results[i].Score != results[j].Score {
+				return results[i].Score < results[j].Score
+			}
+			return results[i].TeamName < results[j].TeamName
+		})
+	} else {
+		team := getTeamFromTeamArg(a, args[0])
+		if team == nil {
+			return errors.New("Unable to find team '" + args[0] + "'")
+		}
+
+		engagement, appErr := a.GetTeamEngagement(team)
+		if appErr != nil {
+			return errors.New("Unable to score team '" + team.Name + "'. Error: " + appErr.Error())
+		}
+		results = append(results, engagement)
+	}
+
+	if jsonFlag {
+		page := cmd.NewCompletePage(results, len(results))
+		cmd.CommandPrintln(page.ToJson())
+		return nil
+	}
+
+	if len(results) == 0 {
+		cmd.CommandPrettyPrintln("No teams found.")
+		return nil
+	}
+
+	table := cmd.NewTablePrinter("TEAM", "SCORE", "ACTIVE MEMBERS", "POSTS PER ACTIVE MEMBER", "DAYS SINCE LAST POST")
+	table.NoHeaders, _ = command.Flags().GetBool("no-headers")
+	for _, result := range results {
+		daysSinceLastPost := "never posted"
+		if result.DaysSinceLastPost != model.ENGAGEMENT_NEVER_POSTED_DAYS {
+			daysSinceLastPost = fmt.Sprintf("%.1f", result.DaysSinceLastPost)
+		}
+		table.AddRow(
+			result.TeamName,
+			fmt.Sprintf("%.1f", result.Score),
+			fmt.Sprintf("%v of %v (%.0f%%)", result.ActiveMembers, result.TotalMembers, 100*result.ActiveMemberRatio),
+			fmt.Sprintf("%.1f", result.PostsPerActiveMember),
+			daysSinceLastPost,
+		)
 	}
+	cmd.CommandPrint(table.String())
 
 	return nil
 }
@@ -4,12 +4,23 @@
 package commands
 
 import (
+	"fmt"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/mattermost/mattermost-server/api"
 	"github.com/mattermost/mattermost-server/cmd"
 	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/store"
+	"github.com/mattermost/mattermost-server/utils"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCreateTeam(t *testing.T) {
@@ -27,6 +38,192 @@ func TestCreateTeam(t *testing.T) {
 	if !found {
 		t.Fatal("Failed to create Team")
 	}
+
+	require.Error(t, cmd.RunCommand(t, "team", "create", "--name", "name"+model.NewId(), "--display_name", "   "))
+	require.Error(t, cmd.RunCommand(t, "team", "create", "--name", "\t", "--display_name", displayName))
+
+	require.Error(t, cmd.RunCommand(t, "team", "create", "--name", strings.ToUpper(name), "--display_name", displayName), "names differing only in case should collide")
+	require.Error(t, cmd.RunCommand(t, "team", "create", "--name", " "+name+" ", "--display_name", displayName), "names differing only in whitespace should collide")
+
+	mixedCase := "Name" + model.NewId()
+	output := cmd.CheckCommand(t, "team", "create", "--name", mixedCase, "--display_name", displayName)
+	require.Contains(t, output, "Using team name '"+strings.ToLower(mixedCase)+"' instead of '"+mixedCase+"'.")
+	found = th.SystemAdminClient.Must(th.SystemAdminClient.FindTeamByName(strings.ToLower(mixedCase))).Data.(bool)
+	require.True(t, found, "the team should be created with the lowercase name")
+
+	output = cmd.CheckCommand(t, "team", "create", "--name", name, "--display_name", displayName, "--auto-suffix")
+	require.Contains(t, output, "A team named '"+name+"' already exists, using '"+name+"-2' instead.")
+	found = th.SystemAdminClient.Must(th.SystemAdminClient.FindTeamByName(name + "-2")).Data.(bool)
+	require.True(t, found, "the team should be created with a suffix")
+
+	team, err := th.App.GetTeamByName(name)
+	require.Nil(t, err)
+	require.Equal(t, model.TEAM_CREATION_SOURCE_CLI, team.GetCreationSource())
+
+	require.Error(t, cmd.RunCommand(t, "team", "create", "--name", "name"+model.NewId(), "--display_name", displayName, "--email", "admin@example"))
+
+	output = cmd.CheckCommand(t, "team", "create", "--name", "name"+model.NewId(), "--display_name", displayName, "--email", th.SystemAdminUser.Email)
+	require.NotContains(t, output, "Warning")
+
+	nobody := "nobody" + model.NewId() + "@example.com"
+	output = cmd.CheckCommand(t, "team", "create", "--name", "name"+model.NewId(), "--display_name", displayName, "--email", nobody)
+	require.Contains(t, output, "Warning: no user has the email '"+nobody+"'")
+}
+
+func TestCreateTeamsFromFile(t *testing.T) {
+	th := api.Setup().InitBasic()
+	defer th.TearDown()
+
+	id := model.NewId()
+	openName := "open" + id
+	inviteName := "invite" + id
+
+	dir, err := ioutil.TempDir("", "bulk-file")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "teams.csv")
+	contents := "name,display_name,type,email\n" +
+		openName + ",Open Team,open,\n" +
+		"# a comment\n\n" +
+		inviteName + ",\"Invite, Only\",invite," + th.GenerateTestEmail() + "\n" +
+		"Not Valid!,Bad Name,open,\n" +
+		th.BasicTeam.Name + ",Existing,open,\n" +
+		"badtype" + id + ",Bad Type,secret,\n" +
+		strings.ToUpper(openName) + ",Open Again,open,\n" +
+		"Mixed-" + id + ",Mixed Case,open,\n"
+	require.NoError(t, ioutil.WriteFile(path, []byte(contents), 0600))
+
+	output := cmd.CheckCommandFails(t, "team", "create", "--bulk-file", path)
+	require.Contains(t, output, "Created 3 teams from "+path+", 4 failed.")
+	require.Contains(t, output, "Line 9: Team '"+openName+"' is already listed on line 2")
+	require.Contains(t, output, "Line 6: Invalid team name 'Not Valid!'")
+	require.Contains(t, output, "Line 7: A team named '"+th.BasicTeam.Name+"' already exists")
+	require.Contains(t, output, "Line 8: Type of team 'badtype"+id+"' must be either open or invite")
+
+	openTeam, appErr := th.App.GetTeamByName(openName)
+	require.Nil(t, appErr)
+	require.Equal(t, "Open Team", openTeam.DisplayName)
+	require.Equal(t, model.TEAM_OPEN, openTeam.Type)
+	require.Equal(t, model.TEAM_CREATION_SOURCE_CLI, openTeam.GetCreationSource())
+
+	invite, appErr := th.App.GetTeamByName(inviteName)
+	require.Nil(t, appErr)
+	require.Equal(t, "Invite, Only", invite.DisplayName)
+	require.Equal(t, model.TEAM_INVITE, invite.Type)
+
+	_, appErr = th.App.GetTeamByName("mixed-" + id)
+	require.Nil(t, appErr, "names from the file should be lowercased like a single team create")
+
+	require.Error(t, cmd.RunCommand(t, "team", "create", "--bulk-file", path, "--name", "other"+id))
+	require.Error(t, cmd.RunCommand(t, "team", "create", "--bulk-file", filepath.Join(dir, "missing.csv")))
+
+	multiline := filepath.Join(dir, "multiline.csv")
+	multilineName := "multiline" + id
+	require.NoError(t, ioutil.WriteFile(multiline, []byte("name,display_name\n"+multilineName+",\"Two\nLines\"\n"), 0600))
+	cmd.CheckCommand(t, "team", "create", "--bulk-file", multiline)
+	team, appErr := th.App.GetTeamByName(multilineName)
+	require.Nil(t, appErr)
+	require.Equal(t, "Two\nLines", team.DisplayName)
+
+	headerless := filepath.Join(dir, "headerless.csv")
+	firstName, secondName := "first"+id, "second"+id
+	require.NoError(t, ioutil.WriteFile(headerless, []byte(firstName+",First Team,invite\n"+secondName+",Second Team\n"), 0600))
+	output = cmd.CheckCommand(t, "team", "create", "--bulk-file", headerless)
+	require.Contains(t, output, "Created 2 teams from "+headerless+", 0 failed.")
+	first, appErr := th.App.GetTeamByName(firstName)
+	require.Nil(t, appErr, "the first row of a file without a header should be created")
+	require.Equal(t, model.TEAM_INVITE, first.Type)
+	_, appErr = th.App.GetTeamByName(secondName)
+	require.Nil(t, appErr)
+
+	invalid := filepath.Join(dir, "invalid.csv")
+	invalidName := "invalid" + id
+	require.NoError(t, ioutil.WriteFile(invalid, []byte("name,display_name,type,email\n"+invalidName+",Valid,open,\nother"+id+",Bad Email,open,not-an-email\n"), 0600))
+	output = cmd.CheckCommandFails(t, "team", "create", "--bulk-file", invalid)
+	require.Contains(t, output, `row 2: invalid email "not-an-email"`)
+	_, appErr = th.App.GetTeamByName(invalidName)
+	require.NotNil(t, appErr, "no team should be created from an invalid file")
+}
+
+func TestRenameTeam(t *testing.T) {
+	th := api.Setup().InitBasic()
+	defer th.TearDown()
+
+	team := th.CreateTeam(th.BasicClient)
+	oldName := team.Name
+	newName := "renamed" + model.NewId()
+
+	output := cmd.CheckCommand(t, "team", "rename", oldName, "--new_name", newName, "--display_name", "Renamed Team")
+	require.Contains(t, output, "Renamed team '"+oldName+"' ("+team.DisplayName+") to '"+newName+"' (Renamed Team)")
+
+	renamed, err := th.App.GetTeam(team.Id)
+	require.Nil(t, err)
+	require.Equal(t, newName, renamed.Name)
+	require.Equal(t, "Renamed Team", renamed.DisplayName)
+
+	_, err = th.App.GetTeamByName(oldName)
+	require.NotNil(t, err)
+
+	otherName := "renamed" + model.NewId()
+	cmd.CheckCommand(t, "team", "rename", renamed.Id, "--new_name", otherName)
+	renamed, err = th.App.GetTeam(team.Id)
+	require.Nil(t, err)
+	require.Equal(t, otherName, renamed.Name)
+	require.Equal(t, "Renamed Team", renamed.DisplayName, "the display name should be kept when not given")
+
+	require.Error(t, cmd.RunCommand(t, "team", "rename", otherName, "--new_name", th.BasicTeam.Name))
+	require.Error(t, cmd.RunCommand(t, "team", "rename", otherName, "--new_name", strings.ToUpper(th.BasicTeam.Name)))
+	require.Error(t, cmd.RunCommand(t, "team", "rename", otherName, "--new_name", "not a slug"))
+	require.Error(t, cmd.RunCommand(t, "team", "rename", otherName))
+	require.Error(t, cmd.RunCommand(t, "team", "rename", "missing"+model.NewId(), "--new_name", "renamed"+model.NewId()))
+}
+
+func TestArchiveTeam(t *testing.T) {
+	th := api.Setup().InitBasic()
+	defer th.TearDown()
+
+	team := th.CreateTeam(th.BasicClient)
+
+	output := cmd.CheckCommand(t, "team", "archive", team.Name)
+	require.Contains(t, output, "Archived team '"+team.Name+"'.")
+
+	archived, err := th.App.GetTeam(team.Id)
+	require.Nil(t, err)
+	require.NotEqual(t, int64(0), archived.DeleteAt)
+
+	output = cmd.CheckCommand(t, "team", "archive", team.Id)
+	require.Contains(t, output, "Team '"+team.Name+"' is already archived.")
+
+	cmd.CheckCommand(t, "team", "restore", team.Name)
+	restored, err := th.App.GetTeam(team.Id)
+	require.Nil(t, err)
+	require.Equal(t, int64(0), restored.DeleteAt)
+
+	require.Error(t, cmd.RunCommand(t, "team", "archive", "nonexistent"+model.NewId()))
+	require.Error(t, cmd.RunCommand(t, "team", "archive"))
+}
+
+func TestRestoreTeam(t *testing.T) {
+	th := api.Setup().InitBasic()
+	defer th.TearDown()
+
+	team := th.CreateTeam(th.BasicClient)
+	require.Nil(t, th.App.SoftDeleteTeam(team.Id))
+
+	output := cmd.CheckCommand(t, "team", "restore", team.Name)
+	require.Contains(t, output, "Restored team '"+team.Name+"'.")
+
+	restored, err := th.App.GetTeam(team.Id)
+	require.Nil(t, err)
+	require.Equal(t, int64(0), restored.DeleteAt)
+
+	output = cmd.CheckCommand(t, "team", "restore", team.Id)
+	require.Contains(t, output, "Team '"+team.Name+"' is already active.")
+
+	require.Nil(t, th.App.PermanentDeleteTeam(restored))
+	require.Error(t, cmd.RunCommand(t, "team", "restore", team.Name))
+	require.Error(t, cmd.RunCommand(t, "team", "restore"))
 }
 
 func TestJoinTeam(t *testing.T) {
@@ -51,6 +248,63 @@ func TestJoinTeam(t *testing.T) {
 	}
 }
 
+func TestJoinTeamFromFile(t *testing.T) {
+	th := api.Setup().InitBasic()
+	defer th.TearDown()
+
+	team := th.CreateTeam(th.BasicClient)
+	missing := "missing" + model.NewId()
+
+	dir, err := ioutil.TempDir("", "users-file")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "users.txt")
+	contents := "# new hires\n" + th.BasicUser.Email + "\n\n" + missing + "\n" + th.BasicUser2.Username + "\n"
+	require.NoError(t, ioutil.WriteFile(path, []byte(contents), 0600))
+
+	output := cmd.CheckCommandFails(t, "team", "add", team.Name, "--users-file", path)
+	require.Contains(t, output, "Added 1 users from "+path+" to team '"+team.Name+"', 1 skipped, 1 failed.")
+	require.NotContains(t, output, "already a member")
+	require.Contains(t, output, "Line 4: Can't find user '"+missing+"'")
+
+	for _, user := range []*model.User{th.BasicUser, th.BasicUser2} {
+		_, err := th.App.GetTeamMember(team.Id, user.Id)
+		require.Nil(t, err, user.Username)
+	}
+
+	require.Error(t, cmd.RunCommand(t, "team", "add", team.Name))
+	require.Error(t, cmd.RunCommand(t, "team", "add", team.Name, "--users-file", filepath.Join(dir, "missing.txt")))
+}
+
+func TestJoinTeamExistingMember(t *testing.T) {
+	th := api.Setup().InitBasic()
+	defer th.TearDown()
+
+	output := cmd.CheckCommand(t, "team", "add", th.BasicTeam.Name, th.BasicUser.Username)
+	require.Contains(t, output, "'"+th.BasicUser.Username+"' is already a member of "+th.BasicTeam.Name+", skipping")
+
+	require.Nil(t, th.App.LeaveTeam(th.BasicTeam, th.BasicUser2, ""))
+	output = cmd.CheckCommand(t, "team", "add", th.BasicTeam.Name, th.BasicUser2.Username, "--fail-on-existing")
+	require.NotContains(t, output, "already a member")
+	require.Error(t, cmd.RunCommand(t, "team", "add", th.BasicTeam.Name, th.BasicUser2.Username, "--fail-on-existing"))
+
+	member, err := th.App.GetTeamMember(th.BasicTeam.Id, th.BasicUser2.Id)
+	require.Nil(t, err)
+	require.Equal(t, int64(0), member.DeleteAt)
+
+	dir, ioErr := ioutil.TempDir("", "users-file")
+	require.NoError(t, ioErr)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "users.txt")
+	require.NoError(t, ioutil.WriteFile(path, []byte(th.BasicUser.Email+"\n"), 0600))
+
+	output = cmd.CheckCommandFails(t, "team", "add", th.BasicTeam.Name, "--users-file", path, "--fail-on-existing")
+	require.Contains(t, output, "Added 0 users from "+path+" to team '"+th.BasicTeam.Name+"', 0 skipped, 1 failed.")
+	require.Contains(t, output, "Line 1: '"+th.BasicUser.Email+"' is already a member of "+th.BasicTeam.Name)
+}
+
 func TestLeaveTeam(t *testing.T) {
 	th := api.Setup().InitBasic()
 	defer th.TearDown()
@@ -95,4 +349,1032 @@ func TestListTeams(t *testing.T) {
 	if !strings.Contains(string(output), name) {
 		t.Fatal("should have the created team")
 	}
+
+	invite, err := th.App.CreateTeam(&model.Team{
+		Name:        "invite" + model.NewId(),
+		DisplayName: "Invite Only",
+		Email:       th.GenerateTestEmail(),
+		Type:        model.TEAM_INVITE,
+	})
+	require.Nil(t, err)
+	archived := th.CreateTeam(th.BasicClient)
+	require.Nil(t, th.App.SoftDeleteTeam(archived.Id))
+
+	output = cmd.CheckCommand(t, "team", "list")
+	require.Regexp(t, `(?m)^`+regexp.QuoteMeta(th.BasicTeam.Name)+`$`, output)
+	require.NotContains(t, output, archived.Name)
+
+	output = cmd.CheckCommand(t, "team", "list", "--include-deleted")
+	require.Regexp(t, `(?m)^`+regexp.QuoteMeta(archived.Name)+` \(archived\)$`, output)
+	require.Regexp(t, `(?m)^`+regexp.QuoteMeta(th.BasicTeam.Name)+`$`, output)
+
+	output = cmd.CheckCommand(t, "team", "list", "--type", "invite")
+	require.Regexp(t, `(?m)^`+regexp.QuoteMeta(invite.Name)+`$`, output)
+	require.NotContains(t, output, th.BasicTeam.Name)
+
+	output = cmd.CheckCommand(t, "team", "list", "--type", "invite", "--include-deleted", "--json")
+	require.Contains(t, output, `{"id":"`+invite.Id+`",`)
+	require.Contains(t, output, `"display_name":"Invite Only","name":"`+invite.Name+`"`)
+	require.NotContains(t, output, th.BasicTeam.Id)
+	jsonLine := regexp.MustCompile(`(?m)^\[.*\]$`).FindString(output)
+	teams := model.TeamListFromJson(strings.NewReader(jsonLine))
+	require.NotEmpty(t, teams)
+	for _, team := range teams {
+		require.Equal(t, model.TEAM_INVITE, team.Type)
+	}
+
+	th.CreateTeam(th.BasicClient)
+	teamLine := regexp.MustCompile(`(?m)^[a-z0-9][a-z0-9_-]*$`)
+	page0 := teamLine.FindAllString(cmd.CheckCommand(t, "team", "list", "--page", "0", "--per_page", "2"), -1)
+	page1 := teamLine.FindAllString(cmd.CheckCommand(t, "team", "list", "--page", "1", "--per_page", "2"), -1)
+	require.Len(t, page0, 2)
+	require.Len(t, page1, 2)
+	for _, name := range page0 {
+		require.NotContains(t, page1, name, "pages shouldn't overlap")
+	}
+
+	output = cmd.CheckCommand(t, "team", "list", "--show-source", "--include-deleted")
+	require.Regexp(t, `(?m)^`+regexp.QuoteMeta(name)+` cli$`, output)
+	require.Regexp(t, `(?m)^`+regexp.QuoteMeta(invite.Name)+` unknown$`, output)
+	require.Regexp(t, `(?m)^`+regexp.QuoteMeta(archived.Name)+` \S+ \(archived\)$`, output)
+
+	require.Error(t, cmd.RunCommand(t, "team", "list", "--type", "secret"))
+	require.Error(t, cmd.RunCommand(t, "team", "list", "--per_page", "-1"))
+}
+
+func TestListTeamsFormat(t *testing.T) {
+	th := api.Setup().InitBasic()
+	defer th.TearDown()
+
+	output := cmd.CheckCommand(t, "team", "list", "--format", `{{.Name}}\t{{.DisplayName}}|{{.Type}}`)
+	require.Contains(t, output, th.BasicTeam.Name+"\t"+th.BasicTeam.DisplayName+"|"+th.BasicTeam.Type+"\n")
+
+	output = cmd.CheckCommand(t, "team", "list", "--format", "{{.Id}} {{.GetCreationSource}}")
+	require.Contains(t, output, th.BasicTeam.Id+" "+th.BasicTeam.GetCreationSource())
+
+	require.Error(t, cmd.RunCommand(t, "team", "list", "--format", "{{.Name"))
+	require.Error(t, cmd.RunCommand(t, "team", "list", "--format", "{{.NoSuchField}}"))
+	require.Error(t, cmd.RunCommand(t, "team", "list", "--format", "{{.Name}}", "--json"))
+}
+
+func TestListTeamsWithMembers(t *testing.T) {
+	th := api.Setup().InitBasic()
+	defer th.TearDown()
+
+	id := model.NewId()
+	empty, err := th.App.CreateTeam(&model.Team{
+		Name:        "name" + id,
+		DisplayName: "Empty " + id,
+		Email:       th.GenerateTestEmail(),
+		Type:        model.TEAM_OPEN,
+	})
+	require.Nil(t, err)
+
+	stats, err := th.App.GetTeamStats(th.BasicTeam.Id)
+	require.Nil(t, err)
+	require.NotEqual(t, int64(0), stats.ActiveMemberCount)
+
+	output := cmd.CheckCommand(t, "team", "list", "--with-members")
+	require.Contains(t, output, fmt.Sprintf("%v (%v members)", th.BasicTeam.Name, stats.ActiveMemberCount))
+	require.Contains(t, output, empty.Name+" (0 members)")
+
+	require.Error(t, cmd.RunCommand(t, "team", "list", "--with-members", "--json"))
+}
+
+func TestCheckWhitespaceTeams(t *testing.T) {
+	th := api.Setup().InitBasic()
+	defer th.TearDown()
+
+	id := model.NewId()
+	team, err := th.App.CreateTeam(&model.Team{
+		Name:        "name" + id,
+		DisplayName: "  Stray   Whitespace " + id + " ",
+		Email:       th.GenerateTestEmail(),
+		Type:        model.TEAM_OPEN,
+	})
+	require.Nil(t, err)
+
+	output := cmd.CheckCommand(t, "team", "check-whitespace")
+	require.Contains(t, output, team.Name)
+	require.NotContains(t, output, th.BasicTeam.Name)
+
+	cmd.CheckCommand(t, "team", "check-whitespace", "--fix", "--confirm")
+
+	fixed, err := th.App.GetTeam(team.Id)
+	require.Nil(t, err)
+	require.Equal(t, "Stray Whitespace "+id, fixed.DisplayName)
+
+	output = cmd.CheckCommand(t, "team", "check-whitespace")
+	require.NotContains(t, output, team.Name)
+}
+
+func TestTeamProps(t *testing.T) {
+	th := api.Setup().InitBasic()
+	defer th.TearDown()
+
+	team := th.BasicTeam
+
+	cmd.CheckCommand(t, "team", "set-prop", team.Name, "cost_center", "1234")
+	require.Equal(t, "1234", cmd.CheckCommand(t, "team", "get-prop", team.Name, "cost_center"))
+
+	cmd.CheckCommand(t, "team", "set-prop", team.Name, "cost_center", "5678")
+	require.Equal(t, "5678", cmd.CheckCommand(t, "team", "get-prop", team.Name, "cost_center"))
+
+	cmd.CheckCommand(t, "team", "set-prop", team.Name, "region", "emea")
+	require.Equal(t, "emea", cmd.CheckCommand(t, "team", "get-prop", team.Id, "region"))
+
+	cmd.CheckCommand(t, "team", "set-prop", team.Name, "cost_center", "--delete")
+	require.Error(t, cmd.RunCommand(t, "team", "get-prop", team.Name, "cost_center"))
+	require.Equal(t, "emea", cmd.CheckCommand(t, "team", "get-prop", team.Name, "region"))
+
+	cmd.CheckCommand(t, "team", "set-prop", team.Name, "region=apac", `owner="Jane Doe"`, "query=a=b")
+	require.Equal(t, "apac", cmd.CheckCommand(t, "team", "get-prop", team.Name, "region"))
+	require.Equal(t, "Jane Doe", cmd.CheckCommand(t, "team", "get-prop", team.Name, "owner"))
+	require.Equal(t, "a=b", cmd.CheckCommand(t, "team", "get-prop", team.Name, "query"))
+	require.Error(t, cmd.RunCommand(t, "team", "set-prop", team.Name, "region=emea", "malformed"))
+	require.Error(t, cmd.RunCommand(t, "team", "set-prop", team.Name, "region=emea", "bad key!=x", "tier=gold"))
+	require.Equal(t, "apac", cmd.CheckCommand(t, "team", "get-prop", team.Name, "region"))
+	require.Error(t, cmd.RunCommand(t, "team", "get-prop", team.Name, "tier"))
+
+	require.Error(t, cmd.RunCommand(t, "team", "set-prop", team.Name, "bad key!", "value"))
+	require.Error(t, cmd.RunCommand(t, "team", "set-prop", "doesnotexist", "key", "value"))
+}
+
+func TestListTeamTokens(t *testing.T) {
+	th := api.Setup().InitBasic()
+	defer th.TearDown()
+
+	team2 := th.CreateTeam(th.BasicClient)
+	th.LinkUserToTeam(th.BasicUser2, team2)
+
+	for _, userId := range []string{th.BasicUser.Id, th.BasicUser.Id, th.BasicUser2.Id} {
+		store.Must(th.App.Srv.Store.UserAccessToken().Save(&model.UserAccessToken{
+			Token:       model.NewId(),
+			UserId:      userId,
+			Description: "test token",
+		}))
+	}
+
+	output := cmd.CheckCommand(t, "team", "list-tokens", "--threshold", "2")
+	require.Regexp(t, `(?m)^TEAM +ACTIVE TOKENS +ABOVE THRESHOLD$`, output)
+	require.Regexp(t, `(?m)^`+regexp.QuoteMeta(th.BasicTeam.Name)+` +3 +yes$`, output)
+	require.Regexp(t, `(?m)^`+regexp.QuoteMeta(team2.Name)+` +1$`, output)
+
+	output = cmd.CheckCommand(t, "team", "list-tokens", "--no-headers")
+	require.NotContains(t, output, "ACTIVE TOKENS")
+	require.Regexp(t, `(?m)^`+regexp.QuoteMeta(th.BasicTeam.Name)+` +3$`, output)
+
+	output = cmd.CheckCommand(t, "team", "list-tokens", "--json")
+	require.Contains(t, output, `{"team_id":"`+th.BasicTeam.Id+`","team_name":"`+th.BasicTeam.Name+`","active_tokens":3,"above_threshold":false}`)
+	require.Contains(t, output, `{"items":[`)
+	require.Contains(t, output, `],"offset":0,"limit":`)
+}
+
+func TestSyncTeamMembers(t *testing.T) {
+	th := api.Setup().InitBasic()
+	defer th.TearDown()
+
+	team := th.BasicTeam
+	user3 := th.CreateUser(th.BasicClient)
+	missing := "nobody" + model.NewId()
+
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	roster := filepath.Join(dir, "roster.csv")
+	require.NoError(t, ioutil.WriteFile(roster, []byte("email,name\n"+th.BasicUser.Email+",Basic\n# a comment\n\n"+user3.Username+",Third\n"), 0600))
+	badRoster := filepath.Join(dir, "bad-roster.csv")
+	require.NoError(t, ioutil.WriteFile(badRoster, []byte("email,name\n"+th.BasicUser.Email+",Basic\n"+missing+",Missing\n"), 0600))
+
+	isMember := func(userId string) bool {
+		member, err := th.App.GetTeamMember(team.Id, userId)
+		return err == nil && member.DeleteAt == 0
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		output := cmd.CheckCommand(t, "team", "sync-members", team.Name, "--file", roster, "--dry-run")
+		require.Contains(t, output, "Would add '"+user3.Username+"'")
+		require.Contains(t, output, "Would remove '"+th.BasicUser2.Username+"'")
+		require.Contains(t, output, "1 members would be added and 1 removed")
+
+		require.False(t, isMember(user3.Id))
+		require.True(t, isMember(th.BasicUser2.Id))
+	})
+
+	t.Run("dry run results", func(t *testing.T) {
+		output := cmd.CheckCommand(t, "team", "sync-members", team.Name, "--file", roster, "--dry-run", "--format", "json")
+		require.Contains(t, output, `{"line":5,"identifier":"`+user3.Username+`","status":"would add"}`)
+		require.Contains(t, output, `{"identifier":"`+th.BasicUser2.Username+`","status":"would remove"}`)
+
+		output = cmd.CheckCommand(t, "team", "sync-members", team.Name, "--file", roster, "--dry-run", "--format", "table")
+		require.Contains(t, output, "LINE")
+		require.Contains(t, output, "would add")
+
+		require.False(t, isMember(user3.Id))
+	})
+
+	t.Run("unresolved rows skip removals", func(t *testing.T) {
+		output := cmd.CheckCommandFails(t, "team", "sync-members", team.Name, "--file", badRoster, "--dry-run", "--format", "json")
+		require.Contains(t, output, `"line":3,`)
+		require.Contains(t, output, `"status":"error","error":"user not found"}`)
+		require.NotContains(t, output, "would remove")
+
+		output = cmd.CheckCommandFails(t, "team", "sync-members", team.Name, "--file", badRoster, "--confirm")
+		require.Contains(t, output, "Warning: 1 rows of "+badRoster+" couldn't be resolved ("+missing+"), so no members will be removed.")
+		require.Contains(t, output, "0 members added and 0 removed")
+
+		require.True(t, isMember(th.BasicUser2.Id))
+	})
+
+	t.Run("add only", func(t *testing.T) {
+		output := cmd.CheckCommand(t, "team", "sync-members", team.Name, "--file", roster, "--no-remove")
+		require.Contains(t, output, "1 members added and 0 removed")
+
+		require.True(t, isMember(user3.Id))
+		require.True(t, isMember(th.BasicUser.Id))
+		require.True(t, isMember(th.BasicUser2.Id))
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		require.Error(t, cmd.RunCommand(t, "team", "sync-members", team.Name, "--file", roster))
+		require.True(t, isMember(th.BasicUser2.Id))
+
+		output := cmd.CheckCommand(t, "team", "sync-members", team.Name, "--file", roster, "--confirm")
+		require.Contains(t, output, "0 members added and 1 removed")
+
+		require.True(t, isMember(user3.Id))
+		require.True(t, isMember(th.BasicUser.Id))
+		require.False(t, isMember(th.BasicUser2.Id))
+	})
+
+	require.Error(t, cmd.RunCommand(t, "team", "sync-members", team.Name))
+	require.Error(t, cmd.RunCommand(t, "team", "sync-members", team.Name, "--file", roster, "--format", "xml"))
+	require.Error(t, cmd.RunCommand(t, "team", "sync-members", team.Name, "--file", filepath.Join(dir, "missing.csv")))
+}
+
+func TestDefaultChannelsTeam(t *testing.T) {
+	th := api.Setup().InitBasic()
+	defer th.TearDown()
+
+	team2 := th.CreateTeam(th.BasicClient)
+
+	offTopic := store.Must(th.App.Srv.Store.Channel().GetByName(team2.Id, model.OFF_TOPIC_CHANNEL, true)).(*model.Channel)
+	offTopic.Type = model.CHANNEL_PRIVATE
+	store.Must(th.App.Srv.Store.Channel().Update(offTopic))
+
+	output := cmd.CheckCommand(t, "team", "default-channels", team2.Name, th.BasicTeam.Name)
+	require.Regexp(t, `(?m)^TEAM +CHANNELS$`, output)
+	require.Regexp(t, `(?m)^`+regexp.QuoteMeta(th.BasicTeam.Name)+` +town-square, off-topic$`, output)
+	require.Regexp(t, `(?m)^`+regexp.QuoteMeta(team2.Name)+` +town-square$`, output)
+
+	output = cmd.CheckCommand(t, "team", "default-channels", "--all", "--format", "json")
+	require.Contains(t, output, `{"team_id":"`+th.BasicTeam.Id+`","team_name":"`+th.BasicTeam.Name+`","channels":["town-square","off-topic"]}`)
+	require.Contains(t, output, `{"items":[`)
+
+	require.Error(t, cmd.RunCommand(t, "team", "default-channels"))
+	require.Error(t, cmd.RunCommand(t, "team", "default-channels", th.BasicTeam.Name, "--format", "xml"))
+}
+
+func TestCheckInviteFormatTeams(t *testing.T) {
+	th := api.Setup().InitBasic()
+	defer th.TearDown()
+
+	id := model.NewId()
+	team, err := th.App.CreateTeam(&model.Team{
+		Name:        "name" + id,
+		DisplayName: "Legacy Invite " + id,
+		Email:       th.GenerateTestEmail(),
+		Type:        model.TEAM_OPEN,
+		InviteId:    "legacy-" + id[:8],
+	})
+	require.Nil(t, err)
+
+	output := cmd.CheckCommand(t, "team", "check-invite-format")
+	require.Contains(t, output, team.Name)
+	require.NotContains(t, output, th.BasicTeam.Name)
+
+	cmd.CheckCommand(t, "team", "check-invite-format", "--fix", "--confirm")
+
+	fixed, err := th.App.GetTeam(team.Id)
+	require.Nil(t, err)
+	require.True(t, model.IsValidInviteId(fixed.InviteId))
+
+	output = cmd.CheckCommand(t, "team", "check-invite-format")
+	require.NotContains(t, output, team.Name)
+}
+
+func TestInviteHealthTeams(t *testing.T) {
+	th := api.Setup().InitBasic()
+	defer th.TearDown()
+
+	id := model.NewId()
+	team, err := th.App.CreateTeam(&model.Team{
+		Name:        "name" + id,
+		DisplayName: "Legacy Invite " + id,
+		Email:       th.GenerateTestEmail(),
+		Type:        model.TEAM_OPEN,
+		InviteId:    "legacy-" + id[:8],
+	})
+	require.Nil(t, err)
+
+	configPath := writeTempConfig(t, false)
+	defer os.RemoveAll(filepath.Dir(configPath))
+
+	config, _, _, appErr := utils.LoadConfig(configPath)
+	require.Nil(t, appErr)
+	config.ServiceSettings.SiteURL = model.NewString("http://localhost:8065")
+	require.NoError(t, ioutil.WriteFile(configPath, []byte(config.ToJson()), 0600))
+
+	output := cmd.CheckCommand(t, "--config", configPath, "team", "invite-health")
+	require.Contains(t, output, team.Name+": malformed invite id")
+	require.NotContains(t, output, th.BasicTeam.Name+":")
+
+	output = cmd.CheckCommand(t, "--config", configPath, "team", "invite-health", "--json")
+	require.Contains(t, output, `{"team_id":"`+team.Id+`","team_name":"`+team.Name+`","invite_link":"http://localhost:8065/signup_user_complete/?id=legacy-`+id[:8]+`","reasons":["malformed invite id"]}`)
+
+	config.ServiceSettings.SiteURL = model.NewString("localhost:8065")
+	require.NoError(t, ioutil.WriteFile(configPath, []byte(config.ToJson()), 0600))
+
+	output = cmd.CheckCommand(t, "--config", configPath, "team", "invite-health")
+	require.Contains(t, output, team.Name+": invalid SiteURL, malformed invite id")
+	require.Contains(t, output, th.BasicTeam.Name+": invalid SiteURL")
+}
+
+func TestMemberHealthTeams(t *testing.T) {
+	th := api.Setup().InitBasic()
+	defer th.TearDown()
+
+	team2 := th.CreateTeam(th.BasicClient)
+	th.LinkUserToTeam(th.BasicUser2, team2)
+
+	user3 := th.CreateUser(th.BasicClient)
+	th.LinkUserToTeam(user3, th.BasicTeam)
+	th.LinkUserToTeam(user3, team2)
+	_, err := th.App.UpdateActive(user3, false)
+	require.Nil(t, err)
+
+	output := cmd.CheckCommand(t, "team", "member-health", th.BasicTeam.Name, team2.Name)
+	require.Regexp(t, `(?m)^TEAM +ACTIVE +DEACTIVATED +ACTIVE %$`, output)
+	require.Regexp(t, `(?m)^`+regexp.QuoteMeta(th.BasicTeam.Name)+` +2 +1 +66\.7%$`, output)
+	require.Regexp(t, `(?m)^`+regexp.QuoteMeta(team2.Name)+` +2 +1 +66\.7%$`, output)
+
+	user4 := th.CreateUser(th.BasicClient)
+	th.LinkUserToTeam(user4, team2)
+	_, err = th.App.UpdateActive(user4, false)
+	require.Nil(t, err)
+
+	output = cmd.CheckCommand(t, "team", "member-health", "--all", "--no-headers")
+	require.NotContains(t, output, "DEACTIVATED")
+	require.Regexp(t, `(?m)^`+regexp.QuoteMeta(team2.Name)+` +2 +2 +50\.0%$`, output)
+	require.True(t, strings.Index(output, team2.Name+" ") < strings.Index(output, th.BasicTeam.Name+" "), "teams should be sorted by active share")
+
+	output = cmd.CheckCommand(t, "team", "member-health", th.BasicTeam.Name, "--json")
+	require.Contains(t, output, `{"team_id":"`+th.BasicTeam.Id+`","team_name":"`+th.BasicTeam.Name+`","active_members":2,"deactivated_members":1,"active_percent":"66.7%"}`)
+
+	output = cmd.CheckCommand(t, "team", "member-health", team2.Name, th.BasicTeam.Name, "--sort", "-active_percent")
+	require.True(t, strings.Index(output, th.BasicTeam.Name+" ") < strings.Index(output, team2.Name+" "), "teams should be sorted by active share, highest first")
+
+	output = cmd.CheckCommand(t, "team", "member-health", th.BasicTeam.Name, team2.Name, "--sort", "deactivated")
+	require.True(t, strings.Index(output, th.BasicTeam.Name+" ") < strings.Index(output, team2.Name+" "), "teams should be sorted by deactivated members")
+
+	require.Error(t, cmd.RunCommand(t, "team", "member-health"))
+	require.Error(t, cmd.RunCommand(t, "team", "member-health", th.BasicTeam.Name, "--sort", "members"))
+}
+
+func TestTeamQuota(t *testing.T) {
+	th := api.Setup().InitBasic()
+	defer th.TearDown()
+
+	team := th.BasicTeam
+
+	info := store.Must(th.App.Srv.Store.FileInfo().Save(&model.FileInfo{
+		CreatorId: th.BasicUser.Id,
+		PostId:    th.BasicPost.Id,
+		Path:      "quota.txt",
+		Size:      1536,
+	})).(*model.FileInfo)
+	defer func() {
+		<-th.App.Srv.Store.FileInfo().PermanentDelete(info.Id)
+	}()
+
+	output := cmd.CheckCommand(t, "team", "show-quota", team.Name)
+	require.Contains(t, output, team.Name+": 1.5KiB used, no quota")
+
+	output = cmd.CheckCommand(t, "team", "set-quota", team.Name, "--max-storage", "1MiB")
+	require.NotContains(t, output, "Warning")
+
+	output = cmd.CheckCommand(t, "team", "show-quota", team.Name)
+	require.Contains(t, output, team.Name+": 1.5KiB used of 1MiB (0.1%)")
+
+	output = cmd.CheckCommand(t, "team", "set-quota", team.Name, "--max-storage", "1KB")
+	require.Contains(t, output, "Warning: team '"+team.Name+"' already uses 1.5KiB, which is over the new quota.")
+
+	output = cmd.CheckCommand(t, "team", "show-quota", team.Name)
+	require.Contains(t, output, team.Name+": 1.5KiB used of 1000B (153.6%) (over quota)")
+
+	cmd.CheckCommand(t, "team", "set-quota", team.Name, "--max-storage", "0")
+	output = cmd.CheckCommand(t, "team", "show-quota", team.Name)
+	require.Contains(t, output, team.Name+": 1.5KiB used, no quota")
+
+	require.Error(t, cmd.RunCommand(t, "team", "set-quota", team.Name))
+	require.Error(t, cmd.RunCommand(t, "team", "set-quota", team.Name, "--max-storage", "lots"))
+}
+
+func TestCheckPlaceholderTeams(t *testing.T) {
+	th := api.Setup().InitBasic()
+	defer th.TearDown()
+
+	createTeam := func(displayName string) *model.Team {
+		team, err := th.App.CreateTeam(&model.Team{
+			Name:        "name" + model.NewId(),
+			DisplayName: displayName,
+			Email:       th.GenerateTestEmail(),
+			Type:        model.TEAM_OPEN,
+		})
+		require.Nil(t, err)
+		return team
+	}
+
+	placeholder := createTeam("New Team 12")
+	custom := createTeam("New Team Building Committee")
+	provisioned := createTeam("Provisioned " + model.NewId()[:6])
+
+	output := cmd.CheckCommand(t, "team", "check-placeholder")
+	require.Contains(t, output, placeholder.Name+": \"New Team 12\"")
+	require.NotContains(t, output, custom.Name)
+	require.NotContains(t, output, provisioned.Name)
+	require.NotContains(t, output, th.BasicTeam.Name)
+
+	output = cmd.CheckCommand(t, "team", "check-placeholder", "--pattern", `provisioned \w+`, "--json")
+	require.Contains(t, output, `{"team_id":"`+provisioned.Id+`","team_name":"`+provisioned.Name+`","display_name":"`+provisioned.DisplayName+`"}`)
+	require.NotContains(t, output, placeholder.Name)
+
+	require.Error(t, cmd.RunCommand(t, "team", "check-placeholder", "--pattern", "(unclosed"))
+}
+
+func TestCloneTeam(t *testing.T) {
+	th := api.Setup().InitBasic()
+	defer th.TearDown()
+
+	source := th.BasicTeam
+	source.Description = "cloned description"
+	source.AllowedDomains = "example.com"
+	source, err := th.App.UpdateTeam(source)
+	require.Nil(t, err)
+
+	private := th.CreatePrivateChannel(th.BasicClient, source)
+	archived := th.CreateChannel(th.BasicClient, source)
+	require.Nil(t, th.App.DeleteChannel(archived, th.BasicUser.Id))
+
+	t.Run("structure only", func(t *testing.T) {
+		name := "clone" + model.NewId()
+		output := cmd.CheckCommand(t, "team", "clone", source.Name, "--name", name, "--display_name", "Cloned Team")
+		require.Contains(t, output, "Created team '"+name+"' from '"+source.Name+"'")
+		require.Contains(t, output, th.BasicChannel.Name)
+		require.Contains(t, output, private.Name)
+		require.NotContains(t, output, archived.Name)
+
+		clone, err := th.App.GetTeamByName(name)
+		require.Nil(t, err)
+		require.Equal(t, "Cloned Team", clone.DisplayName)
+		require.Equal(t, source.Type, clone.Type)
+		require.Equal(t, source.Description, clone.Description)
+		require.Equal(t, source.AllowedDomains, clone.AllowedDomains)
+
+		channel, err := th.App.GetChannelByName(private.Name, clone.Id)
+		require.Nil(t, err)
+		require.Equal(t, model.CHANNEL_PRIVATE, channel.Type)
+		_, err = th.App.GetChannelByName(model.DEFAULT_CHANNEL, clone.Id)
+		require.Nil(t, err)
+
+		_, err = th.App.GetTeamMember(clone.Id, th.BasicUser.Id)
+		require.NotNil(t, err)
+	})
+
+	t.Run("with members", func(t *testing.T) {
+		_, err := th.App.UpdateTeamMemberRoles(source.Id, th.BasicUser2.Id, model.TEAM_USER_ROLE_ID+" "+model.TEAM_ADMIN_ROLE_ID)
+		require.Nil(t, err)
+
+		name := "clone" + model.NewId()
+		output := cmd.CheckCommand(t, "team", "clone", source.Name, "--name", name, "--display_name", "Cloned Team", "--with-members")
+		require.Contains(t, output, "Copied 2 members.")
+
+		clone, err := th.App.GetTeamByName(name)
+		require.Nil(t, err)
+
+		_, err = th.App.GetTeamMember(clone.Id, th.BasicUser.Id)
+		require.Nil(t, err)
+		member, err := th.App.GetTeamMember(clone.Id, th.BasicUser2.Id)
+		require.Nil(t, err)
+		require.Contains(t, member.Roles, model.TEAM_ADMIN_ROLE_ID)
+	})
+
+	require.Error(t, cmd.RunCommand(t, "team", "clone", source.Name, "--name", source.Name, "--display_name", "Taken"))
+	output := cmd.CheckCommand(t, "team", "clone", source.Name, "--name", source.Name, "--display_name", "Taken", "--auto-suffix")
+	require.Contains(t, output, "Created team '"+source.Name+"-2' from '"+source.Name+"'")
+	require.Error(t, cmd.RunCommand(t, "team", "clone", source.Name, "--name", "Not A Slug", "--display_name", "Invalid"))
+	require.Error(t, cmd.RunCommand(t, "team", "clone", source.Name, "--display_name", "No Name"))
+	require.Error(t, cmd.RunCommand(t, "team", "clone", "missingteam"+model.NewId(), "--name", "clone"+model.NewId(), "--display_name", "Missing"))
+}
+
+func TestAdminActivityTeams(t *testing.T) {
+	th := api.Setup().InitBasic()
+	defer th.TearDown()
+
+	team := th.BasicTeam
+	for _, user := range []*model.User{th.BasicUser, th.BasicUser2} {
+		_, err := th.App.UpdateTeamMemberRoles(team.Id, user.Id, model.TEAM_USER_ROLE_ID+" "+model.TEAM_ADMIN_ROLE_ID)
+		require.Nil(t, err)
+	}
+
+	active := time.Now().Add(-24 * time.Hour)
+	dormant := time.Now().Add(-200 * 24 * time.Hour)
+	store.Must(th.App.Srv.Store.Status().SaveOrUpdate(&model.Status{UserId: th.BasicUser.Id, Status: model.STATUS_OFFLINE, LastActivityAt: utils.MillisFromTime(active)}))
+	store.Must(th.App.Srv.Store.Status().SaveOrUpdate(&model.Status{UserId: th.BasicUser2.Id, Status: model.STATUS_OFFLINE, LastActivityAt: utils.MillisFromTime(dormant)}))
+
+	output := cmd.CheckCommand(t, "team", "admin-activity", team.Name)
+	require.Regexp(t, `(?m)^TEAM +ADMIN +LAST ACTIVE +INACTIVE$`, output)
+	require.Regexp(t, `(?m)^`+regexp.QuoteMeta(team.Name)+` +`+regexp.QuoteMeta(th.BasicUser.Username)+` +`+active.UTC().Format("2006-01-02")+`\S*$`, output)
+	require.Regexp(t, `(?m)^`+regexp.QuoteMeta(team.Name)+` +`+regexp.QuoteMeta(th.BasicUser2.Username)+` +`+dormant.UTC().Format("2006-01-02")+`\S* +yes$`, output)
+
+	output = cmd.CheckCommand(t, "team", "admin-activity", team.Name, "--inactive", "12h", "--json")
+	require.Contains(t, output, `"user_id":"`+th.BasicUser.Id+`","username":"`+th.BasicUser.Username+`","last_activity_at":`+strconv.FormatInt(utils.MillisFromTime(active), 10)+`,"inactive":true}`)
+
+	output = cmd.CheckCommand(t, "team", "admin-activity", team.Name, "--inactive", "1w")
+	require.Regexp(t, `(?m)^`+regexp.QuoteMeta(team.Name)+` +`+regexp.QuoteMeta(th.BasicUser.Username)+` +\S+$`, output)
+
+	require.Error(t, cmd.RunCommand(t, "team", "admin-activity", team.Name, "--inactive", "soon"))
+	require.Error(t, cmd.RunCommand(t, "team", "admin-activity", "--all", team.Name))
+	require.Error(t, cmd.RunCommand(t, "team", "admin-activity"))
+}
+
+func TestBulkArchiveTeams(t *testing.T) {
+	th := api.Setup().InitBasic()
+	defer th.TearDown()
+
+	team1 := th.CreateTeam(th.BasicClient)
+	team2 := th.CreateTeam(th.BasicClient)
+	archived := th.CreateTeam(th.BasicClient)
+	require.Nil(t, th.App.SoftDeleteTeam(archived.Id))
+	missing := "missing" + model.NewId()
+
+	dir, err := ioutil.TempDir("", "bulk-archive")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "teams.txt")
+	contents := "# decommissioned in Q3\n" + team1.Name + "\n\n" + team2.Id + "\n" + missing + "\n" + archived.Name + "\n"
+	require.NoError(t, ioutil.WriteFile(path, []byte(contents), 0600))
+
+	t.Run("dry run", func(t *testing.T) {
+		output := cmd.CheckCommand(t, "team", "bulk-archive", "--file", path, "--dry-run")
+		require.Contains(t, output, "Would archive '"+team1.Name+"'")
+		require.Contains(t, output, "Would archive '"+team2.Name+"'")
+		require.Contains(t, output, "Line 5: unable to find team '"+missing+"'")
+		require.Contains(t, output, "Would archive 2 teams, 1 already archived, 1 not found.")
+
+		team, err := th.App.GetTeam(team1.Id)
+		require.Nil(t, err)
+		require.Zero(t, team.DeleteAt)
+	})
+
+	t.Run("archive", func(t *testing.T) {
+		output := cmd.CheckCommand(t, "team", "bulk-archive", "--file", path, "--confirm")
+		require.Contains(t, output, "Archived '"+team1.Name+"'")
+		require.Contains(t, output, "Archived '"+team2.Name+"'")
+		require.Contains(t, output, "Archived 2 teams, 1 already archived, 1 not found, 0 failed.")
+
+		for _, id := range []string{team1.Id, team2.Id} {
+			team, err := th.App.GetTeam(id)
+			require.Nil(t, err)
+			require.NotZero(t, team.DeleteAt)
+		}
+
+		team, err := th.App.GetTeam(th.BasicTeam.Id)
+		require.Nil(t, err)
+		require.Zero(t, team.DeleteAt)
+	})
+
+	require.Error(t, cmd.RunCommand(t, "team", "bulk-archive"))
+	require.Error(t, cmd.RunCommand(t, "team", "bulk-archive", "--file", filepath.Join(dir, "missing.txt")))
+}
+
+func TestCheckArchivedChannelsTeams(t *testing.T) {
+	th := api.Setup().InitBasic()
+	defer th.TearDown()
+
+	now := model.GetMillis()
+	old := now - 1000*60*60*24*400
+	archive := func(team *model.Team, deleteAt int64) *model.Channel {
+		return store.Must(th.App.Srv.Store.Channel().Save(&model.Channel{
+			TeamId:      team.Id,
+			DisplayName: "Archived",
+			Name:        "archived-" + model.NewId(),
+			Type:        model.CHANNEL_OPEN,
+			DeleteAt:    deleteAt,
+		}, -1)).(*model.Channel)
+	}
+
+	most := th.CreateTeam(th.BasicClient)
+	oldChannel := archive(most, old)
+	for i := 0; i < 3; i++ {
+		archive(most, now)
+	}
+	above := th.CreateTeam(th.BasicClient)
+	for i := 0; i < 3; i++ {
+		archive(above, now)
+	}
+	below := th.CreateTeam(th.BasicClient)
+	belowOld := archive(below, old)
+
+	output := cmd.CheckCommand(t, "team", "check-archived-channels", "--max", "2")
+	require.Regexp(t, `(?m)^TEAM +ARCHIVED CHANNELS$`, output)
+	require.Regexp(t, `(?m)^`+regexp.QuoteMeta(most.Name)+` +4$`, output)
+	require.Regexp(t, `(?m)^`+regexp.QuoteMeta(above.Name)+` +3$`, output)
+	require.NotContains(t, output, below.Name)
+	require.True(t, strings.Index(output, most.Name) < strings.Index(output, above.Name), "teams should be sorted by archived channels, most first")
+
+	output = cmd.CheckCommand(t, "team", "check-archived-channels", "--max", "3", "--json")
+	require.Contains(t, output, `{"team_id":"`+most.Id+`","team_name":"`+most.Name+`","archived_channels":4}`)
+	require.NotContains(t, output, above.Name)
+
+	output = cmd.CheckCommand(t, "team", "check-archived-channels", "--max", "2", "--purge-older-than", "365d", "--confirm")
+	require.Contains(t, output, "Deleted 1 archived channels from team '"+most.Name+"'")
+	require.Contains(t, output, "Deleted 0 archived channels from team '"+above.Name+"'")
+
+	_, err := th.App.GetChannel(oldChannel.Id)
+	require.NotNil(t, err, "the old archived channel should be deleted")
+	_, err = th.App.GetChannel(belowOld.Id)
+	require.Nil(t, err, "teams under the threshold shouldn't be purged")
+	count, err := th.App.GetArchivedChannelCount(most.Id)
+	require.Nil(t, err)
+	require.Equal(t, int64(3), count)
+
+	require.Error(t, cmd.RunCommand(t, "team", "check-archived-channels", "--purge-older-than", "ages", "--confirm"))
+}
+
+func TestFixDefaultMembershipTeam(t *testing.T) {
+	th := api.Setup().InitBasic()
+	defer th.TearDown()
+
+	team := th.BasicTeam
+	townSquare, err := th.App.GetChannelByName(model.DEFAULT_CHANNEL, team.Id)
+	require.Nil(t, err)
+	offTopic, err := th.App.GetChannelByName(model.OFF_TOPIC_CHANNEL, team.Id)
+	require.Nil(t, err)
+
+	store.Must(th.App.Srv.Store.Channel().RemoveMember(townSquare.Id, th.BasicUser2.Id))
+	store.Must(th.App.Srv.Store.Channel().RemoveMember(offTopic.Id, th.BasicUser2.Id))
+	store.Must(th.App.Srv.Store.Channel().RemoveMember(offTopic.Id, th.BasicUser.Id))
+
+	output := cmd.CheckCommand(t, "team", "fix-default-membership", team.Name, "--dry-run")
+	require.Contains(t, output, "Would add '"+th.BasicUser2.Username+"' to "+model.DEFAULT_CHANNEL)
+	require.Contains(t, output, "Would add '"+th.BasicUser2.Username+"' to off-topic")
+	require.Contains(t, output, "Would add '"+th.BasicUser.Username+"' to off-topic")
+	require.NotContains(t, output, "Would add '"+th.BasicUser.Username+"' to "+model.DEFAULT_CHANNEL)
+	require.Contains(t, output, "Would add 3 missing memberships for 2 members of team '"+team.Name+"'.")
+
+	_, err = th.App.GetChannelMember(townSquare.Id, th.BasicUser2.Id)
+	require.NotNil(t, err, "a dry run shouldn't add any memberships")
+
+	output = cmd.CheckCommand(t, "team", "fix-default-membership", team.Name)
+	require.Contains(t, output, "Added 3 missing memberships for 2 members of team '"+team.Name+"', 0 failed.")
+
+	for _, channel := range []*model.Channel{townSquare, offTopic} {
+		for _, user := range []*model.User{th.BasicUser, th.BasicUser2} {
+			_, err := th.App.GetChannelMember(channel.Id, user.Id)
+			require.Nil(t, err)
+		}
+	}
+
+	output = cmd.CheckCommand(t, "team", "fix-default-membership", team.Name)
+	require.Contains(t, output, "Added 0 missing memberships for 0 members of team '"+team.Name+"', 0 failed.")
+
+	require.Error(t, cmd.RunCommand(t, "team", "fix-default-membership"))
+	require.Error(t, cmd.RunCommand(t, "team", "fix-default-membership", "missing"+model.NewId()))
+}
+
+func TestListWebhooksTeam(t *testing.T) {
+	th := api.Setup().InitBasic()
+	defer th.TearDown()
+
+	configPath := writeTempConfig(t, false)
+	defer os.RemoveAll(filepath.Dir(configPath))
+
+	config, _, _, appErr := utils.LoadConfig(configPath)
+	require.Nil(t, appErr)
+	config.ServiceSettings.SiteURL = model.NewString("http://localhost:8065")
+	config.ServiceSettings.EnableIncomingWebhooks = true
+	config.ServiceSettings.EnableOutgoingWebhooks = true
+	require.NoError(t, ioutil.WriteFile(configPath, []byte(config.ToJson()), 0600))
+
+	team := th.CreateTeam(th.BasicClient)
+	channel := th.CreateChannel(th.BasicClient, team)
+
+	valid := store.Must(th.App.Srv.Store.Webhook().SaveIncoming(&model.IncomingWebhook{
+		UserId:      th.BasicUser.Id,
+		ChannelId:   channel.Id,
+		TeamId:      team.Id,
+		DisplayName: "Valid",
+	})).(*model.IncomingWebhook)
+	broken := store.Must(th.App.Srv.Store.Webhook().SaveIncoming(&model.IncomingWebhook{
+		UserId:      th.BasicUser.Id,
+		ChannelId:   channel.Id,
+		TeamId:      team.Id,
+		DisplayName: "Broken",
+		IconURL:     "ftp://example.com/icon.png",
+	})).(*model.IncomingWebhook)
+	outgoing := store.Must(th.App.Srv.Store.Webhook().SaveOutgoing(&model.OutgoingWebhook{
+		CreatorId:    th.BasicUser.Id,
+		ChannelId:    channel.Id,
+		TeamId:       team.Id,
+		DisplayName:  "Outgoing",
+		TriggerWords: []string{"deploy"},
+		CallbackURLs: []string{"https://example.com/callback"},
+	})).(*model.OutgoingWebhook)
+
+	output := cmd.CheckCommand(t, "--config", configPath, "team", "list-webhooks", team.Name)
+	require.Regexp(t, `(?m)^TYPE +ID +NAME +URL +INVALID$`, output)
+	require.Regexp(t, `(?m)^incoming +`+valid.Id+` +Valid +http://localhost:8065/hooks/`+valid.Id+`$`, output)
+	require.Regexp(t, `(?m)^incoming +`+broken.Id+` +Broken +ftp://example\.com/icon\.png +yes$`, output)
+	require.Regexp(t, `(?m)^outgoing +`+outgoing.Id+` +Outgoing +https://example\.com/callback$`, output)
+	require.Contains(t, output, "1 of 3 webhooks have invalid URLs.")
+
+	output = cmd.CheckCommand(t, "--config", configPath, "team", "list-webhooks", team.Name, "--json")
+	require.Contains(t, output, `{"id":"`+broken.Id+`","type":"incoming","display_name":"Broken","channel_id":"`+channel.Id+`","urls":["http://localhost:8065/hooks/`+broken.Id+`","ftp://example.com/icon.png"],"invalid_urls":["ftp://example.com/icon.png"]}`)
+	require.Contains(t, output, `"urls":["https://example.com/callback"],"invalid_urls":[]}`)
+
+	config.ServiceSettings.EnableIncomingWebhooks = false
+	config.ServiceSettings.EnableOutgoingWebhooks = false
+	require.NoError(t, ioutil.WriteFile(configPath, []byte(config.ToJson()), 0600))
+
+	output = cmd.CheckCommand(t, "--config", configPath, "team", "list-webhooks", team.Name)
+	require.Contains(t, output, valid.Id)
+	require.Contains(t, output, outgoing.Id)
+
+	output = cmd.CheckCommand(t, "--config", configPath, "team", "list-webhooks", th.BasicTeam.Name)
+	require.Contains(t, output, "No webhooks found for team '"+th.BasicTeam.Name+"'.")
+
+	require.Error(t, cmd.RunCommand(t, "team", "list-webhooks"))
+}
+
+func TestCheckPropCyclesTeams(t *testing.T) {
+	th := api.Setup().InitBasic()
+	defer th.TearDown()
+
+	prop := "parent_team_" + model.NewId()[:8]
+
+	output := cmd.CheckCommand(t, "team", "check-prop-cycles", "--prop", prop)
+	require.Contains(t, output, "No missing or circular references found in "+prop+".")
+
+	a, b, c := th.CreateTeam(th.BasicClient), th.CreateTeam(th.BasicClient), th.CreateTeam(th.BasicClient)
+	child := th.CreateTeam(th.BasicClient)
+	self := th.CreateTeam(th.BasicClient)
+	orphan := th.CreateTeam(th.BasicClient)
+	missing := "missing" + model.NewId()
+
+	for team, parent := range map[*model.Team]string{
+		a:      b.Name,
+		b:      c.Id,
+		c:      a.Name,
+		child:  a.Name,
+		self:   self.Name,
+		orphan: missing,
+	} {
+		_, err := th.App.SetTeamProp(team.Id, prop, parent)
+		require.Nil(t, err)
+	}
+
+	output = cmd.CheckCommand(t, "team", "check-prop-cycles", "--prop", prop)
+	require.Contains(t, output, "Team '"+orphan.Name+"' references missing team '"+missing+"' in "+prop+".")
+	require.Contains(t, output, "Team '"+self.Name+"' references itself in "+prop+".")
+	require.Regexp(t, "Cycle in "+prop+": ("+
+		regexp.QuoteMeta(a.Name+" -> "+b.Name+" -> "+c.Name+" -> "+a.Name)+"|"+
+		regexp.QuoteMeta(b.Name+" -> "+c.Name+" -> "+a.Name+" -> "+b.Name)+"|"+
+		regexp.QuoteMeta(c.Name+" -> "+a.Name+" -> "+b.Name+" -> "+c.Name)+")", output)
+	require.Equal(t, 1, strings.Count(output, "Cycle in"), "each cycle should be reported once")
+	require.NotContains(t, output, child.Name)
+
+	output = cmd.CheckCommand(t, "team", "check-prop-cycles", "--prop", prop, "--json")
+	require.Contains(t, output, `{"type":"missing","teams":["`+orphan.Name+`"],"value":"`+missing+`"}`)
+	require.Contains(t, output, `{"type":"cycle","teams":["`+self.Name+`"]}`)
+	require.Contains(t, output, `"total":3`)
+
+	require.Error(t, cmd.RunCommand(t, "team", "check-prop-cycles"))
+}
+
+func TestInviteQRTeam(t *testing.T) {
+	th := api.Setup().InitBasic()
+	defer th.TearDown()
+
+	configPath := writeTempConfig(t, false)
+	defer os.RemoveAll(filepath.Dir(configPath))
+
+	config, _, _, appErr := utils.LoadConfig(configPath)
+	require.Nil(t, appErr)
+	config.ServiceSettings.SiteURL = model.NewString("http://localhost:8065")
+	require.NoError(t, ioutil.WriteFile(configPath, []byte(config.ToJson()), 0600))
+
+	output := filepath.Join(filepath.Dir(configPath), "invite.png")
+	result := cmd.CheckCommand(t, "--config", configPath, "team", "invite-qr", th.BasicTeam.Name, "--output", output, "--size", "300")
+	require.Contains(t, result, "QR code of http://localhost:8065/signup_user_complete/?id="+th.BasicTeam.InviteId+" to "+output)
+
+	file, err := os.Open(output)
+	require.NoError(t, err)
+	defer file.Close()
+
+	img, err := png.Decode(file)
+	require.NoError(t, err)
+	require.True(t, img.Bounds().Dx() > 0 && img.Bounds().Dx() <= 300)
+	require.Equal(t, img.Bounds().Dx(), img.Bounds().Dy())
+
+	require.Error(t, cmd.RunCommand(t, "--config", configPath, "team", "invite-qr", th.BasicTeam.Name))
+	require.Error(t, cmd.RunCommand(t, "--config", configPath, "team", "invite-qr", th.BasicTeam.Name, "--output", output, "--size", "10"))
+	require.Error(t, cmd.RunCommand(t, "--config", configPath, "team", "invite-qr", "missing"+model.NewId(), "--output", output))
+
+	config.ServiceSettings.SiteURL = model.NewString("")
+	require.NoError(t, ioutil.WriteFile(configPath, []byte(config.ToJson()), 0600))
+	require.Error(t, cmd.RunCommand(t, "--config", configPath, "team", "invite-qr", th.BasicTeam.Name, "--output", output))
+}
+
+func TestCheckChannelsTeams(t *testing.T) {
+	th := api.Setup().InitBasic()
+	defer th.TearDown()
+
+	id := model.NewId()
+	result := <-th.App.Srv.Store.Team().Save(&model.Team{
+		Name:        "name" + id,
+		DisplayName: "No Channels " + id,
+		Email:       th.GenerateTestEmail(),
+		Type:        model.TEAM_OPEN,
+	})
+	require.Nil(t, result.Err)
+	team := result.Data.(*model.Team)
+
+	output := cmd.CheckCommand(t, "team", "check-channels")
+	require.Contains(t, output, team.Name)
+	require.NotContains(t, output, th.BasicTeam.Name)
+
+	cmd.CheckCommand(t, "team", "check-channels", "--fix", "--confirm")
+
+	channels, err := th.App.GetDefaultChannels(team.Id)
+	require.Nil(t, err)
+	require.NotEmpty(t, channels)
+
+	output = cmd.CheckCommand(t, "team", "check-channels")
+	require.NotContains(t, output, team.Name)
+}
+
+func TestSearchTeams(t *testing.T) {
+	th := api.Setup().InitBasic()
+	defer th.TearDown()
+
+	id := model.NewId()
+	team, err := th.App.CreateTeam(&model.Team{
+		Name:        "searchable" + id,
+		DisplayName: "Quarterly Planning " + id,
+		Email:       th.GenerateTestEmail(),
+		Type:        model.TEAM_INVITE,
+	})
+	require.Nil(t, err)
+
+	output := cmd.CheckCommand(t, "team", "search", strings.ToUpper(id[:12]))
+	require.Contains(t, output, team.Name)
+	require.Contains(t, output, team.DisplayName)
+	require.Contains(t, output, model.TEAM_INVITE)
+	require.NotContains(t, output, th.BasicTeam.Name)
+
+	output = cmd.CheckCommand(t, "team", "search", "planning "+id[:6])
+	require.Contains(t, output, team.Name)
+
+	output = cmd.CheckCommand(t, "team", "search", "nomatch"+model.NewId())
+	require.Contains(t, output, "No teams found")
+
+	require.Error(t, cmd.RunCommand(t, "team", "search"))
+}
+
+func TestModifyTeam(t *testing.T) {
+	th := api.Setup().InitBasic()
+	defer th.TearDown()
+
+	team := th.BasicTeam
+	team.AllowOpenInvite = true
+	_, err := th.App.UpdateTeam(team)
+	require.Nil(t, err)
+
+	output := cmd.CheckCommand(t, "team", "modify", team.Name, "--private")
+	require.Contains(t, output, "Team '"+team.Name+"' is now private.")
+
+	updated, err := th.App.GetTeam(team.Id)
+	require.Nil(t, err)
+	require.Equal(t, model.TEAM_INVITE, updated.Type)
+	require.False(t, updated.AllowOpenInvite)
+
+	output = cmd.CheckCommand(t, "team", "modify", team.Name, "--private")
+	require.Contains(t, output, "Team '"+team.Name+"' is already private.")
+
+	output = cmd.CheckCommand(t, "team", "modify", team.Name, "--public")
+	require.Contains(t, output, "Team '"+team.Name+"' is now public.")
+
+	updated, err = th.App.GetTeam(team.Id)
+	require.Nil(t, err)
+	require.Equal(t, model.TEAM_OPEN, updated.Type)
+
+	require.Error(t, cmd.RunCommand(t, "team", "modify", team.Name, "--private", "--public"))
+	require.Error(t, cmd.RunCommand(t, "team", "modify", team.Name))
+	require.Error(t, cmd.RunCommand(t, "team", "modify", "nonexistent"+model.NewId(), "--private"))
+}
+
+func TestMembersTeam(t *testing.T) {
+	th := api.Setup().InitBasic()
+	defer th.TearDown()
+
+	_, err := th.App.UpdateTeamMemberRoles(th.BasicTeam.Id, th.BasicUser.Id, model.TEAM_USER_ROLE_ID+" "+model.TEAM_ADMIN_ROLE_ID)
+	require.Nil(t, err)
+
+	output := cmd.CheckCommand(t, "team", "members", th.BasicTeam.Name)
+	require.Regexp(t, `(?m)^`+regexp.QuoteMeta(th.BasicUser.Username)+` +`+regexp.QuoteMeta(th.BasicUser.Email)+` +team_user team_admin$`, output)
+	require.Regexp(t, `(?m)^`+regexp.QuoteMeta(th.BasicUser2.Username)+` +`+regexp.QuoteMeta(th.BasicUser2.Email)+` +team_user$`, output)
+
+	output = cmd.CheckCommand(t, "team", "members", th.BasicTeam.Name, "--json")
+	require.Contains(t, output, `{"user_id":"`+th.BasicUser.Id+`","username":"`+th.BasicUser.Username+`","email":"`+th.BasicUser.Email+`","roles":"team_user team_admin","deactivated":false}`)
+	require.Contains(t, output, `"offset":0,"limit":200,"total":2}`)
+
+	page0 := cmd.CheckCommand(t, "team", "members", th.BasicTeam.Name, "--per_page", "1", "--no-headers")
+	page1 := cmd.CheckCommand(t, "team", "members", th.BasicTeam.Name, "--per_page", "1", "--page", "1", "--no-headers")
+	require.NotEqual(t, page0, page1)
+	for _, user := range []*model.User{th.BasicUser, th.BasicUser2} {
+		require.True(t, strings.Contains(page0, user.Username) != strings.Contains(page1, user.Username))
+	}
+
+	output = cmd.CheckCommand(t, "team", "members", th.BasicTeam.Name, "--page", "5")
+	require.Contains(t, output, "No members found.")
+
+	require.Error(t, cmd.RunCommand(t, "team", "members", th.BasicTeam.Name, "--page", "-1"))
+	require.Error(t, cmd.RunCommand(t, "team", "members", "nonexistent"+model.NewId()))
+	require.Error(t, cmd.RunCommand(t, "team", "members"))
+}
+
+func TestEngagementTeam(t *testing.T) {
+	th := api.Setup().InitBasic()
+	defer th.TearDown()
+
+	newTeam := func(prefix string) *model.Team {
+		id := model.NewId()
+		team, err := th.App.CreateTeam(&model.Team{
+			Name:        prefix + id,
+			DisplayName: prefix + " " + id,
+			Email:       th.GenerateTestEmail(),
+			Type:        model.TEAM_OPEN,
+		})
+		require.Nil(t, err)
+		return team
+	}
+
+	// An empty team scores 0, a team whose only member never posted scores 30 from its active member ratio
+	// and the basic team, with recent posts, scores higher still.
+	empty := newTeam("empty")
+	quiet := newTeam("quiet")
+	result := <-th.App.Srv.Store.Team().SaveMember(&model.TeamMember{TeamId: quiet.Id, UserId: th.BasicUser.Id}, -1)
+	require.Nil(t, result.Err)
+	for i := 0; i < 5; i++ {
+		th.CreatePost(th.BasicClient, th.BasicChannel)
+	}
+
+	output := cmd.CheckCommand(t, "team", "engagement", quiet.Name)
+	require.Contains(t, output, "30.0")
+	require.Contains(t, output, "1 of 1 (100%)")
+	require.Contains(t, output, "never posted")
+
+	output = cmd.CheckCommand(t, "team", "engagement", "--all", "--no-headers")
+	emptyIndex := strings.Index(output, empty.Name)
+	quietIndex := strings.Index(output, quiet.Name)
+	basicIndex := strings.Index(output, th.BasicTeam.Name)
+	require.True(t, emptyIndex >= 0 && quietIndex >= 0 && basicIndex >= 0, output)
+	require.True(t, emptyIndex < quietIndex, output)
+	require.True(t, quietIndex < basicIndex, output)
+
+	output = cmd.CheckCommand(t, "team", "engagement", th.BasicTeam.Name, "--json")
+	require.Contains(t, output, `"team_name":"`+th.BasicTeam.Name+`"`)
+	require.Regexp(t, `"posts":([5-9]|\d\d+),`, output)
+
+	require.Error(t, cmd.RunCommand(t, "team", "engagement"))
+	require.Error(t, cmd.RunCommand(t, "team", "engagement", th.BasicTeam.Name, "--all"))
+	require.Error(t, cmd.RunCommand(t, "team", "engagement", "missing"+model.NewId()))
+}
+
+func TestDeleteTeamsDryRun(t *testing.T) {
+	th := api.Setup().InitBasic()
+	defer th.TearDown()
+
+	missing := "missing" + model.NewId()
+	output := cmd.CheckCommand(t, "team", "delete", th.BasicTeam.Name, missing, "--dry-run")
+	require.Regexp(t, th.BasicTeam.Name+`\s+`+th.BasicTeam.Id+`\s+2\s+\d+`, output)
+	require.Contains(t, output, "Unable to find team '"+missing+"'")
+	require.Contains(t, output, "Would delete 1 teams, 1 not found.")
+
+	team, err := th.App.GetTeam(th.BasicTeam.Id)
+	require.Nil(t, err)
+	require.Equal(t, int64(0), team.DeleteAt)
 }
@@ -8,6 +8,8 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"sort"
+	"strings"
 
 	l4g "github.com/alecthomas/log4go"
 	"github.com/mattermost/mattermost-server/app"
@@ -154,6 +156,18 @@ var SearchUserCmd = &cobra.Command{
 	RunE:    searchUserCmdF,
 }
 
+var FindDuplicateUsersCmd = &cobra.Command{
+	Use:   "find-duplicates",
+	Short: "Find users that appear to be duplicate accounts",
+	Long: `List groups of users that appear to belong to the same person, along with the teams each of them is a member of.
+With --by email, users are grouped by email address, ignoring case and any +tag in the part before the @. With --ignore-dots, dots before the @ are ignored too, as Gmail does.
+This command only reports duplicates, it doesn't change any accounts.`,
+	Example: `  user find-duplicates --by email
+  user find-duplicates --by email --json
+  user find-duplicates --by email --ignore-dots`,
+	RunE: findDuplicateUsersCmdF,
+}
+
 func init() {
 	UserCreateCmd.Flags().String("username", "", "Required. Username for the new user account.")
 	UserCreateCmd.Flags().String("email", "", "Required. The email address for the new user account.")
@@ -232,6 +246,10 @@ Global Flags:
 {{.InheritedFlags.FlagUsages | trimTrailingWhitespaces}}
 `)
 
+	FindDuplicateUsersCmd.Flags().String("by", "email", "The field to compare users by. Only email is supported.")
+	FindDuplicateUsersCmd.Flags().Bool("json", false, "Print the results as JSON.")
+	FindDuplicateUsersCmd.Flags().Bool("ignore-dots", false, "Also ignore dots in the part of email addresses before the @.")
+
 	UserCmd.AddCommand(
 		UserActivateCmd,
 		UserDeactivateCmd,
@@ -245,6 +263,7 @@ Global Flags:
 		MigrateAuthCmd,
 		VerifyUserCmd,
 		SearchUserCmd,
+		FindDuplicateUsersCmd,
 	)
 	cmd.RootCmd.AddCommand(UserCmd)
 }
@@ -259,12 +278,14 @@ func userActivateCmdF(command *cobra.Command, args []string) error {
 		return errors.New("Expected at least one argument. See help text for details.")
 	}
 
-	changeUsersActiveStatus(a, args, true)
-	return nil
+	return changeUsersActiveStatus(a, args, true)
 }
 
-func changeUsersActiveStatus(a *app.App, userArgs []string, active bool) {
-	users := getUsersFromUserArgs(a, userArgs)
+func changeUsersActiveStatus(a *app.App, userArgs []string, active bool) error {
+	users, appErr := getUsersFromUserArgs(a, userArgs)
+	if appErr != nil {
+		return errors.New("Unable to look up the users. Error: " + appErr.Error())
+	}
 	for i, user := range users {
 		err := changeUserActiveStatus(a, user, userArgs[i], active)
 
@@ -272,6 +293,7 @@ func changeUsersActiveStatus(a *app.App, userArgs []string, active bool) {
 			cmd.CommandPrintErrorln(err.Error())
 		}
 	}
+	return nil
 }
 
 func changeUserActiveStatus(a *app.App, user *model.User, userArg string, activate bool) error {
@@ -298,8 +320,7 @@ func userDeactivateCmdF(command *cobra.Command, args []string) error {
 		return errors.New("Expected at least one argument. See help text for details.")
 	}
 
-	changeUsersActiveStatus(a, args, false)
-	return nil
+	return changeUsersActiveStatus(a, args, false)
 }
 
 func userCreateCmdF(command *cobra.Command, args []string) error {
@@ -449,7 +470,10 @@ func resetUserMfaCmdF(command *cobra.Command, args []string) error {
 		return errors.New("Expected at least one argument. See help text for details.")
 	}
 
-	users := getUsersFromUserArgs(a, args)
+	users, appErr := getUsersFromUserArgs(a, args)
+	if appErr != nil {
+		return errors.New("Unable to look up the users. Error: " + appErr.Error())
+	}
 
 	for i, user := range users {
 		if user == nil {
@@ -490,7 +514,10 @@ func deleteUserCmdF(command *cobra.Command, args []string) error {
 		}
 	}
 
-	users := getUsersFromUserArgs(a, args)
+	users, appErr := getUsersFromUserArgs(a, args)
+	if appErr != nil {
+		return errors.New("Unable to look up the users. Error: " + appErr.Error())
+	}
 
 	for i, user := range users {
 		if user == nil {
@@ -647,7 +674,10 @@ func verifyUserCmdF(command *cobra.Command, args []string) error {
 		return errors.New("Expected at least one argument. See help text for details.")
 	}
 
-	users := getUsersFromUserArgs(a, args)
+	users, appErr := getUsersFromUserArgs(a, args)
+	if appErr != nil {
+		return errors.New("Unable to look up the users. Error: " + appErr.Error())
+	}
 
 	for i, user := range users {
 		if user == nil {
@@ -672,7 +702,10 @@ func searchUserCmdF(command *cobra.Command, args []string) error {
 		return errors.New("Expected at least one argument. See help text for details.")
 	}
 
-	users := getUsersFromUserArgs(a, args)
+	users, appErr := getUsersFromUserArgs(a, args)
+	if appErr != nil {
+		return errors.New("Unable to look up the users. Error: " + appErr.Error())
+	}
 
 	for i, user := range users {
 		if i > 0 {
@@ -695,3 +728,91 @@ func searchUserCmdF(command *cobra.Command, args []string) error {
 
 	return nil
 }
+
+type duplicateUser struct {
+	Id       string   `json:"id"`
+	Username string   `json:"username"`
+	Email    string   `json:"email"`
+	Teams    []string `json:"teams"`
+}
+
+type duplicateUserGroup struct {
+	Key   string           `json:"key"`
+	Users []*duplicateUser `json:"users"`
+}
+
+func findDuplicateUsersCmdF(command *cobra.Command, args []string) error {
+	a, err := cmd.InitDBCommandContextCobra(command)
+	if err != nil {
+		return err
+	}
+
+	by, _ := command.Flags().GetString("by")
+	if by != "email" {
+		return errors.New("Users can only be compared by email.")
+	}
+	jsonFlag, _ := command.Flags().GetBool("json")
+	ignoreDots, _ := command.Flags().GetBool("ignore-dots")
+
+	ctx, cancel := cmd.InterruptContext()
+	defer cancel()
+
+	usersByKey := map[string][]*model.User{}
+	keys := []string{}
+	scanned, err := cmd.ForEachUser(ctx, a, cmd.DEFAULT_SCAN_PAGE_SIZE, func(user *model.User) error {
+		key := model.NormalizeEmailMailbox(user.Email, ignoreDots)
+		if _, ok := usersByKey[key]; !ok {
+			keys = append(keys, key)
+		}
+		usersByKey[key] = append(usersByKey[key], user)
+		return nil
+	})
+	if err := cmd.ScanError(err, "Scan interrupted after %v users.", scanned); err != nil {
+		return err
+	}
+
+	sort.Strings(keys)
+
+	groups := []*duplicateUserGroup{}
+	for _, key := range keys {
+		users := usersByKey[key]
+		if len(users) < 2 {
+			continue
+		}
+
+		group := &duplicateUserGroup{Key: key, Users: []*duplicateUser{}}
+		for _, user := range users {
+			teams, err := a.GetTeamsForUser(user.Id)
+			if err != nil {
+				return err
+			}
+
+			duplicate := &duplicateUser{Id: user.Id, Username: user.Username, Email: user.Email, Teams: []string{}}
+			for _, team := range teams {
+				duplicate.Teams = append(duplicate.Teams, team.Name)
+			}
+			group.Users = append(group.Users, duplicate)
+		}
+		groups = append(groups, group)
+	}
+
+	if jsonFlag {
+		page := cmd.NewCompletePage(groups, len(groups))
+		cmd.CommandPrintln(page.ToJson())
+		return nil
+	}
+
+	if len(groups) == 0 {
+		cmd.CommandPrettyPrintln("No duplicate users found.")
+		return nil
+	}
+
+	for _, group := range groups {
+		cmd.CommandPrintln(group.Key + ":")
+		for _, user := range group.Users {
+			cmd.CommandPrintln(fmt.Sprintf("  %v (%v): %v", user.Username, user.Email, strings.Join(user.Teams, ", ")))
+		}
+	}
+
+	return nil
+}
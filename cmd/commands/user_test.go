@@ -108,3 +108,41 @@ func TestChangeUserEmail(t *testing.T) {
 	require.Error(t, cmd.RunCommand(t, "user", "email", "invalidUser", newEmail))
 
 }
+
+func TestFindDuplicateUsers(t *testing.T) {
+	th := api.Setup().InitBasic()
+	defer th.TearDown()
+
+	id := model.NewId()
+	user1 := th.CreateUser(th.BasicClient)
+	user2 := th.CreateUser(th.BasicClient)
+
+	user1.Email = "dup" + id + "+work@example.com"
+	user2.Email = "dup" + id + "@example.com"
+	for _, user := range []*model.User{user1, user2} {
+		_, err := th.App.UpdateUser(user, false)
+		require.Nil(t, err)
+	}
+	th.LinkUserToTeam(user1, th.BasicTeam)
+
+	output := cmd.CheckCommand(t, "user", "find-duplicates", "--by", "email")
+	require.Contains(t, output, "dup"+id+"@example.com:")
+	require.Contains(t, output, "  "+user1.Username+" ("+user1.Email+"): "+th.BasicTeam.Name)
+	require.Contains(t, output, "  "+user2.Username+" ("+user2.Email+"): ")
+
+	output = cmd.CheckCommand(t, "user", "find-duplicates", "--json")
+	require.Contains(t, output, `{"id":"`+user1.Id+`","username":"`+user1.Username+`","email":"`+user1.Email+`","teams":["`+th.BasicTeam.Name+`"]}`)
+
+	user3 := th.CreateUser(th.BasicClient)
+	user3.Email = "d.u.p" + id + "@example.com"
+	_, err := th.App.UpdateUser(user3, false)
+	require.Nil(t, err)
+
+	output = cmd.CheckCommand(t, "user", "find-duplicates")
+	require.NotContains(t, output, user3.Username)
+
+	output = cmd.CheckCommand(t, "user", "find-duplicates", "--ignore-dots")
+	require.Contains(t, output, "  "+user3.Username+" ("+user3.Email+"): ")
+
+	require.Error(t, cmd.RunCommand(t, "user", "find-duplicates", "--by", "username"))
+}
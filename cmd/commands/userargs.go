@@ -8,13 +8,11 @@ import (
 	"github.com/mattermost/mattermost-server/model"
 )
 
-func getUsersFromUserArgs(a *app.App, userArgs []string) []*model.User {
-	users := make([]*model.User, 0, len(userArgs))
-	for _, userArg := range userArgs {
-		user := getUserFromUserArg(a, userArg)
-		users = append(users, user)
-	}
-	return users
+// getUsersFromUserArgs resolves each argument to the user with that email, username or id. The result lines up
+// with userArgs and holds nil for the arguments that don't match any user. A store error is returned as is rather
+// than reported as users not being found, so that callers don't act on a lookup that never happened.
+func getUsersFromUserArgs(a *app.App, userArgs []string) ([]*model.User, *model.AppError) {
+	return a.GetUsersByIdentifiers(userArgs)
 }
 
 func getUserFromUserArg(a *app.App, userArg string) *model.User {
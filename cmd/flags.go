@@ -0,0 +1,61 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// RequireMutuallyExclusive returns an error if more than one of the named flags was set on the command line.
+func RequireMutuallyExclusive(cmd *cobra.Command, flags ...string) error {
+	set := []string{}
+	for _, flag := range flags {
+		if cmd.Flags().Changed(flag) {
+			set = append(set, "--"+flag)
+		}
+	}
+
+	if len(set) > 1 {
+		return errors.New("Flags " + strings.Join(set, " and ") + " can't be used together.")
+	}
+
+	return nil
+}
+
+// RequireArgsOrFlag returns an error unless the command was given either arguments or the named boolean flag,
+// such as --all, but not both. what describes the arguments in the error message.
+func RequireArgsOrFlag(cmd *cobra.Command, args []string, flag, what string) error {
+	set, _ := cmd.Flags().GetBool(flag)
+	if set && len(args) > 0 {
+		return fmt.Errorf("Expected %v or --%v, but not both.", what, flag)
+	} else if !set && len(args) == 0 {
+		return fmt.Errorf("Expected %v or --%v.", what, flag)
+	}
+
+	return nil
+}
+
+// GetChangedString returns the value of the named string flag and whether it was set on the command line, so
+// that an explicitly empty value can be told apart from an omitted flag.
+func GetChangedString(cmd *cobra.Command, name string) (value string, changed bool) {
+	value, _ = cmd.Flags().GetString(name)
+	return value, cmd.Flags().Changed(name)
+}
+
+// AddConcurrencyFlag registers --concurrency on a command that can run its checks in parallel, with usage
+// describing what is done at once.
+func AddConcurrencyFlag(cmd *cobra.Command, usage string) {
+	cmd.Flags().Int("concurrency", DEFAULT_CONCURRENCY, fmt.Sprintf("%v At most %v.", usage, MAX_CONCURRENCY))
+}
+
+// GetConcurrencyLimiter returns a ConcurrencyLimiter sized by the --concurrency flag of the command, clamped to
+// MAX_CONCURRENCY.
+func GetConcurrencyLimiter(cmd *cobra.Command) *ConcurrencyLimiter {
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
+	return NewConcurrencyLimiter(ResolveLimit(concurrency, DEFAULT_CONCURRENCY, MAX_CONCURRENCY))
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireMutuallyExclusive(t *testing.T) {
+	newCommand := func(args ...string) *cobra.Command {
+		command := &cobra.Command{Use: "modify"}
+		command.Flags().Bool("public", false, "")
+		command.Flags().Bool("private", false, "")
+		command.Flags().String("name", "", "")
+		require.NoError(t, command.ParseFlags(args))
+		return command
+	}
+
+	require.NoError(t, RequireMutuallyExclusive(newCommand(), "public", "private"))
+	require.NoError(t, RequireMutuallyExclusive(newCommand("--private"), "public", "private"))
+	require.NoError(t, RequireMutuallyExclusive(newCommand("--public", "--name", "x"), "public", "private"))
+	require.NoError(t, RequireMutuallyExclusive(newCommand("--public=false"), "public", "private"))
+
+	err := RequireMutuallyExclusive(newCommand("--public", "--private=false"), "public", "private")
+	require.EqualError(t, err, "Flags --public and --private can't be used together.")
+}
+
+func TestRequireArgsOrFlag(t *testing.T) {
+	newCommand := func(args ...string) *cobra.Command {
+		command := &cobra.Command{Use: "engagement"}
+		command.Flags().Bool("all", false, "")
+		require.NoError(t, command.ParseFlags(args))
+		return command
+	}
+
+	require.NoError(t, RequireArgsOrFlag(newCommand(), []string{"myteam"}, "all", "teams"))
+	require.NoError(t, RequireArgsOrFlag(newCommand("--all"), nil, "all", "teams"))
+	require.EqualError(t, RequireArgsOrFlag(newCommand("--all"), []string{"myteam"}, "all", "teams"), "Expected teams or --all, but not both.")
+	require.EqualError(t, RequireArgsOrFlag(newCommand(), nil, "all", "teams"), "Expected teams or --all.")
+}
+
+func TestGetChangedString(t *testing.T) {
+	newCommand := func(args ...string) *cobra.Command {
+		command := &cobra.Command{Use: "modify"}
+		command.Flags().String("description", "default", "")
+		require.NoError(t, command.ParseFlags(args))
+		return command
+	}
+
+	value, changed := GetChangedString(newCommand(), "description")
+	require.False(t, changed)
+	require.Equal(t, "default", value)
+
+	value, changed = GetChangedString(newCommand("--description", ""), "description")
+	require.True(t, changed)
+	require.Equal(t, "", value)
+
+	value, changed = GetChangedString(newCommand("--description=New description"), "description")
+	require.True(t, changed)
+	require.Equal(t, "New description", value)
+}
@@ -0,0 +1,38 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package cmd
+
+const (
+	DEFAULT_CONCURRENCY = 1
+	MAX_CONCURRENCY     = 16
+)
+
+// ConcurrencyLimiter is a semaphore that lets at most a fixed number of goroutines hold it at once, so that a
+// scan running its checks in parallel doesn't overload the database. Every Acquire must be paired with a Release.
+type ConcurrencyLimiter struct {
+	slots chan struct{}
+}
+
+// NewConcurrencyLimiter returns a ConcurrencyLimiter with limit slots, or a single slot if limit isn't positive.
+func NewConcurrencyLimiter(limit int) *ConcurrencyLimiter {
+	if limit < 1 {
+		limit = 1
+	}
+	return &ConcurrencyLimiter{slots: make(chan struct{}, limit)}
+}
+
+// Acquire blocks until a slot is free and takes it.
+func (l *ConcurrencyLimiter) Acquire() {
+	l.slots <- struct{}{}
+}
+
+// Release frees a slot taken by Acquire.
+func (l *ConcurrencyLimiter) Release() {
+	<-l.slots
+}
+
+// Limit returns the number of goroutines that can hold the limiter at once.
+func (l *ConcurrencyLimiter) Limit() int {
+	return cap(l.slots)
+}
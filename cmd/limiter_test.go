@@ -0,0 +1,75 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package cmd
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcurrencyLimiter(t *testing.T) {
+	t.Run("bounds concurrent holders", func(t *testing.T) {
+		limiter := NewConcurrencyLimiter(3)
+		require.Equal(t, 3, limiter.Limit())
+
+		var current, peak int32
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				limiter.Acquire()
+				defer limiter.Release()
+
+				n := atomic.AddInt32(&current, 1)
+				for {
+					p := atomic.LoadInt32(&peak)
+					if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond)
+				atomic.AddInt32(&current, -1)
+			}()
+		}
+		wg.Wait()
+
+		require.True(t, peak <= 3, "peak of %v holders", peak)
+		require.True(t, peak > 1, "the holders should have overlapped")
+	})
+
+	t.Run("non-positive limit allows one holder", func(t *testing.T) {
+		require.Equal(t, 1, NewConcurrencyLimiter(0).Limit())
+		require.Equal(t, 1, NewConcurrencyLimiter(-5).Limit())
+	})
+
+	t.Run("release frees a slot", func(t *testing.T) {
+		limiter := NewConcurrencyLimiter(1)
+		limiter.Acquire()
+
+		acquired := make(chan struct{})
+		go func() {
+			limiter.Acquire()
+			close(acquired)
+		}()
+
+		select {
+		case <-acquired:
+			t.Fatal("should have blocked while the only slot is held")
+		case <-time.After(20 * time.Millisecond):
+		}
+
+		limiter.Release()
+		select {
+		case <-acquired:
+		case <-time.After(time.Second):
+			t.Fatal("should have acquired the released slot")
+		}
+	})
+}
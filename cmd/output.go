@@ -8,6 +8,10 @@ import (
 	"os"
 )
 
+func CommandPrint(a ...interface{}) (int, error) {
+	return fmt.Print(a...)
+}
+
 func CommandPrintln(a ...interface{}) (int, error) {
 	return fmt.Println(a...)
 }
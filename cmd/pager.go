@@ -0,0 +1,173 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/mattermost/mattermost-server/app"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+const (
+	DEFAULT_SCAN_PAGE_SIZE = 200
+	MAX_SCAN_PAGE_SIZE     = 1000
+
+	DEFAULT_LIST_LIMIT = 200
+	MAX_LIST_LIMIT     = 10000
+)
+
+// ResolveLimit returns the requested limit clamped to max, or def when no positive limit was requested, so
+// that a list command never fetches more rows than it can hold in memory.
+func ResolveLimit(requested, def, max int) int {
+	if requested <= 0 {
+		requested = def
+	}
+	if requested > max {
+		return max
+	}
+	if requested < 1 {
+		return 1
+	}
+	return requested
+}
+
+// ScanError returns the error a command should return once a scan has stopped with err. If the scan was
+// interrupted, that's an error built from format and a, which should tell the user how far the scan got.
+// Otherwise it's err itself, which is nil when the scan finished.
+func ScanError(err error, format string, a ...interface{}) error {
+	if err == context.Canceled {
+		return fmt.Errorf(format, a...)
+	}
+	return err
+}
+
+// NewCompletePage returns a page holding all count of the items, for commands that print their whole result
+// at once.
+func NewCompletePage(items interface{}, count int) *model.Page {
+	return &model.Page{Items: items, Limit: count, Total: count}
+}
+
+// InterruptContext returns a context that is canceled when the process receives SIGINT or SIGTERM,
+// so that long running scans can stop gracefully. The returned cancel function must be called to
+// release the signal handler.
+func InterruptContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	interruptChan := make(chan os.Signal, 1)
+	signal.Notify(interruptChan, syscall.SIGINT, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case <-interruptChan:
+			CommandPrettyPrintln("Interrupted, stopping...")
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(interruptChan)
+		cancel()
+	}
+}
+
+// ForEachPage fetches items perPage at a time and calls fn on each of them until a short page is
+// returned, fn returns an error or ctx is canceled. It returns the number of items processed along
+// with the error that stopped the scan, which is ctx.Err() when the scan was canceled.
+func ForEachPage(ctx context.Context, perPage int, fetch func(offset, limit int) ([]interface{}, error), fn func(item interface{}) error) (int, error) {
+	perPage = ResolveLimit(perPage, DEFAULT_SCAN_PAGE_SIZE, MAX_SCAN_PAGE_SIZE)
+
+	processed := 0
+	for offset := 0; ; offset += perPage {
+		if err := ctx.Err(); err != nil {
+			return processed, err
+		}
+
+		items, err := fetch(offset, perPage)
+		if err != nil {
+			return processed, err
+		}
+
+		for _, item := range items {
+			if err := ctx.Err(); err != nil {
+				return processed, err
+			}
+
+			if err := fn(item); err != nil {
+				return processed, err
+			}
+			processed++
+		}
+
+		if len(items) < perPage {
+			return processed, nil
+		}
+	}
+}
+
+// ForEachTeam calls fn on every team, including archived ones, fetching them a page at a time.
+func ForEachTeam(ctx context.Context, a *app.App, perPage int, fn func(team *model.Team) error) (int, error) {
+	fetch := func(offset, limit int) ([]interface{}, error) {
+		teams, err := a.GetAllTeamsPage(offset, limit)
+		if err != nil {
+			return nil, err
+		}
+
+		items := make([]interface{}, len(teams))
+		for i, team := range teams {
+			items[i] = team
+		}
+		return items, nil
+	}
+
+	return ForEachPage(ctx, perPage, fetch, func(item interface{}) error {
+		return fn(item.(*model.Team))
+	})
+}
+
+// ForEachUser calls fn on every user, including deactivated ones, fetching them a page at a time.
+func ForEachUser(ctx context.Context, a *app.App, perPage int, fn func(user *model.User) error) (int, error) {
+	fetch := func(offset, limit int) ([]interface{}, error) {
+		users, err := a.GetUsers(offset, limit)
+		if err != nil {
+			return nil, err
+		}
+
+		items := make([]interface{}, len(users))
+		for i, user := range users {
+			items[i] = user
+		}
+		return items, nil
+	}
+
+	return ForEachPage(ctx, perPage, fetch, func(item interface{}) error {
+		return fn(item.(*model.User))
+	})
+}
+
+// ForEachChannel calls fn on every public and private channel of every team, including archived ones, fetching
+// them a page at a time.
+func ForEachChannel(ctx context.Context, a *app.App, perPage int, fn func(channel *model.Channel) error) (int, error) {
+	fetch := func(offset, limit int) ([]interface{}, error) {
+		channels, err := a.GetAllChannels(offset, limit)
+		if err != nil {
+			return nil, err
+		}
+
+		items := make([]interface{}, len(channels))
+		for i, channel := range channels {
+			items[i] = channel
+		}
+		return items, nil
+	}
+
+	return ForEachPage(ctx, perPage, fetch, func(item interface{}) error {
+		return fn(item.(*model.Channel))
+	})
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func fetchRange(total int) func(offset, limit int) ([]interface{}, error) {
+	return func(offset, limit int) ([]interface{}, error) {
+		items := []interface{}{}
+		for i := offset; i < total && i < offset+limit; i++ {
+			items = append(items, i)
+		}
+		return items, nil
+	}
+}
+
+func TestForEachPage(t *testing.T) {
+	t.Run("visits every item", func(t *testing.T) {
+		seen := []int{}
+		processed, err := ForEachPage(context.Background(), 3, fetchRange(10), func(item interface{}) error {
+			seen = append(seen, item.(int))
+			return nil
+		})
+		require.Nil(t, err)
+		require.Equal(t, 10, processed)
+		require.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, seen)
+	})
+
+	t.Run("exact multiple of page size", func(t *testing.T) {
+		processed, err := ForEachPage(context.Background(), 5, fetchRange(10), func(item interface{}) error {
+			return nil
+		})
+		require.Nil(t, err)
+		require.Equal(t, 10, processed)
+	})
+
+	t.Run("stops when canceled mid-scan", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		fetches := 0
+		fetch := func(offset, limit int) ([]interface{}, error) {
+			fetches++
+			return fetchRange(1000)(offset, limit)
+		}
+
+		processed, err := ForEachPage(ctx, 10, fetch, func(item interface{}) error {
+			if item.(int) == 14 {
+				cancel()
+			}
+			return nil
+		})
+		require.Equal(t, context.Canceled, err)
+		require.Equal(t, 15, processed)
+		require.Equal(t, 2, fetches)
+	})
+
+	t.Run("stops on callback error", func(t *testing.T) {
+		stop := errors.New("stop")
+		processed, err := ForEachPage(context.Background(), 10, fetchRange(100), func(item interface{}) error {
+			if item.(int) == 3 {
+				return stop
+			}
+			return nil
+		})
+		require.Equal(t, stop, err)
+		require.Equal(t, 3, processed)
+	})
+
+	t.Run("stops on fetch error", func(t *testing.T) {
+		fail := errors.New("fail")
+		processed, err := ForEachPage(context.Background(), 10, func(offset, limit int) ([]interface{}, error) {
+			return nil, fail
+		}, func(item interface{}) error {
+			return nil
+		})
+		require.Equal(t, fail, err)
+		require.Equal(t, 0, processed)
+	})
+}
+
+func TestResolveLimit(t *testing.T) {
+	for name, tc := range map[string]struct {
+		Requested int
+		Expected  int
+	}{
+		"zero uses default":      {0, 50},
+		"negative uses default":  {-10, 50},
+		"over max is clamped":    {10000000, 100},
+		"max is kept":            {100, 100},
+		"in range is kept":       {1, 1},
+		"in range above default": {75, 75},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.Expected, ResolveLimit(tc.Requested, 50, 100))
+		})
+	}
+
+	t.Run("default over max is clamped", func(t *testing.T) {
+		require.Equal(t, 100, ResolveLimit(0, 500, 100))
+	})
+}
+
+func TestScanError(t *testing.T) {
+	require.NoError(t, ScanError(nil, "Scan interrupted after %v teams.", 3))
+	require.EqualError(t, ScanError(context.Canceled, "Scan interrupted after %v teams.", 3), "Scan interrupted after 3 teams.")
+
+	err := errors.New("database unavailable")
+	require.Equal(t, err, ScanError(err, "Scan interrupted after %v teams.", 3))
+}
+
+func TestNewCompletePage(t *testing.T) {
+	items := []string{"a", "b", "c"}
+	page := NewCompletePage(items, len(items))
+	require.Equal(t, items, page.Items)
+	require.Equal(t, 0, page.Offset)
+	require.Equal(t, 3, page.Limit)
+	require.Equal(t, 3, page.Total)
+	require.False(t, page.HasNext())
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	ROW_STATUS_SKIPPED = "skipped"
+	ROW_STATUS_ERROR   = "error"
+)
+
+// RowResult is the outcome of processing one row of the input of a bulk command. Line is 0 for results
+// that don't come from a line of the input, such as members removed because they were missing from it.
+type RowResult struct {
+	Line       int
+	Identifier string
+	Status     string
+	Error      error
+}
+
+type RowResults []RowResult
+
+func (r *RowResults) Add(line int, identifier, status string, err error) {
+	if err != nil {
+		status = ROW_STATUS_ERROR
+	}
+	*r = append(*r, RowResult{Line: line, Identifier: identifier, Status: status, Error: err})
+}
+
+// Count returns the number of results with the given status.
+func (r RowResults) Count(status string) int {
+	count := 0
+	for _, result := range r {
+		if result.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// ToTable renders the results as aligned columns with a header row.
+func (r RowResults) ToTable() string {
+	table := NewTablePrinter("LINE", "IDENTIFIER", "STATUS", "ERROR")
+	for _, result := range r {
+		line := "-"
+		if result.Line > 0 {
+			line = fmt.Sprint(result.Line)
+		}
+
+		errMessage := ""
+		if result.Error != nil {
+			errMessage = result.Error.Error()
+		}
+
+		table.AddRow(line, result.Identifier, result.Status, errMessage)
+	}
+
+	return table.String()
+}
+
+type rowResultJson struct {
+	Line       int    `json:"line,omitempty"`
+	Identifier string `json:"identifier"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+}
+
+func (r RowResults) ToJson() string {
+	results := make([]rowResultJson, len(r))
+	for i, result := range r {
+		results[i] = rowResultJson{Line: result.Line, Identifier: result.Identifier, Status: result.Status}
+		if result.Error != nil {
+			results[i].Error = result.Error.Error()
+		}
+	}
+
+	b, _ := json.Marshal(results)
+	return string(b)
+}
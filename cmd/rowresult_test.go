@@ -0,0 +1,55 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRowResults(t *testing.T) {
+	results := RowResults{}
+	results.Add(2, "user1@example.com", "added", nil)
+	results.Add(3, "user2", ROW_STATUS_SKIPPED, nil)
+	results.Add(5, "missing-user", "added", errors.New("user not found"))
+	results.Add(0, "user4", "removed", nil)
+
+	t.Run("add", func(t *testing.T) {
+		require.Len(t, results, 4)
+		require.Equal(t, ROW_STATUS_ERROR, results[2].Status)
+		require.EqualError(t, results[2].Error, "user not found")
+	})
+
+	t.Run("count", func(t *testing.T) {
+		require.Equal(t, 1, results.Count("added"))
+		require.Equal(t, 1, results.Count(ROW_STATUS_SKIPPED))
+		require.Equal(t, 1, results.Count(ROW_STATUS_ERROR))
+		require.Equal(t, 1, results.Count("removed"))
+		require.Equal(t, 0, results.Count("unknown"))
+	})
+
+	t.Run("table", func(t *testing.T) {
+		require.Equal(t, ""+
+			"LINE  IDENTIFIER         STATUS   ERROR\n"+
+			"2     user1@example.com  added\n"+
+			"3     user2              skipped\n"+
+			"5     missing-user       error    user not found\n"+
+			"-     user4              removed\n", results.ToTable())
+	})
+
+	t.Run("json", func(t *testing.T) {
+		require.Equal(t, `[`+
+			`{"line":2,"identifier":"user1@example.com","status":"added"},`+
+			`{"line":3,"identifier":"user2","status":"skipped"},`+
+			`{"line":5,"identifier":"missing-user","status":"error","error":"user not found"},`+
+			`{"identifier":"user4","status":"removed"}]`, results.ToJson())
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		require.Equal(t, "LINE  IDENTIFIER  STATUS  ERROR\n", RowResults{}.ToTable())
+		require.Equal(t, "[]", RowResults{}.ToJson())
+	})
+}
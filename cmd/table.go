@@ -0,0 +1,76 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+const TABLE_COLUMN_GAP = 2
+
+// TablePrinter renders rows of values as columns padded to line up with each other, under a row of headers
+// unless NoHeaders is set.
+type TablePrinter struct {
+	Headers   []string
+	NoHeaders bool
+	rows      [][]string
+}
+
+func NewTablePrinter(headers ...string) *TablePrinter {
+	return &TablePrinter{Headers: headers}
+}
+
+// AddRow adds a row with one value per column. Values are formatted with fmt.Sprint.
+func (t *TablePrinter) AddRow(values ...interface{}) {
+	row := make([]string, len(values))
+	for i, value := range values {
+		row[i] = fmt.Sprint(value)
+	}
+	t.rows = append(t.rows, row)
+}
+
+func (t *TablePrinter) Print(w io.Writer) error {
+	rows := t.rows
+	if !t.NoHeaders && len(t.Headers) > 0 {
+		rows = append([][]string{t.Headers}, rows...)
+	}
+
+	widths := []int{}
+	for _, row := range rows {
+		for i, value := range row {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			if width := utf8.RuneCountInString(value); width > widths[i] {
+				widths[i] = width
+			}
+		}
+	}
+
+	for _, row := range rows {
+		var line bytes.Buffer
+		for i, value := range row {
+			line.WriteString(value)
+			if i < len(row)-1 {
+				line.WriteString(strings.Repeat(" ", widths[i]-utf8.RuneCountInString(value)+TABLE_COLUMN_GAP))
+			}
+		}
+
+		if _, err := fmt.Fprintln(w, strings.TrimRight(line.String(), " ")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t *TablePrinter) String() string {
+	var b bytes.Buffer
+	t.Print(&b)
+	return b.String()
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTablePrinter(t *testing.T) {
+	newTable := func() *TablePrinter {
+		table := NewTablePrinter("NAME", "MEMBERS", "TYPE")
+		table.AddRow("engineering", 1204, "open")
+		table.AddRow("qa", 7, "invite")
+		table.AddRow("société", 12, "")
+		return table
+	}
+
+	t.Run("aligned columns", func(t *testing.T) {
+		require.Equal(t, ""+
+			"NAME         MEMBERS  TYPE\n"+
+			"engineering  1204     open\n"+
+			"qa           7        invite\n"+
+			"société      12\n", newTable().String())
+	})
+
+	t.Run("no headers", func(t *testing.T) {
+		table := newTable()
+		table.NoHeaders = true
+		require.Equal(t, ""+
+			"engineering  1204  open\n"+
+			"qa           7     invite\n"+
+			"société      12\n", table.String())
+	})
+
+	t.Run("ragged rows", func(t *testing.T) {
+		table := NewTablePrinter("A", "B")
+		table.AddRow("long value")
+		table.AddRow("x", "y", "extra")
+		require.Equal(t, ""+
+			"A           B\n"+
+			"long value\n"+
+			"x           y  extra\n", table.String())
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		require.Equal(t, "A  B\n", NewTablePrinter("A", "B").String())
+
+		table := NewTablePrinter("A", "B")
+		table.NoHeaders = true
+		require.Equal(t, "", table.String())
+	})
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package cmd
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	DEFAULT_LOG_THROTTLE_LIMIT    = 100
+	DEFAULT_LOG_THROTTLE_INTERVAL = 10 * time.Second
+)
+
+// ThrottledLogger prints at most Limit messages per Interval and counts the rest, so that a scan reporting
+// a problem with every item doesn't flood the output. The number of suppressed messages is printed as a
+// summary when the interval is over or when Flush is called, which should be done once the scan is finished.
+type ThrottledLogger struct {
+	Limit    int
+	Interval time.Duration
+
+	print       func(a ...interface{}) (int, error)
+	now         func() time.Time
+	mutex       sync.Mutex
+	windowStart time.Time
+	printed     int
+	suppressed  int
+}
+
+// NewThrottledLogger returns a ThrottledLogger that writes with print, such as CommandPrintErrorln.
+func NewThrottledLogger(limit int, interval time.Duration, print func(a ...interface{}) (int, error)) *ThrottledLogger {
+	return &ThrottledLogger{
+		Limit:    limit,
+		Interval: interval,
+		print:    print,
+		now:      time.Now,
+	}
+}
+
+// NewDefaultThrottledLogger returns a ThrottledLogger that writes with print, using the default limit and interval.
+func NewDefaultThrottledLogger(print func(a ...interface{}) (int, error)) *ThrottledLogger {
+	return NewThrottledLogger(DEFAULT_LOG_THROTTLE_LIMIT, DEFAULT_LOG_THROTTLE_INTERVAL, print)
+}
+
+// Println prints the message unless Limit messages were already printed in the current interval, in which case
+// it's only counted. The first message of a new interval prints the summary of the previous one first.
+func (l *ThrottledLogger) Println(a ...interface{}) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+	if now.Sub(l.windowStart) >= l.Interval {
+		l.flush()
+		l.windowStart = now
+		l.printed = 0
+	}
+
+	if l.printed >= l.Limit {
+		l.suppressed++
+		return
+	}
+
+	l.print(a...)
+	l.printed++
+}
+
+// Flush prints the summary of the messages suppressed since the last summary, if there are any.
+func (l *ThrottledLogger) Flush() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.flush()
+}
+
+// Suppressed returns the number of messages suppressed since the last summary.
+func (l *ThrottledLogger) Suppressed() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.suppressed
+}
+
+func (l *ThrottledLogger) flush() {
+	if l.suppressed > 0 {
+		l.print(fmt.Sprintf("... and %v more", l.suppressed))
+		l.suppressed = 0
+	}
+}
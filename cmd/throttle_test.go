@@ -0,0 +1,52 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package cmd
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestThrottledLogger(t *testing.T) {
+	var lines []string
+	print := func(a ...interface{}) (int, error) {
+		lines = append(lines, fmt.Sprint(a...))
+		return 0, nil
+	}
+
+	now := time.Unix(1500000000, 0)
+	logger := NewThrottledLogger(5, time.Minute, print)
+	logger.now = func() time.Time { return now }
+
+	for i := 0; i < 4218; i++ {
+		logger.Println(fmt.Sprintf("bad item %v", i))
+	}
+	require.Len(t, lines, 5)
+	require.Equal(t, "bad item 0", lines[0])
+	require.Equal(t, "bad item 4", lines[4])
+	require.Equal(t, 4213, logger.Suppressed())
+
+	logger.Flush()
+	require.Len(t, lines, 6)
+	require.Equal(t, "... and 4213 more", lines[5])
+	require.Equal(t, 0, logger.Suppressed())
+
+	logger.Flush()
+	require.Len(t, lines, 6, "flushing again shouldn't print another summary")
+
+	t.Run("new interval", func(t *testing.T) {
+		lines = nil
+		for i := 0; i < 7; i++ {
+			logger.Println(fmt.Sprintf("late item %v", i))
+		}
+		require.Empty(t, lines, "the limit applies until the interval is over")
+
+		now = now.Add(time.Minute)
+		logger.Println("next item")
+		require.Equal(t, []string{"... and 7 more", "next item"}, lines)
+	})
+}
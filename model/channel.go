@@ -22,6 +22,7 @@ const (
 	CHANNEL_GROUP_MAX_USERS        = 8
 	CHANNEL_GROUP_MIN_USERS        = 3
 	DEFAULT_CHANNEL                = "town-square"
+	OFF_TOPIC_CHANNEL              = "off-topic"
 	CHANNEL_DISPLAY_NAME_MAX_RUNES = 64
 	CHANNEL_NAME_MIN_LENGTH        = 2
 	CHANNEL_NAME_MAX_LENGTH        = 64
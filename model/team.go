@@ -9,6 +9,7 @@ import (
 	"io"
 	"net/http"
 	"regexp"
+	"strconv"
 	"strings"
 	"unicode/utf8"
 )
@@ -23,23 +24,34 @@ const (
 	TEAM_EMAIL_MAX_LENGTH           = 128
 	TEAM_NAME_MAX_LENGTH            = 64
 	TEAM_NAME_MIN_LENGTH            = 2
+	TEAM_PROPS_MAX_LENGTH           = 4000
+
+	TEAM_PROP_MAX_STORAGE     = "max_storage"
+	TEAM_PROP_CREATION_SOURCE = "creation_source"
+
+	TEAM_CREATION_SOURCE_CLI     = "cli"
+	TEAM_CREATION_SOURCE_API     = "api"
+	TEAM_CREATION_SOURCE_IMPORT  = "import"
+	TEAM_CREATION_SOURCE_UI      = "ui"
+	TEAM_CREATION_SOURCE_UNKNOWN = "unknown"
 )
 
 type Team struct {
-	Id                 string `json:"id"`
-	CreateAt           int64  `json:"create_at"`
-	UpdateAt           int64  `json:"update_at"`
-	DeleteAt           int64  `json:"delete_at"`
-	DisplayName        string `json:"display_name"`
-	Name               string `json:"name"`
-	Description        string `json:"description"`
-	Email              string `json:"email"`
-	Type               string `json:"type"`
-	CompanyName        string `json:"company_name"`
-	AllowedDomains     string `json:"allowed_domains"`
-	InviteId           string `json:"invite_id"`
-	AllowOpenInvite    bool   `json:"allow_open_invite"`
-	LastTeamIconUpdate int64  `json:"last_team_icon_update,omitempty"`
+	Id                 string    `json:"id"`
+	CreateAt           int64     `json:"create_at"`
+	UpdateAt           int64     `json:"update_at"`
+	DeleteAt           int64     `json:"delete_at"`
+	DisplayName        string    `json:"display_name"`
+	Name               string    `json:"name"`
+	Description        string    `json:"description"`
+	Email              string    `json:"email"`
+	Type               string    `json:"type"`
+	CompanyName        string    `json:"company_name"`
+	AllowedDomains     string    `json:"allowed_domains"`
+	InviteId           string    `json:"invite_id"`
+	AllowOpenInvite    bool      `json:"allow_open_invite"`
+	LastTeamIconUpdate int64     `json:"last_team_icon_update,omitempty"`
+	Props              StringMap `json:"props,omitempty"`
 }
 
 type TeamPatch struct {
@@ -164,6 +176,10 @@ func (o *Team) IsValid() *AppError {
 		return NewAppError("Team.IsValid", "model.team.is_valid.domains.app_error", nil, "id="+o.Id, http.StatusBadRequest)
 	}
 
+	if utf8.RuneCountInString(MapToJson(o.Props)) > TEAM_PROPS_MAX_LENGTH {
+		return NewAppError("Team.IsValid", "model.team.is_valid.props.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
 	return nil
 }
 
@@ -184,6 +200,53 @@ func (o *Team) PreUpdate() {
 	o.UpdateAt = GetMillis()
 }
 
+func (o *Team) MakeNonNil() {
+	if o.Props == nil {
+		o.Props = make(map[string]string)
+	}
+}
+
+func (o *Team) GetProp(key string) string {
+	return o.Props[key]
+}
+
+func (o *Team) SetProp(key string, value string) {
+	o.MakeNonNil()
+
+	o.Props[key] = value
+}
+
+func (o *Team) DelProp(key string) {
+	delete(o.Props, key)
+}
+
+// GetStorageQuota returns the most bytes of files the team's members may store, or 0 if the team has no quota.
+func (o *Team) GetStorageQuota() int64 {
+	quota, err := strconv.ParseInt(o.GetProp(TEAM_PROP_MAX_STORAGE), 10, 64)
+	if err != nil || quota < 0 {
+		return 0
+	}
+	return quota
+}
+
+// GetCreationSource returns how the team was created, such as TEAM_CREATION_SOURCE_CLI, or
+// TEAM_CREATION_SOURCE_UNKNOWN for teams created before the source was recorded.
+func (o *Team) GetCreationSource() string {
+	if source := o.GetProp(TEAM_PROP_CREATION_SOURCE); source != "" {
+		return source
+	}
+	return TEAM_CREATION_SOURCE_UNKNOWN
+}
+
+// TeamCreationSourceFromRequest returns TEAM_CREATION_SOURCE_UI for requests made by the web app, which marks
+// them with the X-Requested-With header, and TEAM_CREATION_SOURCE_API for any other request.
+func TeamCreationSourceFromRequest(r *http.Request) string {
+	if r.Header.Get(HEADER_REQUESTED_WITH) == HEADER_REQUESTED_WITH_XML {
+		return TEAM_CREATION_SOURCE_UI
+	}
+	return TEAM_CREATION_SOURCE_API
+}
+
 func IsReservedTeamName(s string) bool {
 	s = strings.ToLower(s)
 
@@ -211,6 +274,20 @@ func IsValidTeamName(s string) bool {
 
 var validTeamNameCharacter = regexp.MustCompile(`^[a-z0-9-]$`)
 
+// ValidateTeamInviteConsistency returns an error if the team's type and open invite setting contradict each
+// other, which is the case for an invite only team that allows anyone to join.
+func ValidateTeamInviteConsistency(t *Team) *AppError {
+	if t.Type == TEAM_INVITE && t.AllowOpenInvite {
+		return NewAppError("ValidateTeamInviteConsistency", "model.team.validate_invite_consistency.app_error", nil, "id="+t.Id, http.StatusBadRequest)
+	}
+	return nil
+}
+
+// IsValidInviteId reports whether s has the format of the invite ids generated for new teams.
+func IsValidInviteId(s string) bool {
+	return IsValidId(s)
+}
+
 func CleanTeamName(s string) string {
 	s = strings.ToLower(strings.Replace(s, " ", "-", -1))
 
@@ -238,6 +315,12 @@ func CleanTeamName(s string) string {
 	return s
 }
 
+// CanonicalizeSlug returns the form of a team name used to compare it with other names, so that names
+// differing only in case or surrounding whitespace are treated as the same.
+func CanonicalizeSlug(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
 func (o *Team) Sanitize() {
 	o.Email = ""
 	o.AllowedDomains = ""
@@ -0,0 +1,71 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+const (
+	ENGAGEMENT_POSTS_HALF_SCORE  = 10
+	ENGAGEMENT_RECENCY_HALF_DAYS = 30
+	ENGAGEMENT_ACTIVE_WEIGHT     = 0.3
+	ENGAGEMENT_POSTS_WEIGHT      = 0.4
+	ENGAGEMENT_RECENCY_WEIGHT    = 0.3
+	ENGAGEMENT_MILLIS_PER_DAY    = 24 * 60 * 60 * 1000
+	ENGAGEMENT_MAX_SCORE         = 100
+	ENGAGEMENT_NEVER_POSTED_DAYS = -1
+)
+
+// TeamEngagement holds the activity of a team and the engagement score computed from it. The score is
+//
+//	100 * (0.3 * active + 0.4 * posts + 0.3 * recency)
+//
+// where each component is between 0 and 1:
+//   - active is ActiveMemberRatio, the share of the team members whose accounts aren't deactivated.
+//   - posts is p / (p + 10) with p the PostsPerActiveMember, so 10 posts per active member scores 0.5.
+//   - recency is 30 / (30 + d) with d the days since the last post, so a team that last posted 30 days ago
+//     scores 0.5. A team that has never posted scores 0.
+type TeamEngagement struct {
+	TeamId               string  `json:"team_id"`
+	TeamName             string  `json:"team_name"`
+	TotalMembers         int64   `json:"total_members"`
+	ActiveMembers        int64   `json:"active_members"`
+	Posts                int64   `json:"posts"`
+	LastPostAt           int64   `json:"last_post_at"`
+	ActiveMemberRatio    float64 `json:"active_member_ratio"`
+	PostsPerActiveMember float64 `json:"posts_per_active_member"`
+	DaysSinceLastPost    float64 `json:"days_since_last_post"`
+	Score                float64 `json:"score"`
+}
+
+// TeamPostActivity is the number of posts made by users in a team and the time of the latest one. System messages,
+// such as join and leave notices, and deleted posts aren't counted.
+type TeamPostActivity struct {
+	Posts      int64
+	LastPostAt int64
+}
+
+// ComputeScore fills in the derived metrics and the score of the engagement from its counts, measuring recency
+// from now, in milliseconds.
+func (e *TeamEngagement) ComputeScore(now int64) {
+	e.ActiveMemberRatio = 0
+	if e.TotalMembers > 0 {
+		e.ActiveMemberRatio = float64(e.ActiveMembers) / float64(e.TotalMembers)
+	}
+
+	e.PostsPerActiveMember = 0
+	if e.ActiveMembers > 0 {
+		e.PostsPerActiveMember = float64(e.Posts) / float64(e.ActiveMembers)
+	}
+	posts := e.PostsPerActiveMember / (e.PostsPerActiveMember + ENGAGEMENT_POSTS_HALF_SCORE)
+
+	recency := 0.0
+	e.DaysSinceLastPost = ENGAGEMENT_NEVER_POSTED_DAYS
+	if e.LastPostAt > 0 {
+		e.DaysSinceLastPost = 0
+		if now > e.LastPostAt {
+			e.DaysSinceLastPost = float64(now-e.LastPostAt) / ENGAGEMENT_MILLIS_PER_DAY
+		}
+		recency = ENGAGEMENT_RECENCY_HALF_DAYS / (ENGAGEMENT_RECENCY_HALF_DAYS + e.DaysSinceLastPost)
+	}
+
+	e.Score = ENGAGEMENT_MAX_SCORE * (ENGAGEMENT_ACTIVE_WEIGHT*e.ActiveMemberRatio + ENGAGEMENT_POSTS_WEIGHT*posts + ENGAGEMENT_RECENCY_WEIGHT*recency)
+}
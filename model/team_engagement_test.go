@@ -0,0 +1,67 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTeamEngagementComputeScore(t *testing.T) {
+	now := GetMillis()
+	day := int64(ENGAGEMENT_MILLIS_PER_DAY)
+
+	t.Run("components", func(t *testing.T) {
+		e := &TeamEngagement{TotalMembers: 4, ActiveMembers: 2, Posts: 20, LastPostAt: now - 30*day}
+		e.ComputeScore(now)
+
+		assert.Equal(t, 0.5, e.ActiveMemberRatio)
+		assert.Equal(t, 10.0, e.PostsPerActiveMember)
+		assert.Equal(t, 30.0, e.DaysSinceLastPost)
+		assert.InDelta(t, 100*(0.3*0.5+0.4*0.5+0.3*0.5), e.Score, 0.0001)
+	})
+
+	t.Run("empty team", func(t *testing.T) {
+		e := &TeamEngagement{}
+		e.ComputeScore(now)
+
+		assert.Equal(t, 0.0, e.ActiveMemberRatio)
+		assert.Equal(t, 0.0, e.PostsPerActiveMember)
+		assert.Equal(t, float64(ENGAGEMENT_NEVER_POSTED_DAYS), e.DaysSinceLastPost)
+		assert.Equal(t, 0.0, e.Score)
+	})
+
+	t.Run("future last post counts as now", func(t *testing.T) {
+		e := &TeamEngagement{TotalMembers: 1, ActiveMembers: 1, LastPostAt: now + day}
+		e.ComputeScore(now)
+
+		assert.Equal(t, 0.0, e.DaysSinceLastPost)
+		assert.InDelta(t, 100*(0.3+0.3), e.Score, 0.0001)
+	})
+
+	t.Run("ordering", func(t *testing.T) {
+		engagements := []*TeamEngagement{
+			{TeamName: "busy", TotalMembers: 10, ActiveMembers: 10, Posts: 1000, LastPostAt: now},
+			{TeamName: "dormant", TotalMembers: 10, ActiveMembers: 10, Posts: 100, LastPostAt: now - 365*day},
+			{TeamName: "abandoned", TotalMembers: 10, ActiveMembers: 2, Posts: 0},
+			{TeamName: "quiet", TotalMembers: 10, ActiveMembers: 10, Posts: 50, LastPostAt: now - 7*day},
+		}
+		for _, e := range engagements {
+			e.ComputeScore(now)
+		}
+
+		sort.Slice(engagements, func(i, j int) bool {
+			return engagements[i].Score < engagements[j].Score
+		})
+
+		names := make([]string, len(engagements))
+		for i, e := range engagements {
+			names[i] = e.TeamName
+		}
+		require.Equal(t, []string{"abandoned", "dormant", "quiet", "busy"}, names)
+	})
+}
@@ -4,6 +4,7 @@
 package model
 
 import (
+	"net/http/httptest"
 	"strings"
 	"testing"
 )
@@ -64,6 +65,48 @@ func TestTeamIsValid(t *testing.T) {
 	}
 }
 
+func TestTeamProps(t *testing.T) {
+	o := Team{}
+	if o.GetProp("region") != "" {
+		t.Fatal("should be empty")
+	}
+
+	o.SetProp("region", "emea")
+	if o.GetProp("region") != "emea" {
+		t.Fatal("should have been set")
+	}
+
+	o.DelProp("region")
+	if o.GetProp("region") != "" {
+		t.Fatal("should have been deleted")
+	}
+
+	o = Team{Id: NewId(), CreateAt: GetMillis(), UpdateAt: GetMillis(), DisplayName: "Test", Name: "zzzzz", Type: TEAM_OPEN}
+	o.SetProp("big", strings.Repeat("0", TEAM_PROPS_MAX_LENGTH))
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+}
+
+func TestTeamGetStorageQuota(t *testing.T) {
+	o := Team{}
+	if o.GetStorageQuota() != 0 {
+		t.Fatal("should have no quota")
+	}
+
+	o.SetProp(TEAM_PROP_MAX_STORAGE, "10000000")
+	if o.GetStorageQuota() != 10000000 {
+		t.Fatal("should have read the quota")
+	}
+
+	for _, value := range []string{"abc", "-5", "10MB"} {
+		o.SetProp(TEAM_PROP_MAX_STORAGE, value)
+		if o.GetStorageQuota() != 0 {
+			t.Fatalf("should ignore invalid quota %q", value)
+		}
+	}
+}
+
 func TestTeamPreSave(t *testing.T) {
 	o := Team{DisplayName: "test"}
 	o.PreSave()
@@ -130,3 +173,73 @@ func TestCleanTeamName(t *testing.T) {
 		t.Fatal("didn't clean name properly")
 	}
 }
+
+func TestCanonicalizeSlug(t *testing.T) {
+	for input, expected := range map[string]string{
+		"myteam":      "myteam",
+		"MyTeam":      "myteam",
+		"  myteam\t":  "myteam",
+		" MY-TEAM ":   "my-team",
+		"":            "",
+		"my team":     "my team",
+		"\tMyTeam2\n": "myteam2",
+	} {
+		if actual := CanonicalizeSlug(input); actual != expected {
+			t.Fatalf("expected %q for %q but got %q", expected, input, actual)
+		}
+	}
+}
+
+func TestIsValidInviteId(t *testing.T) {
+	if !IsValidInviteId(NewId()) {
+		t.Fatal("generated invite id should be valid")
+	}
+
+	for _, inviteId := range []string{"", "legacy", "legacy-invite-id-000000000", NewId() + "a", NewId()[1:] + "!"} {
+		if IsValidInviteId(inviteId) {
+			t.Fatalf("invite id %q should be invalid", inviteId)
+		}
+	}
+}
+
+func TestTeamCreationSource(t *testing.T) {
+	team := Team{}
+	if source := team.GetCreationSource(); source != TEAM_CREATION_SOURCE_UNKNOWN {
+		t.Fatalf("teams without a source should be unknown, got %v", source)
+	}
+
+	team.SetProp(TEAM_PROP_CREATION_SOURCE, TEAM_CREATION_SOURCE_CLI)
+	if source := team.GetCreationSource(); source != TEAM_CREATION_SOURCE_CLI {
+		t.Fatalf("expected %v, got %v", TEAM_CREATION_SOURCE_CLI, source)
+	}
+
+	r := httptest.NewRequest("POST", "/api/v4/teams", nil)
+	if source := TeamCreationSourceFromRequest(r); source != TEAM_CREATION_SOURCE_API {
+		t.Fatalf("expected %v, got %v", TEAM_CREATION_SOURCE_API, source)
+	}
+
+	r.Header.Set(HEADER_REQUESTED_WITH, HEADER_REQUESTED_WITH_XML)
+	if source := TeamCreationSourceFromRequest(r); source != TEAM_CREATION_SOURCE_UI {
+		t.Fatalf("expected %v, got %v", TEAM_CREATION_SOURCE_UI, source)
+	}
+}
+
+func TestValidateTeamInviteConsistency(t *testing.T) {
+	for _, tc := range []struct {
+		Type            string
+		AllowOpenInvite bool
+		Valid           bool
+	}{
+		{TEAM_OPEN, true, true},
+		{TEAM_OPEN, false, true},
+		{TEAM_INVITE, false, true},
+		{TEAM_INVITE, true, false},
+	} {
+		err := ValidateTeamInviteConsistency(&Team{Type: tc.Type, AllowOpenInvite: tc.AllowOpenInvite})
+		if tc.Valid && err != nil {
+			t.Fatalf("type %v with open invite %v should be valid", tc.Type, tc.AllowOpenInvite)
+		} else if !tc.Valid && err == nil {
+			t.Fatalf("type %v with open invite %v should be invalid", tc.Type, tc.AllowOpenInvite)
+		}
+	}
+}
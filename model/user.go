@@ -172,6 +172,46 @@ func NormalizeEmail(email string) string {
 	return strings.ToLower(email)
 }
 
+const (
+	IDENTIFIER_EMAIL    = "email"
+	IDENTIFIER_ID       = "id"
+	IDENTIFIER_USERNAME = "username"
+)
+
+// ClassifyIdentifier returns the kind of user identifier s looks like: IDENTIFIER_EMAIL if it contains an @,
+// IDENTIFIER_ID if it's a valid id and IDENTIFIER_USERNAME otherwise. Ids are also valid usernames, so an
+// identifier classified as an id may still be a username.
+func ClassifyIdentifier(s string) string {
+	if strings.Contains(s, "@") {
+		return IDENTIFIER_EMAIL
+	} else if IsValidId(s) {
+		return IDENTIFIER_ID
+	}
+	return IDENTIFIER_USERNAME
+}
+
+// NormalizeEmailMailbox normalizes an email address and removes any +tag from the part before the @, so that the
+// result identifies the mailbox the address delivers to. With ignoreDots, dots before the @ are removed as well,
+// as Gmail does, so that john.doe+work@example.com and JohnDoe@example.com give the same result.
+func NormalizeEmailMailbox(s string, ignoreDots bool) string {
+	email := NormalizeEmail(strings.TrimSpace(s))
+
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return email
+	}
+
+	local, domain := email[:at], email[at:]
+	if plus := strings.Index(local, "+"); plus >= 0 {
+		local = local[:plus]
+	}
+	if ignoreDots {
+		local = strings.Replace(local, ".", "", -1)
+	}
+
+	return local + domain
+}
+
 // PreSave will set the Id and Username if missing.  It will also fill
 // in the CreateAt, UpdateAt times.  It will also hash the password.  It should
 // be run before saving the user to the db.
@@ -274,6 +274,43 @@ func TestNormalizeEmail(t *testing.T) {
 	}
 }
 
+func TestNormalizeEmailMailbox(t *testing.T) {
+	for _, tc := range []struct {
+		Email      string
+		IgnoreDots bool
+		Expected   string
+	}{
+		{" Corey@Hulen.com\n", false, "corey@hulen.com"},
+		{"corey+test@hulen.com", false, "corey@hulen.com"},
+		{"corey+test+more@hulen.com", false, "corey@hulen.com"},
+		{"john.doe+work@x.com", false, "john.doe@x.com"},
+		{"john.doe+work@x.com", true, "johndoe@x.com"},
+		{"J.o.h.n.Doe@x.com", true, "johndoe@x.com"},
+		{"john@mail.x.com", true, "john@mail.x.com"},
+		{"\"a@b\"+tag@x.com", false, "\"a@b\"@x.com"},
+		{"no-at-sign+tag", false, "no-at-sign+tag"},
+	} {
+		if normalized := NormalizeEmailMailbox(tc.Email, tc.IgnoreDots); normalized != tc.Expected {
+			t.Fatalf("expected %v to normalize to %v, got %v", tc.Email, tc.Expected, normalized)
+		}
+	}
+}
+
+func TestClassifyIdentifier(t *testing.T) {
+	for identifier, expected := range map[string]string{
+		"corey@hulen.com":  IDENTIFIER_EMAIL,
+		"@corey":           IDENTIFIER_EMAIL,
+		NewId():            IDENTIFIER_ID,
+		"corey":            IDENTIFIER_USERNAME,
+		"corey.hulen-test": IDENTIFIER_USERNAME,
+		"":                 IDENTIFIER_USERNAME,
+	} {
+		if kind := ClassifyIdentifier(identifier); kind != expected {
+			t.Fatalf("expected %q to be classified as %v, got %v", identifier, expected, kind)
+		}
+	}
+}
+
 func TestCleanUsername(t *testing.T) {
 	if CleanUsername("Spin-punch") != "spin-punch" {
 		t.Fatal("didn't clean name properly")
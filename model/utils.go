@@ -5,23 +5,31 @@ package model
 
 import (
 	"bytes"
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base32"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math"
+	"math/big"
+	mathrand "math/rand"
 	"net"
 	"net/http"
 	"net/mail"
 	"net/url"
 	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
-	"testing"
+	"sync"
 	"time"
 	"unicode"
+	"unicode/utf8"
 
 	goi18n "github.com/nicksnyder/go-i18n/i18n"
 	"github.com/pborman/uuid"
@@ -34,54 +42,77 @@ const (
 	SYMBOLS           = " !\"\\#$%&'()*+,-./:;<=>?@[]^_`|~"
 )
 
+// USER_REGEX_MAX_LENGTH caps the length of regular expressions given by users, such as in command flags, so
+// that a pattern can't make matching needlessly expensive.
+const USER_REGEX_MAX_LENGTH = 512
+
 type StringInterface map[string]interface{}
 type StringMap map[string]string
 type StringArray []string
 
 var translateFunc goi18n.TranslateFunc = nil
 
+// AppErrorMaxRunes caps the length of the Message and DetailedError of errors created by
+// NewAppError, since they are sometimes built from user input.
+var AppErrorMaxRunes = 4000
+
 func AppErrorInit(t goi18n.TranslateFunc) {
 	translateFunc = t
 }
 
 type AppError struct {
-	Id            string `json:"id"`
-	Message       string `json:"message"`               // Message to be display to the end user without debugging information
-	DetailedError string `json:"detailed_error"`        // Internal error string to help the developer
-	RequestId     string `json:"request_id,omitempty"`  // The RequestId that's also set in the header
-	StatusCode    int    `json:"status_code,omitempty"` // The http status code
-	Where         string `json:"-"`                     // The function where it happened in the form of Struct.Func
-	IsOAuth       bool   `json:"is_oauth,omitempty"`    // Whether the error is OAuth specific
-	params        map[string]interface{}
+	Id            string                 `json:"id"`
+	Message       string                 `json:"message"`               // Message to be display to the end user without debugging information
+	DetailedError string                 `json:"detailed_error"`        // Internal error string to help the developer
+	RequestId     string                 `json:"request_id,omitempty"`  // The RequestId that's also set in the header
+	StatusCode    int                    `json:"status_code,omitempty"` // The http status code
+	Where         string                 `json:"-"`                     // The function where it happened in the form of Struct.Func
+	IsOAuth       bool                   `json:"is_oauth,omitempty"`    // Whether the error is OAuth specific
+	Params        map[string]interface{} `json:"params,omitempty"`      // The parameters used to translate the Message, cleared like DetailedError before reaching clients
+	wrapped       error
 }
 
 func (er *AppError) Error() string {
 	return er.Where + ": " + er.Message + ", " + er.DetailedError
 }
 
+// Unwrap returns the error the AppError was created from, if any, so that errors.Is and errors.As can match it.
+// The wrapped error isn't serialized by ToJson.
+func (er *AppError) Unwrap() error {
+	return er.wrapped
+}
+
+// Translate renders the Message from the Id and Params using T, or sets it to the Id when T is nil. It can be
+// called again on an error read with AppErrorFromJson to render it in another language.
 func (er *AppError) Translate(T goi18n.TranslateFunc) {
 	if T == nil {
 		er.Message = er.Id
 		return
 	}
 
-	if er.params == nil {
+	if er.Params == nil {
 		er.Message = T(er.Id)
 	} else {
-		er.Message = T(er.Id, er.params)
+		er.Message = T(er.Id, er.Params)
 	}
 }
 
 func (er *AppError) SystemMessage(T goi18n.TranslateFunc) string {
-	if er.params == nil {
+	if er.Params == nil {
 		return T(er.Id)
 	} else {
-		return T(er.Id, er.params)
+		return T(er.Id, er.Params)
 	}
 }
 
 func (er *AppError) ToJson() string {
-	b, _ := json.Marshal(er)
+	b, err := json.Marshal(er)
+	if err != nil && er.Params != nil {
+		// Params can't always be serialized, but the rest of the error still can
+		withoutParams := *er
+		withoutParams.Params = nil
+		b, _ = json.Marshal(&withoutParams)
+	}
 	return string(b)
 }
 
@@ -95,6 +126,10 @@ func AppErrorFromJson(data io.Reader) *AppError {
 		str = string(bytes)
 	}
 
+	if !LooksLikeJson([]byte(str)) {
+		return NewAppError("AppErrorFromJson", "model.utils.decode_json.not_json.app_error", nil, "body: "+str, http.StatusInternalServerError)
+	}
+
 	decoder := json.NewDecoder(strings.NewReader(str))
 	var er AppError
 	err := decoder.Decode(&er)
@@ -105,42 +140,164 @@ func AppErrorFromJson(data io.Reader) *AppError {
 	}
 }
 
+// LooksLikeJson cheaply checks whether data could be a JSON object or array by looking at its
+// first non-whitespace byte. It does not validate the rest of the document.
+func LooksLikeJson(data []byte) bool {
+	trimmed := bytes.TrimLeft(data, " \t\r\n")
+	return len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[')
+}
+
 func NewAppError(where string, id string, params map[string]interface{}, details string, status int) *AppError {
 	ap := &AppError{}
 	ap.Id = id
-	ap.params = params
+	ap.Params = params
 	ap.Message = id
 	ap.Where = where
 	ap.DetailedError = details
 	ap.StatusCode = status
 	ap.IsOAuth = false
 	ap.Translate(translateFunc)
+	ap.Message = TruncateRunes(ap.Message, AppErrorMaxRunes)
+	ap.DetailedError = TruncateRunes(ap.DetailedError, AppErrorMaxRunes)
 	return ap
 }
 
-var encoding = base32.NewEncoding("ybndrfg8ejkmcpqxot1uwisza345h769")
+// NewAppErrorWrap is like NewAppError, but keeps err as the wrapped cause and uses its message as the
+// detailed error.
+func NewAppErrorWrap(where string, id string, params map[string]interface{}, err error, status int) *AppError {
+	details := ""
+	if err != nil {
+		details = err.Error()
+	}
+
+	ap := NewAppError(where, id, params, details, status)
+	ap.wrapped = err
+	return ap
+}
+
+// AsAppError returns err as an *AppError, wrapping errors of other types in an internal server error that keeps
+// their message as the detailed error. It returns nil for a nil err, including a nil *AppError.
+func AsAppError(err error) *AppError {
+	if err == nil {
+		return nil
+	}
+
+	if appErr, ok := err.(*AppError); ok {
+		return appErr
+	}
+
+	return NewAppErrorWrap("AsAppError", "model.utils.as_app_error.app_error", nil, err, http.StatusInternalServerError)
+}
+
+// TruncateRunes shortens s to at most max runes, replacing the last rune kept with an ellipsis
+// when anything had to be cut off.
+func TruncateRunes(s string, max int) string {
+	if max <= 0 {
+		return ""
+	}
+
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+
+	runes := []rune(s)
+	return string(runes[:max-1]) + "…"
+}
+
+// ID_CHARSET holds the characters used in ids and random strings.
+const ID_CHARSET = "ybndrfg8ejkmcpqxot1uwisza345h769"
+
+var encoding = base32.NewEncoding(ID_CHARSET)
 
 // NewId is a globally unique identifier.  It is a [A-Z0-9] string 26
 // characters long.  It is a UUID version 4 Guid that is zbased32 encoded
 // with the padding stripped off.
 func NewId() string {
+	return NewIdN(26)
+}
+
+// NewIdN returns a random identifier of the given length using the same alphabet as NewId. Longer ids are
+// made by joining several encoded UUIDs. Shorter ids collide more easily since each character only carries
+// 5 random bits, so collisions become likely after roughly 2^(5*length/2) ids, about 33 million for 10
+// characters compared to around 2^61 for a full id.
+func NewIdN(length int) string {
+	if length <= 0 {
+		return ""
+	}
+
+	var id bytes.Buffer
+	for id.Len() < length {
+		var b bytes.Buffer
+		encoder := base32.NewEncoder(encoding, &b)
+		encoder.Write(uuid.NewRandom())
+		encoder.Close()
+		b.Truncate(26) // removes the '==' padding
+		id.Write(b.Bytes())
+	}
+	id.Truncate(length)
+	return id.String()
+}
+
+// AnonymizeId returns a pseudonym for the given id that is itself a valid 26 character id. The
+// result is deterministic for a given salt, so references to the same id stay consistent across an
+// export, while a different salt produces unrelated pseudonyms.
+func AnonymizeId(id string, salt string) string {
+	mac := hmac.New(sha256.New, []byte(salt))
+	mac.Write([]byte(id))
+
 	var b bytes.Buffer
 	encoder := base32.NewEncoder(encoding, &b)
-	encoder.Write(uuid.NewRandom())
+	encoder.Write(mac.Sum(nil))
 	encoder.Close()
-	b.Truncate(26) // removes the '==' padding
+	b.Truncate(26)
 	return b.String()
 }
 
-func NewRandomString(length int) string {
+// CacheKey returns a stable key for caching the results of a query in the given namespace. The key depends
+// only on the contents of params, not on the order in which they were added.
+func CacheKey(namespace string, params map[string]string) string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	hash := sha256.New()
+	for _, key := range keys {
+		// Length prefixes keep pairs like {"a": "b=c"} and {"a=b": "c"} from hashing the same.
+		fmt.Fprintf(hash, "%d:%s%d:%s", len(key), key, len(params[key]), params[key])
+	}
+
 	var b bytes.Buffer
-	str := make([]byte, length+8)
-	rand.Read(str)
 	encoder := base32.NewEncoder(encoding, &b)
-	encoder.Write(str)
+	encoder.Write(hash.Sum(nil))
 	encoder.Close()
-	b.Truncate(length) // removes the '==' padding
-	return b.String()
+	b.Truncate(26)
+	return namespace + ":" + b.String()
+}
+
+func NewRandomString(length int) string {
+	return NewRandomStringFromCharset(length, ID_CHARSET)
+}
+
+// NewRandomStringFromCharset returns a string of the given length made of characters picked uniformly at
+// random from charset using crypto/rand. It panics if charset is empty.
+func NewRandomStringFromCharset(length int, charset string) string {
+	chars := []rune(charset)
+	if len(chars) == 0 {
+		panic("NewRandomStringFromCharset: empty charset")
+	}
+
+	max := big.NewInt(int64(len(chars)))
+	str := make([]rune, length)
+	for i := range str {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			panic(err)
+		}
+		str[i] = chars[n.Int64()]
+	}
+	return string(str)
 }
 
 // GetMillis is a convience method to get milliseconds since epoch.
@@ -148,12 +305,77 @@ func GetMillis() int64 {
 	return time.Now().UnixNano() / int64(time.Millisecond)
 }
 
+// Page holds one page of a paged result along with the position of the page in the full result.
+type Page struct {
+	Items  interface{} `json:"items"`
+	Offset int         `json:"offset"`
+	Limit  int         `json:"limit"`
+	Total  int         `json:"total"`
+}
+
+// HasNext reports whether there are results beyond the end of this page.
+func (p *Page) HasNext() bool {
+	return p.Limit > 0 && p.Offset+p.Limit < p.Total
+}
+
+// ToJson converts the page, including its items, to a json string.
+func (p *Page) ToJson() string {
+	b, _ := json.Marshal(p)
+	return string(b)
+}
+
 // MapToJson converts a map to a json string
 func MapToJson(objmap map[string]string) string {
 	b, _ := json.Marshal(objmap)
 	return string(b)
 }
 
+// MapToSortedJson converts a map to a json string with its keys in sorted order, so that equal maps always give
+// the same string. encoding/json already sorts map keys, so this is the same as MapToJson, but callers that
+// depend on the order, such as when hashing, should use it to make that explicit.
+func MapToSortedJson(objmap map[string]string) string {
+	return MapToJson(objmap)
+}
+
+// SortChannelsStable sorts channels in place by creation time and then by id, so that the same channels are
+// always put in the same order regardless of how the store returned them.
+func SortChannelsStable(channels []*Channel) {
+	sort.Slice(channels, func(i, j int) bool {
+		if channels[i].CreateAt != channels[j].CreateAt {
+			return channels[i].CreateAt < channels[j].CreateAt
+		}
+		return channels[i].Id < channels[j].Id
+	})
+}
+
+// DiffTeams returns the mutable fields that differ between old and new, keyed by their json name, with the
+// old and new values. A nil team is treated as having empty fields.
+func DiffTeams(old, new *Team) map[string][2]string {
+	if old == nil {
+		old = &Team{}
+	}
+	if new == nil {
+		new = &Team{}
+	}
+
+	diff := map[string][2]string{}
+	for _, field := range []struct {
+		Name     string
+		Old, New string
+	}{
+		{"display_name", old.DisplayName, new.DisplayName},
+		{"type", old.Type, new.Type},
+		{"description", old.Description, new.Description},
+		{"allowed_domains", old.AllowedDomains, new.AllowedDomains},
+	} {
+		if field.Old != field.New {
+			diff[field.Name] = [2]string{field.Old, field.New}
+		}
+	}
+
+	return diff
+}
+
 // MapToJson converts a map to a json string
 func MapBoolToJson(objmap map[string]bool) string {
 	b, _ := json.Marshal(objmap)
@@ -249,33 +471,501 @@ func StringFromJson(data io.Reader) string {
 	}
 }
 
+// GetServerIpAddress returns the first IPv4 address of the server, or its first IPv6 address on hosts without
+// one. Loopback and link-local addresses are skipped.
 func GetServerIpAddress() string {
-	if addrs, err := net.InterfaceAddrs(); err != nil {
-		return ""
-	} else {
+	return preferredIpAddress(GetServerIpAddresses())
+}
+
+// preferredIpAddress returns the first IPv4 address in addresses, or the first address if none is IPv4.
+func preferredIpAddress(addresses []string) string {
+	for _, address := range addresses {
+		if ip := net.ParseIP(address); ip != nil && ip.To4() != nil {
+			return address
+		}
+	}
+
+	if len(addresses) > 0 {
+		return addresses[0]
+	}
+
+	return ""
+}
+
+type InterfaceAddress struct {
+	Interface string `json:"interface"`
+	Address   string `json:"address"`
+}
+
+// GetServerInterfaceAddresses returns the IPv4 and IPv6 addresses of the server's network interfaces along with
+// the name of the interface each is assigned to. Loopback and link-local addresses are skipped.
+func GetServerInterfaceAddresses() []InterfaceAddress {
+	addresses := []InterfaceAddress{}
+
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return addresses
+	}
+
+	for _, iface := range interfaces {
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+
 		for _, addr := range addrs {
+			ip, ok := addr.(*net.IPNet)
+			if !ok || ip.IP.IsLoopback() || ip.IP.IsLinkLocalUnicast() {
+				continue
+			}
 
-			if ip, ok := addr.(*net.IPNet); ok && !ip.IP.IsLoopback() {
-				if ip.IP.To4() != nil {
-					return ip.IP.String()
-				}
+			addresses = append(addresses, InterfaceAddress{Interface: iface.Name, Address: ip.IP.String()})
+		}
+	}
+
+	return addresses
+}
+
+// GetServerIpAddresses returns the addresses found by GetServerInterfaceAddresses.
+func GetServerIpAddresses() []string {
+	addresses := []string{}
+	for _, address := range GetServerInterfaceAddresses() {
+		addresses = append(addresses, address.Address)
+	}
+	return addresses
+}
+
+// ParseKeyValuePairs parses arguments of the form key=value into a map. Only the first = separates
+// the key from the value, so values may contain = themselves, and a value wrapped in matching single
+// or double quotes has them removed.
+func ParseKeyValuePairs(args []string) (map[string]string, error) {
+	pairs := make(map[string]string, len(args))
+
+	for _, arg := range args {
+		index := strings.Index(arg, "=")
+		if index < 0 {
+			return nil, fmt.Errorf("invalid key=value pair %q: missing =", arg)
+		}
+
+		key := strings.TrimSpace(arg[:index])
+		if key == "" {
+			return nil, fmt.Errorf("invalid key=value pair %q: missing key", arg)
+		}
+
+		value := arg[index+1:]
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') {
+			if value[len(value)-1] != value[0] {
+				return nil, fmt.Errorf("invalid key=value pair %q: unterminated quote", arg)
 			}
+			value = value[1 : len(value)-1]
+		} else if value == "\"" || value == "'" {
+			return nil, fmt.Errorf("invalid key=value pair %q: unterminated quote", arg)
 		}
+
+		pairs[key] = value
 	}
 
-	return ""
+	return pairs, nil
+}
+
+// ParseSortSpec parses a sort specification such as "name" or "-name", where a leading - sorts in descending
+// order. The field is matched against allowed ignoring case and returned as it's spelled in allowed.
+func ParseSortSpec(s string, allowed []string) (field string, desc bool, err error) {
+	spec := strings.TrimSpace(s)
+	if strings.HasPrefix(spec, "-") {
+		desc = true
+		spec = spec[1:]
+	}
+
+	for _, candidate := range allowed {
+		if strings.EqualFold(spec, candidate) {
+			return candidate, desc, nil
+		}
+	}
+
+	return "", false, fmt.Errorf("invalid sort field %q, expected one of %v", s, strings.Join(allowed, ", "))
+}
+
+// CompileUserRegex compiles a regular expression given by a user, rejecting patterns longer than
+// USER_REGEX_MAX_LENGTH. It's meant to be called while validating input, so that a bad pattern is reported
+// before any work is done.
+func CompileUserRegex(pattern string) (*regexp.Regexp, error) {
+	if len(pattern) > USER_REGEX_MAX_LENGTH {
+		return nil, fmt.Errorf("pattern is %v characters long, the maximum is %v", len(pattern), USER_REGEX_MAX_LENGTH)
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %v", pattern, err)
+	}
+
+	return re, nil
+}
+
+// DiffIdSlices returns the ids in desired that are missing from current, and the ids in current that are
+// missing from desired. Both results keep the order in which the ids first appear and contain no duplicates.
+func DiffIdSlices(current, desired []string) (toAdd, toRemove []string) {
+	inCurrent := make(map[string]bool, len(current))
+	for _, id := range current {
+		inCurrent[id] = true
+	}
+
+	inDesired := make(map[string]bool, len(desired))
+	for _, id := range desired {
+		inDesired[id] = true
+	}
+
+	toAdd = []string{}
+	for _, id := range desired {
+		if !inCurrent[id] {
+			toAdd = append(toAdd, id)
+			inCurrent[id] = true
+		}
+	}
+
+	toRemove = []string{}
+	for _, id := range current {
+		if !inDesired[id] {
+			toRemove = append(toRemove, id)
+			inDesired[id] = true
+		}
+	}
+
+	return toAdd, toRemove
+}
+
+// RequireKeys returns the required keys that are missing from keys, in the order they were required.
+func RequireKeys(keys []string, required ...string) []string {
+	present := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		present[key] = true
+	}
+
+	missing := []string{}
+	for _, key := range required {
+		if !present[key] {
+			missing = append(missing, key)
+		}
+	}
+
+	return missing
 }
 
 func IsLower(s string) bool {
 	return strings.ToLower(s) == s
 }
 
+// FormatPercent returns part as a percentage of total with one decimal place, such as "12.5%". A total of
+// zero is reported as "0.0%".
+func FormatPercent(part, total int64) string {
+	if total == 0 {
+		return "0.0%"
+	}
+	return strconv.FormatFloat(float64(part)*100/float64(total), 'f', 1, 64) + "%"
+}
+
+var byteSizeUnits = map[string]int64{
+	"":    1,
+	"B":   1,
+	"KB":  1000,
+	"MB":  1000 * 1000,
+	"GB":  1000 * 1000 * 1000,
+	"TB":  1000 * 1000 * 1000 * 1000,
+	"KIB": 1 << 10,
+	"MIB": 1 << 20,
+	"GIB": 1 << 30,
+	"TIB": 1 << 40,
+}
+
+// ParseByteSize parses a size such as "512", "10MB" or "1.5GiB" into a number of bytes. The KB, MB, GB and
+// TB suffixes are decimal and the KiB, MiB, GiB and TiB suffixes are binary. Suffixes are case-insensitive.
+func ParseByteSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+
+	i := 0
+	for i < len(s) && (s[i] >= '0' && s[i] <= '9' || s[i] == '.') {
+		i++
+	}
+
+	number, unit := s[:i], strings.ToUpper(strings.TrimSpace(s[i:]))
+	multiplier, ok := byteSizeUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("invalid size %q: unknown unit %q", s, s[i:])
+	}
+
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+
+	size := value * float64(multiplier)
+	if size >= math.MaxInt64 {
+		return 0, fmt.Errorf("invalid size %q: too large", s)
+	}
+
+	return int64(size), nil
+}
+
+// FormatBytes returns a number of bytes in the largest binary unit that keeps the value at least 1, with at
+// most one decimal place, such as "512B" or "1.5GiB". The result can be read back with ParseByteSize.
+func FormatBytes(size int64) string {
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB"}
+
+	value := float64(size)
+	unit := 0
+	for unit < len(units)-1 && (value >= 1024 || value <= -1024) {
+		value /= 1024
+		unit++
+	}
+
+	return strconv.FormatFloat(math.Round(value*10)/10, 'f', -1, 64) + units[unit]
+}
+
+var extendedDurationUnits = map[byte]time.Duration{
+	'w': 7 * 24 * time.Hour,
+	'd': 24 * time.Hour,
+}
+
+// ParseExtendedDuration parses a duration like time.ParseDuration but also accepts whole numbers of weeks and
+// days at the start, such as "90d", "2w" or "1d12h".
+func ParseExtendedDuration(s string) (time.Duration, error) {
+	rest := strings.TrimSpace(s)
+
+	var duration time.Duration
+	for {
+		i := 0
+		for i < len(rest) && rest[i] >= '0' && rest[i] <= '9' {
+			i++
+		}
+		if i == 0 || i == len(rest) {
+			break
+		}
+
+		unit, ok := extendedDurationUnits[rest[i]]
+		if !ok {
+			break
+		}
+
+		value, err := strconv.ParseInt(rest[:i], 10, 64)
+		if err != nil || value > int64(math.MaxInt64/unit) {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		duration += time.Duration(value) * unit
+		rest = rest[i+1:]
+	}
+
+	if rest == "" {
+		if duration == 0 && strings.TrimSpace(s) == "" {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return duration, nil
+	}
+
+	remainder, err := time.ParseDuration(rest)
+	if err != nil || remainder < 0 && duration > 0 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+
+	return duration + remainder, nil
+}
+
+var (
+	backoffRand      = mathrand.New(mathrand.NewSource(time.Now().UnixNano()))
+	backoffRandMutex sync.Mutex
+)
+
+// BackoffSchedule returns the delays to wait before each of attempts retries. The delays double from base and
+// are capped at max, and each one is randomly reduced by up to half so that clients retrying together spread out.
+// The schedule is empty when attempts isn't positive.
+func BackoffSchedule(attempts int, base, max time.Duration) []time.Duration {
+	if attempts < 0 {
+		attempts = 0
+	}
+
+	backoffRandMutex.Lock()
+	defer backoffRandMutex.Unlock()
+
+	schedule := make([]time.Duration, 0, attempts)
+	delay := base
+	for i := 0; i < attempts; i++ {
+		if delay > max || delay <= 0 {
+			delay = max
+		}
+
+		jitter := time.Duration(0)
+		if half := int64(delay / 2); half > 0 {
+			jitter = time.Duration(backoffRand.Int63n(half + 1))
+		}
+		schedule = append(schedule, delay-jitter)
+
+		delay *= 2
+	}
+
+	return schedule
+}
+
+// RetryWithBackoff calls fn until it succeeds or has been called attempts times, waiting between calls according
+// to BackoffSchedule. fn is always called at least once. It returns the error from the last call.
+func RetryWithBackoff(attempts int, base, max time.Duration, fn func() error) error {
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	schedule := BackoffSchedule(attempts-1, base, max)
+
+	var err error
+	for i := 0; i < attempts; i++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if i < len(schedule) {
+			time.Sleep(schedule[i])
+		}
+	}
+
+	return err
+}
+
+// CopyLimitedWithChecksum copies src to dst and returns the number of bytes written along with the hex
+// encoded SHA-256 of them. It fails without writing more than max bytes if src holds more than that, and
+// without writing anything if max is negative.
+func CopyLimitedWithChecksum(dst io.Writer, src io.Reader, max int64) (written int64, sum string, err error) {
+	if max < 0 {
+		return 0, "", fmt.Errorf("invalid limit of %v bytes", max)
+	}
+
+	hash := sha256.New()
+
+	written, err = io.Copy(io.MultiWriter(dst, hash), io.LimitReader(src, max))
+	if err != nil {
+		return written, "", err
+	}
+
+	if written == max {
+		if n, _ := io.ReadFull(src, make([]byte, 1)); n > 0 {
+			return written, "", fmt.Errorf("the data is larger than the limit of %v bytes", max)
+		}
+	}
+
+	return written, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// FindSlugConflicts returns each slug that appears more than once in slugs once canonicalized with
+// CanonicalizeSlug, mapped to the indexes in slugs at which it appears, so that a batch of names can be
+// checked against itself before any of them are saved. Blank slugs are ignored.
+func FindSlugConflicts(slugs []string) map[string][]int {
+	positions := map[string][]int{}
+	for i, slug := range slugs {
+		if canonical := CanonicalizeSlug(slug); canonical != "" {
+			positions[canonical] = append(positions[canonical], i)
+		}
+	}
+
+	conflicts := map[string][]int{}
+	for slug, indexes := range positions {
+		if len(indexes) > 1 {
+			conflicts[slug] = indexes
+		}
+	}
+
+	return conflicts
+}
+
+// UniqueSlug returns base if exists reports that it's free, or otherwise the first of base-2, base-3 and so on
+// that is. The base is truncated so that the result is never longer than TEAM_NAME_MAX_LENGTH.
+func UniqueSlug(base string, exists func(string) bool) string {
+	if len(base) > TEAM_NAME_MAX_LENGTH {
+		base = base[:TEAM_NAME_MAX_LENGTH]
+	}
+	if !exists(base) {
+		return base
+	}
+
+	for n := 2; ; n++ {
+		suffix := "-" + strconv.Itoa(n)
+
+		prefix := base
+		if len(prefix)+len(suffix) > TEAM_NAME_MAX_LENGTH {
+			prefix = strings.TrimRight(prefix[:TEAM_NAME_MAX_LENGTH-len(suffix)], "-")
+		}
+
+		if candidate := prefix + suffix; !exists(candidate) {
+			return candidate
+		}
+	}
+}
+
+// IsBlank reports whether s is empty or contains only whitespace.
+func IsBlank(s string) bool {
+	return strings.TrimSpace(s) == ""
+}
+
+// windows1252Bytes maps the characters that Windows-1252 assigns to the bytes 0x80 to 0x9F back to those bytes.
+var windows1252Bytes = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87, 'ˆ': 0x88,
+	'‰': 0x89, 'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E, '‘': 0x91, '’': 0x92, '“': 0x93,
+	'”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '˜': 0x98, '™': 0x99, 'š': 0x9A, '›': 0x9B,
+	'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+// undoMojibake reverses a decoding of UTF-8 bytes as Windows-1252 or Latin-1. It returns false if s contains
+// characters that can't come from such a decoding or if the original bytes aren't valid UTF-8.
+func undoMojibake(s string) (string, bool) {
+	b := make([]byte, 0, len(s))
+	for _, r := range s {
+		if r <= 0xFF {
+			b = append(b, byte(r))
+		} else if c, ok := windows1252Bytes[r]; ok {
+			b = append(b, c)
+		} else {
+			return "", false
+		}
+	}
+
+	if !utf8.Valid(b) {
+		return "", false
+	}
+
+	return string(b), true
+}
+
+// LooksLikeMojibake reports whether s appears to be UTF-8 text that was mistakenly decoded as Windows-1252
+// or Latin-1 and then encoded as UTF-8 again, such as "CafÃ©" for "Café" or "donâ€™t" for "don’t".
+func LooksLikeMojibake(s string) bool {
+	fixed, ok := undoMojibake(s)
+	return ok && fixed != s
+}
+
+// FixMojibake makes a best effort at restoring text that was double encoded as described in LooksLikeMojibake.
+// Text that doesn't look double encoded is returned unchanged.
+func FixMojibake(s string) string {
+	if fixed, ok := undoMojibake(s); ok {
+		return fixed
+	}
+	return s
+}
+
+// CollapseWhitespace trims leading and trailing whitespace and replaces any internal run of
+// whitespace with a single space.
+func CollapseWhitespace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
 func IsValidEmail(email string) bool {
 
 	if !IsLower(email) {
 		return false
 	}
 
+	// Some mail relays bounce addresses whose unquoted local part starts or ends with a dot or has two dots in
+	// a row, and older versions of net/mail accept them, so check for them explicitly.
+	if at := strings.LastIndex(email, "@"); at >= 0 {
+		local := email[:at]
+		if !strings.HasPrefix(local, `"`) && (strings.HasPrefix(local, ".") || strings.HasSuffix(local, ".") || strings.Contains(local, "..")) {
+			return false
+		}
+	}
+
 	if _, err := mail.ParseAddress(email); err == nil {
 		return true
 	}
@@ -322,40 +1012,77 @@ func IsValidAlphaNumHyphenUnderscore(s string, withFormat bool) bool {
 	return validSimpleAlphaNumHyphenUnderscore.MatchString(s)
 }
 
+var validHexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6}|[0-9a-fA-F]{8})$`)
+
+// IsValidHexColor reports whether s is a color in the #RGB, #RRGGBB or #RRGGBBAA form, in either case.
+func IsValidHexColor(s string) bool {
+	return validHexColor.MatchString(s)
+}
+
+// NormalizeTimezone returns the validated name of the IANA timezone s, such as America/New_York or UTC, with
+// surrounding spaces trimmed. The name is otherwise returned as given, not converted to a canonical zone, so an
+// alias such as US/Eastern stays as it is. Empty strings and Local, which time.LoadLocation accepts but which
+// depend on the machine, are rejected.
+func NormalizeTimezone(s string) (string, error) {
+	name := strings.TrimSpace(s)
+	if name == "" || name == "Local" {
+		return "", fmt.Errorf("invalid timezone %q", s)
+	}
+
+	if _, err := time.LoadLocation(name); err != nil {
+		return "", fmt.Errorf("invalid timezone %q", s)
+	}
+	return name, nil
+}
+
+// IsValidTimezone reports whether s is an IANA timezone name that NormalizeTimezone accepts.
+func IsValidTimezone(s string) bool {
+	_, err := NormalizeTimezone(s)
+	return err == nil
+}
+
 func Etag(parts ...interface{}) string {
 
 	etag := CurrentVersion
 
 	for _, part := range parts {
-		etag += fmt.Sprintf(".%v", part)
+		if m, ok := part.(map[string]string); ok {
+			etag += "." + MapToSortedJson(m)
+		} else {
+			etag += fmt.Sprintf(".%v", part)
+		}
 	}
 
 	return etag
 }
 
+// WeakEtag returns the Etag of parts as a weak validator, as described in RFC 7232, for responses whose
+// content is only semantically equivalent between requests, such as compressed or transformed content.
+func WeakEtag(parts ...interface{}) string {
+	return `W/"` + Etag(parts...) + `"`
+}
+
+// IsWeakEtag reports whether s is a weak entity tag.
+func IsWeakEtag(s string) bool {
+	return len(s) >= 4 && strings.HasPrefix(s, `W/"`) && strings.HasSuffix(s, `"`)
+}
+
 var validHashtag = regexp.MustCompile(`^(#\pL[\pL\d\-_.]*[\pL\d])$`)
 var puncStart = regexp.MustCompile(`^[^\pL\d\s#]+`)
 var hashtagStart = regexp.MustCompile(`^#{2,}`)
 var puncEnd = regexp.MustCompile(`[^\pL\d\s]+$`)
+var emojiHashtag = regexp.MustCompile(`^(#+(?:\pL[\pL\d\-_.]*[\pL\d])?:[a-zA-Z0-9\-+_]+:)[^\pL\d\s]*$`)
 
+// ParseHashtags returns the hashtags found in text joined by spaces, followed by the rest of the words of text.
+// A hashtag is a # followed by a letter and then letters, digits, -, _ or ., ending with a letter or digit, such
+// as #bug or #v1.2-beta. It may also end with an emoji shortcode, as in #win:tada:, or be a shortcode alone, as
+// in #:fire:. Punctuation around a hashtag and repeated #s before it are ignored.
 func ParseHashtags(text string) (string, string) {
-	words := strings.Fields(text)
+	matches, plainString := ParseHashtagsWithOffsets(text)
 
 	hashtagString := ""
-	plainString := ""
-	for _, word := range words {
-		// trim off surrounding punctuation
-		word = puncStart.ReplaceAllString(word, "")
-		word = puncEnd.ReplaceAllString(word, "")
-
-		// and remove extra pound #s
-		word = hashtagStart.ReplaceAllString(word, "#")
-
-		if validHashtag.MatchString(word) {
-			hashtagString += " " + word
-		} else {
-			plainString += " " + word
-		}
+	for _, match := range matches {
+		hashtagString += " " + match.Tag
 	}
 
 	if len(hashtagString) > 1000 {
@@ -368,7 +1095,63 @@ func ParseHashtags(text string) (string, string) {
 		}
 	}
 
-	return strings.TrimSpace(hashtagString), strings.TrimSpace(plainString)
+	return strings.TrimSpace(hashtagString), plainString
+}
+
+// HashtagMatch is a hashtag found in a message. Start and End are the byte offsets of the tag in the message,
+// so that message[Start:End] == Tag.
+type HashtagMatch struct {
+	Tag   string `json:"tag"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+// ParseHashtagsWithOffsets is like ParseHashtags, but returns every hashtag along with its position in text
+// instead of joining them into a single string.
+func ParseHashtagsWithOffsets(text string) ([]HashtagMatch, string) {
+	matches := []HashtagMatch{}
+	plainString := ""
+
+	start := -1
+	for i, r := range text + " " {
+		if !unicode.IsSpace(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		} else if start < 0 {
+			continue
+		}
+
+		word := text[start:i]
+		offset := start
+		start = -1
+
+		// trim off surrounding punctuation
+		trimmed := puncStart.FindString(word)
+		word = word[len(trimmed):]
+		offset += len(trimmed)
+		emoji := emojiHashtag.FindStringSubmatch(word)
+		if emoji != nil {
+			word = emoji[1]
+		} else {
+			word = puncEnd.ReplaceAllString(word, "")
+		}
+
+		// and remove extra pound #s
+		if extra := hashtagStart.FindString(word); extra != "" {
+			word = word[len(extra)-1:]
+			offset += len(extra) - 1
+		}
+
+		if emoji != nil || validHashtag.MatchString(word) {
+			matches = append(matches, HashtagMatch{Tag: word, Start: offset, End: offset + len(word)})
+		} else {
+			plainString += " " + word
+		}
+	}
+
+	return matches, strings.TrimSpace(plainString)
 }
 
 func IsFileExtImage(ext string) bool {
@@ -472,9 +1255,15 @@ func IsValidId(value string) bool {
 	return true
 }
 
-// checkNowhereNil checks that the given interface value is not nil, and if a struct, that all of
-// its public fields are also nowhere nil
-func checkNowhereNil(t *testing.T, name string, value interface{}) bool {
+// NowhereNil reports whether the given value is not nil and, for pointers and structs, whether every exported
+// field reachable from it is also not nil. Nil maps and struct pointers are considered nil, while nil slices
+// aren't since they behave like empty ones. Map values and unexported fields aren't checked.
+func NowhereNil(value interface{}) bool {
+	return nowhereNil(func(string, ...interface{}) {}, "value", value)
+}
+
+// nowhereNil implements NowhereNil, calling logf with the path to the first nil value it finds.
+func nowhereNil(logf func(format string, args ...interface{}), name string, value interface{}) bool {
 	if value == nil {
 		return false
 	}
@@ -483,15 +1272,15 @@ func checkNowhereNil(t *testing.T, name string, value interface{}) bool {
 	switch v.Type().Kind() {
 	case reflect.Ptr:
 		if v.IsNil() {
-			t.Logf("%s was nil", name)
+			logf("%s was nil", name)
 			return false
 		}
 
-		return checkNowhereNil(t, fmt.Sprintf("(*%s)", name), v.Elem().Interface())
+		return nowhereNil(logf, fmt.Sprintf("(*%s)", name), v.Elem().Interface())
 
 	case reflect.Map:
 		if v.IsNil() {
-			t.Logf("%s was nil", name)
+			logf("%s was nil", name)
 			return false
 		}
 
@@ -499,7 +1288,7 @@ func checkNowhereNil(t *testing.T, name string, value interface{}) bool {
 		return true
 
 	case reflect.Struct:
-		nowhereNil := true
+		ok := true
 		for i := 0; i < v.NumField(); i++ {
 			f := v.Field(i)
 			// Ignore unexported fields
@@ -507,10 +1296,10 @@ func checkNowhereNil(t *testing.T, name string, value interface{}) bool {
 				continue
 			}
 
-			nowhereNil = nowhereNil && checkNowhereNil(t, fmt.Sprintf("%s.%s", name, v.Type().Field(i).Name), f.Interface())
+			ok = ok && nowhereNil(logf, fmt.Sprintf("%s.%s", name, v.Type().Field(i).Name), f.Interface())
 		}
 
-		return nowhereNil
+		return ok
 
 	case reflect.Array:
 		fallthrough
@@ -521,7 +1310,7 @@ func checkNowhereNil(t *testing.T, name string, value interface{}) bool {
 	case reflect.Interface:
 		fallthrough
 	case reflect.UnsafePointer:
-		t.Logf("unhandled field %s, type: %s", name, v.Type().Kind())
+		logf("unhandled field %s, type: %s", name, v.Type().Kind())
 		return false
 
 	default:
@@ -4,9 +4,19 @@
 package model
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	mathrand "math/rand"
+	"net"
 	"net/http"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
+	"unicode/utf8"
 
 	"github.com/stretchr/testify/require"
 )
@@ -20,13 +30,79 @@ func TestNewId(t *testing.T) {
 	}
 }
 
+func TestNewIdN(t *testing.T) {
+	for _, length := range []int{1, 10, 26, 27, 60} {
+		seen := map[string]bool{}
+		for i := 0; i < 100; i++ {
+			id := NewIdN(length)
+			require.Len(t, id, length)
+			require.Regexp(t, `^[a-z0-9]+$`, id)
+			if length >= 10 {
+				require.False(t, seen[id], "ids of length %v shouldn't repeat", length)
+			}
+			seen[id] = true
+		}
+	}
+
+	require.Equal(t, "", NewIdN(0))
+	require.Equal(t, "", NewIdN(-1))
+	require.True(t, IsValidId(NewIdN(26)))
+}
+
 func TestRandomString(t *testing.T) {
 	for i := 0; i < 1000; i++ {
 		r := NewRandomString(32)
 		if len(r) != 32 {
 			t.Fatal("should be 32 chars")
 		}
+		if strings.Trim(r, ID_CHARSET) != "" {
+			t.Fatalf("%v should only contain id characters", r)
+		}
+	}
+
+	charset := LOWERCASE_LETTERS + UPPERCASE_LETTERS + NUMBERS
+	mixedCase := false
+	for i := 0; i < 100; i++ {
+		r := NewRandomStringFromCharset(32, charset)
+		require.Len(t, r, 32)
+		require.Equal(t, "", strings.Trim(r, charset))
+		mixedCase = mixedCase || strings.ContainsAny(r, UPPERCASE_LETTERS) && strings.ContainsAny(r, LOWERCASE_LETTERS)
 	}
+	require.True(t, mixedCase, "should use the whole charset")
+
+	require.Equal(t, "ééé", NewRandomStringFromCharset(3, "é"))
+	require.Equal(t, "", NewRandomStringFromCharset(0, "ab"))
+	require.Panics(t, func() { NewRandomStringFromCharset(8, "") })
+}
+
+func TestAnonymizeId(t *testing.T) {
+	id := NewId()
+
+	anon := AnonymizeId(id, "salt")
+	require.True(t, IsValidId(anon))
+	require.NotEqual(t, id, anon)
+	require.Equal(t, anon, AnonymizeId(id, "salt"), "should be deterministic for the same salt")
+	require.NotEqual(t, anon, AnonymizeId(id, "pepper"), "should diverge across salts")
+	require.NotEqual(t, anon, AnonymizeId(NewId(), "salt"), "should diverge across ids")
+}
+
+func TestCacheKey(t *testing.T) {
+	params := map[string]string{"type": "open", "sort": "name", "since": "1500000000000"}
+	key := CacheKey("teams", params)
+
+	require.True(t, strings.HasPrefix(key, "teams:"))
+	for i := 0; i < 10; i++ {
+		reordered := map[string]string{}
+		for _, k := range []string{"since", "type", "sort"} {
+			reordered[k] = params[k]
+		}
+		require.Equal(t, key, CacheKey("teams", reordered))
+	}
+
+	require.NotEqual(t, key, CacheKey("channels", params), "should diverge across namespaces")
+	require.NotEqual(t, key, CacheKey("teams", map[string]string{"type": "invite", "sort": "name", "since": "1500000000000"}))
+	require.NotEqual(t, CacheKey("teams", map[string]string{"a": "b=c"}), CacheKey("teams", map[string]string{"a=b": "c"}))
+	require.Equal(t, CacheKey("teams", nil), CacheKey("teams", map[string]string{}))
 }
 
 func TestAppError(t *testing.T) {
@@ -38,6 +114,54 @@ func TestAppError(t *testing.T) {
 	}
 
 	t.Log(err.Error())
+
+	cause := errors.New("connection refused")
+	werr := NewAppErrorWrap("TestAppError", "message", nil, cause, http.StatusInternalServerError)
+	require.True(t, errors.Is(werr, cause))
+	require.Equal(t, cause, werr.Unwrap())
+	require.Equal(t, "connection refused", werr.DetailedError)
+
+	var target *AppError
+	require.True(t, errors.As(fmt.Errorf("saving: %w", werr), &target))
+	require.True(t, target == werr)
+
+	rerr = AppErrorFromJson(strings.NewReader(werr.ToJson()))
+	require.Equal(t, werr.Id, rerr.Id)
+	require.Equal(t, werr.DetailedError, rerr.DetailedError)
+	require.Nil(t, rerr.Unwrap())
+	require.False(t, errors.Is(rerr, cause))
+
+	require.Nil(t, err.Unwrap())
+}
+
+func TestAppErrorParams(t *testing.T) {
+	T := func(translationID string, args ...interface{}) string {
+		if len(args) == 0 {
+			return translationID
+		}
+		return fmt.Sprintf("%v %v", translationID, args[0].(map[string]interface{})["Name"])
+	}
+
+	err := NewAppError("TestAppErrorParams", "message", map[string]interface{}{"Name": "town-square"}, "", http.StatusBadRequest)
+	require.Equal(t, "town-square", err.Params["Name"])
+
+	rerr := AppErrorFromJson(strings.NewReader(err.ToJson()))
+	require.Equal(t, err.Params, rerr.Params)
+
+	rerr.Translate(T)
+	require.Equal(t, "message town-square", rerr.Message)
+
+	rerr.Translate(nil)
+	require.Equal(t, "message", rerr.Message)
+
+	err = NewAppError("TestAppErrorParams", "message", nil, "", http.StatusBadRequest)
+	require.NotContains(t, err.ToJson(), "params")
+
+	err = NewAppError("TestAppErrorParams", "message", map[string]interface{}{"Callback": func() {}}, "details", http.StatusBadRequest)
+	rerr = AppErrorFromJson(strings.NewReader(err.ToJson()))
+	require.Equal(t, "message", rerr.Id)
+	require.Equal(t, "details", rerr.DetailedError)
+	require.Nil(t, rerr.Params)
 }
 
 func TestAppErrorJunk(t *testing.T) {
@@ -47,6 +171,75 @@ func TestAppErrorJunk(t *testing.T) {
 	}
 }
 
+func TestAppErrorTruncated(t *testing.T) {
+	long := strings.Repeat("x", AppErrorMaxRunes+100)
+
+	err := NewAppError("TestAppErrorTruncated", long, nil, long, http.StatusInternalServerError)
+	require.Equal(t, AppErrorMaxRunes, utf8.RuneCountInString(err.Message))
+	require.Equal(t, AppErrorMaxRunes, utf8.RuneCountInString(err.DetailedError))
+	require.True(t, strings.HasSuffix(err.DetailedError, "…"))
+
+	rerr := AppErrorFromJson(strings.NewReader("<html>" + long + "</html>"))
+	require.Equal(t, AppErrorMaxRunes, utf8.RuneCountInString(rerr.DetailedError))
+	require.True(t, strings.HasPrefix(rerr.DetailedError, "body: <html>"))
+	require.True(t, strings.HasSuffix(rerr.DetailedError, "…"))
+}
+
+func TestAsAppError(t *testing.T) {
+	require.Nil(t, AsAppError(nil))
+
+	var nilAppErr *AppError
+	require.Nil(t, AsAppError(nilAppErr))
+
+	appErr := NewAppError("TestAsAppError", "message", nil, "details", http.StatusBadRequest)
+	require.True(t, appErr == AsAppError(appErr), "app errors should be returned unchanged")
+
+	wrapped := AsAppError(errors.New("disk full"))
+	require.NotNil(t, wrapped)
+	require.Equal(t, "model.utils.as_app_error.app_error", wrapped.Id)
+	require.Equal(t, "disk full", wrapped.DetailedError)
+	require.Equal(t, http.StatusInternalServerError, wrapped.StatusCode)
+	require.EqualError(t, wrapped.Unwrap(), "disk full")
+}
+
+func TestTruncateRunes(t *testing.T) {
+	require.Equal(t, "", TruncateRunes("hello", 0))
+	require.Equal(t, "hello", TruncateRunes("hello", 5))
+	require.Equal(t, "hello", TruncateRunes("hello", 10))
+	require.Equal(t, "hel…", TruncateRunes("hello", 4))
+	require.Equal(t, "hü…", TruncateRunes("hüllo", 3))
+}
+
+func TestAppErrorNotJson(t *testing.T) {
+	rerr := AppErrorFromJson(strings.NewReader("<html><body>This is a broken test</body></html>"))
+	require.Equal(t, "model.utils.decode_json.not_json.app_error", rerr.Id)
+
+	rerr = AppErrorFromJson(strings.NewReader("{not valid json"))
+	require.Equal(t, "model.utils.decode_json.app_error", rerr.Id)
+	require.Equal(t, "body: {not valid json", rerr.DetailedError)
+}
+
+func TestLooksLikeJson(t *testing.T) {
+	cases := []struct {
+		Input    string
+		Expected bool
+	}{
+		{`{"id": "test"}`, true},
+		{`["a", "b"]`, true},
+		{" \n\t {\"id\": \"test\"}", true},
+		{"\r\n[]", true},
+		{"<html><body>This is a broken test</body></html>", false},
+		{"   <!DOCTYPE html>", false},
+		{"plain text", false},
+		{"", false},
+		{"   ", false},
+	}
+
+	for _, tc := range cases {
+		require.Equal(t, tc.Expected, LooksLikeJson([]byte(tc.Input)), "input=%q", tc.Input)
+	}
+}
+
 func TestMapJson(t *testing.T) {
 
 	m := make(map[string]string)
@@ -63,6 +256,285 @@ func TestMapJson(t *testing.T) {
 	if len(rm2) > 0 {
 		t.Fatal("make should be ivalid")
 	}
+
+	m = map[string]string{"zeta": "1", "alpha": "2", "mu": "3", "beta": "4", "omega": "5"}
+	sorted := MapToSortedJson(m)
+	require.Equal(t, `{"alpha":"2","beta":"4","mu":"3","omega":"5","zeta":"1"}`, sorted)
+	for i := 0; i < 100; i++ {
+		require.Equal(t, sorted, MapToSortedJson(m))
+	}
+	require.Equal(t, m, MapFromJson(strings.NewReader(sorted)))
+
+	copied := make(map[string]string, len(m))
+	for k, v := range m {
+		copied[k] = v
+	}
+	require.Equal(t, Etag("props", m), Etag("props", copied))
+	require.Equal(t, CurrentVersion+".props."+sorted, Etag("props", m))
+}
+
+func TestFormatPercent(t *testing.T) {
+	require.Equal(t, "0.0%", FormatPercent(0, 0))
+	require.Equal(t, "0.0%", FormatPercent(0, 10))
+	require.Equal(t, "100.0%", FormatPercent(10, 10))
+	require.Equal(t, "12.5%", FormatPercent(1, 8))
+	require.Equal(t, "66.7%", FormatPercent(2, 3))
+	require.Equal(t, "0.1%", FormatPercent(1, 1000))
+}
+
+func TestParseByteSize(t *testing.T) {
+	for input, expected := range map[string]int64{
+		"0":        0,
+		"512":      512,
+		"512B":     512,
+		"10KB":     10000,
+		"10MB":     10000000,
+		"10mb":     10000000,
+		"2GB":      2000000000,
+		"1TB":      1000000000000,
+		"1KiB":     1024,
+		"10MiB":    10485760,
+		"1.5GiB":   1610612736,
+		"1.5 gib":  1610612736,
+		" 2TiB ":   2199023255552,
+		"0.5KB":    500,
+		"1.0001KB": 1000,
+	} {
+		size, err := ParseByteSize(input)
+		require.Nil(t, err, input)
+		require.Equal(t, expected, size, input)
+	}
+
+	for _, input := range []string{"", "MB", "ten MB", "10XB", "10 M B", "-10MB", "1.2.3MB", "10MBs", "99999999TB"} {
+		_, err := ParseByteSize(input)
+		require.NotNil(t, err, input)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	require.Equal(t, "0B", FormatBytes(0))
+	require.Equal(t, "512B", FormatBytes(512))
+	require.Equal(t, "1KiB", FormatBytes(1024))
+	require.Equal(t, "1.5KiB", FormatBytes(1536))
+	require.Equal(t, "10MiB", FormatBytes(10485760))
+	require.Equal(t, "1.5GiB", FormatBytes(1610612736))
+	require.Equal(t, "2048TiB", FormatBytes(2048<<40))
+
+	for _, size := range []int64{0, 512, 1024, 10485760, 1610612736} {
+		parsed, err := ParseByteSize(FormatBytes(size))
+		require.Nil(t, err)
+		require.Equal(t, size, parsed)
+	}
+}
+
+func TestUniqueSlug(t *testing.T) {
+	taken := map[string]bool{}
+	exists := func(slug string) bool {
+		return taken[slug]
+	}
+
+	t.Run("no collision", func(t *testing.T) {
+		require.Equal(t, "engineering", UniqueSlug("engineering", exists))
+	})
+
+	t.Run("collisions", func(t *testing.T) {
+		taken["engineering"] = true
+		require.Equal(t, "engineering-2", UniqueSlug("engineering", exists))
+
+		taken["engineering-2"] = true
+		taken["engineering-3"] = true
+		require.Equal(t, "engineering-4", UniqueSlug("engineering", exists))
+	})
+
+	t.Run("length capped", func(t *testing.T) {
+		long := strings.Repeat("a", TEAM_NAME_MAX_LENGTH)
+		require.Equal(t, long, UniqueSlug(long, exists))
+		require.Equal(t, long, UniqueSlug(long+"bbb", exists))
+
+		taken[long] = true
+		slug := UniqueSlug(long, exists)
+		require.Equal(t, strings.Repeat("a", TEAM_NAME_MAX_LENGTH-2)+"-2", slug)
+		require.Len(t, slug, TEAM_NAME_MAX_LENGTH)
+
+		for n := 2; n < 10; n++ {
+			taken[strings.Repeat("a", TEAM_NAME_MAX_LENGTH-2)+"-"+strconv.Itoa(n)] = true
+		}
+		slug = UniqueSlug(long, exists)
+		require.Equal(t, strings.Repeat("a", TEAM_NAME_MAX_LENGTH-3)+"-10", slug)
+		require.Len(t, slug, TEAM_NAME_MAX_LENGTH)
+
+		hyphenated := strings.Repeat("a", TEAM_NAME_MAX_LENGTH-3) + "-bc"
+		taken[hyphenated] = true
+		require.Equal(t, strings.Repeat("a", TEAM_NAME_MAX_LENGTH-3)+"-2", UniqueSlug(hyphenated, exists), "a hyphen left at the end of the truncated base should be dropped")
+	})
+}
+
+func TestIsBlank(t *testing.T) {
+	require.True(t, IsBlank(""))
+	require.True(t, IsBlank("   "))
+	require.True(t, IsBlank("\t\t"))
+	require.True(t, IsBlank(" \t\n\r "))
+	require.False(t, IsBlank("Engineering"))
+	require.False(t, IsBlank("  Engineering\t"))
+}
+
+func TestMojibake(t *testing.T) {
+	for mojibake, clean := range map[string]string{
+		"CafÃ©":             "Café",
+		"donâ€™t":           "don’t",
+		"Ã¼ber Team":        "über Team",
+		"â€œquotedâ€\u009d": "“quoted”",
+		"Ð¢ÐµÑ\u0081Ñ‚":     "Тест",
+		"æ—¥æœ¬èªž":         "日本語",
+	} {
+		require.True(t, LooksLikeMojibake(mojibake), mojibake)
+		require.Equal(t, clean, FixMojibake(mojibake), mojibake)
+	}
+
+	for _, clean := range []string{"", "Engineering", "Café", "don’t", "über Team", "Тест", "日本語", "naïve café"} {
+		require.False(t, LooksLikeMojibake(clean), clean)
+		require.Equal(t, clean, FixMojibake(clean), clean)
+	}
+}
+
+func TestCollapseWhitespace(t *testing.T) {
+	cases := map[string]string{
+		"":                    "",
+		"   ":                 "",
+		"Team":                "Team",
+		"My Team":             "My Team",
+		" My Team":            "My Team",
+		"My Team ":            "My Team",
+		"My  Team":            "My Team",
+		"\tMy \t Team\n":      "My Team",
+		"  My   Other  Team ": "My Other Team",
+	}
+
+	for input, expected := range cases {
+		if actual := CollapseWhitespace(input); actual != expected {
+			t.Fatalf("input=%q expected=%q actual=%q", input, expected, actual)
+		}
+	}
+}
+
+func TestParseKeyValuePairs(t *testing.T) {
+	pairs, err := ParseKeyValuePairs([]string{"region=emea", "cost_center=1234", "empty="})
+	require.Nil(t, err)
+	require.Equal(t, map[string]string{"region": "emea", "cost_center": "1234", "empty": ""}, pairs)
+
+	pairs, err = ParseKeyValuePairs([]string{`name="My Team"`, "note='it works'", `quote=""`})
+	require.Nil(t, err)
+	require.Equal(t, map[string]string{"name": "My Team", "note": "it works", "quote": ""}, pairs)
+
+	pairs, err = ParseKeyValuePairs([]string{"query=a=b", `filter="x=y"`})
+	require.Nil(t, err)
+	require.Equal(t, map[string]string{"query": "a=b", "filter": "x=y"}, pairs)
+
+	pairs, err = ParseKeyValuePairs([]string{})
+	require.Nil(t, err)
+	require.Len(t, pairs, 0)
+
+	for _, malformed := range []string{"novalue", "=value", " =value", `name="unterminated`, `name='mixed"`, `name="`} {
+		_, err := ParseKeyValuePairs([]string{"ok=1", malformed})
+		require.NotNil(t, err, malformed)
+	}
+}
+
+func TestParseSortSpec(t *testing.T) {
+	allowed := []string{"name", "active_percent"}
+
+	field, desc, err := ParseSortSpec("name", allowed)
+	require.Nil(t, err)
+	require.Equal(t, "name", field)
+	require.False(t, desc)
+
+	field, desc, err = ParseSortSpec("-Active_Percent", allowed)
+	require.Nil(t, err)
+	require.Equal(t, "active_percent", field)
+	require.True(t, desc)
+
+	for _, invalid := range []string{"members", "-members", "", "-", "--name", "name-"} {
+		_, _, err := ParseSortSpec(invalid, allowed)
+		require.NotNil(t, err, invalid)
+	}
+
+	_, _, err = ParseSortSpec("members", allowed)
+	require.Equal(t, `invalid sort field "members", expected one of name, active_percent`, err.Error())
+}
+
+func TestCompileUserRegex(t *testing.T) {
+	re, err := CompileUserRegex(`^New Team( \d+)?$`)
+	require.Nil(t, err)
+	require.True(t, re.MatchString("New Team 2"))
+	require.False(t, re.MatchString("Engineering"))
+
+	_, err = CompileUserRegex("New Team (")
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), `invalid pattern "New Team ("`)
+
+	_, err = CompileUserRegex(strings.Repeat("a", USER_REGEX_MAX_LENGTH))
+	require.Nil(t, err)
+
+	_, err = CompileUserRegex(strings.Repeat("a", USER_REGEX_MAX_LENGTH+1))
+	require.NotNil(t, err)
+	require.Equal(t, "pattern is 513 characters long, the maximum is 512", err.Error())
+}
+
+func TestPageHasNext(t *testing.T) {
+	for name, tc := range map[string]struct {
+		Page     Page
+		Expected bool
+	}{
+		"empty":             {Page{Offset: 0, Limit: 10, Total: 0}, false},
+		"single page":       {Page{Offset: 0, Limit: 10, Total: 5}, false},
+		"exactly one page":  {Page{Offset: 0, Limit: 10, Total: 10}, false},
+		"one more":          {Page{Offset: 0, Limit: 10, Total: 11}, true},
+		"middle page":       {Page{Offset: 10, Limit: 10, Total: 30}, true},
+		"exactly last page": {Page{Offset: 20, Limit: 10, Total: 30}, false},
+		"past the end":      {Page{Offset: 40, Limit: 10, Total: 30}, false},
+		"no limit":          {Page{Offset: 0, Limit: 0, Total: 30}, false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.Expected, tc.Page.HasNext())
+		})
+	}
+}
+
+func TestPageToJson(t *testing.T) {
+	page := &Page{Items: []string{"a", "b"}, Offset: 2, Limit: 2, Total: 5}
+	require.Equal(t, `{"items":["a","b"],"offset":2,"limit":2,"total":5}`, page.ToJson())
+}
+
+func TestDiffIdSlices(t *testing.T) {
+	for name, tc := range map[string]struct {
+		Current  []string
+		Desired  []string
+		ToAdd    []string
+		ToRemove []string
+	}{
+		"empty":           {nil, nil, []string{}, []string{}},
+		"identical":       {[]string{"a", "b"}, []string{"b", "a"}, []string{}, []string{}},
+		"additions only":  {[]string{"a"}, []string{"c", "a", "b"}, []string{"c", "b"}, []string{}},
+		"removals only":   {[]string{"c", "a", "b"}, []string{"a"}, []string{}, []string{"c", "b"}},
+		"overlapping":     {[]string{"a", "b", "c"}, []string{"b", "d", "c", "e"}, []string{"d", "e"}, []string{"a"}},
+		"disjoint":        {[]string{"a", "b"}, []string{"c", "d"}, []string{"c", "d"}, []string{"a", "b"}},
+		"duplicate input": {[]string{"a", "x", "x", "a"}, []string{"b", "b", "a", "b"}, []string{"b"}, []string{"x"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			toAdd, toRemove := DiffIdSlices(tc.Current, tc.Desired)
+			require.Equal(t, tc.ToAdd, toAdd)
+			require.Equal(t, tc.ToRemove, toRemove)
+		})
+	}
+}
+
+func TestRequireKeys(t *testing.T) {
+	keys := []string{"email", "username", "team"}
+
+	require.Empty(t, RequireKeys(keys))
+	require.Empty(t, RequireKeys(keys, "email", "team"))
+	require.Equal(t, []string{"id", "name"}, RequireKeys(keys, "id", "email", "name"))
+	require.Equal(t, []string{"email"}, RequireKeys(nil, "email"))
 }
 
 func TestValidEmail(t *testing.T) {
@@ -73,6 +545,18 @@ func TestValidEmail(t *testing.T) {
 	if IsValidEmail("@corey+test@hulen.com") {
 		t.Error("should be invalid")
 	}
+
+	for _, email := range []string{"john.doe@x.com", "john.doe+work.tag@x.com", "j.o.h.n@mail.x.com"} {
+		if !IsValidEmail(email) {
+			t.Errorf("%v should be valid", email)
+		}
+	}
+
+	for _, email := range []string{"john..doe@x.com", ".john@x.com", "john.@x.com", "..@x.com"} {
+		if IsValidEmail(email) {
+			t.Errorf("%v should be invalid", email)
+		}
+	}
 }
 
 func TestValidLower(t *testing.T) {
@@ -90,6 +574,27 @@ func TestEtag(t *testing.T) {
 	if len(etag) <= 0 {
 		t.Fatal()
 	}
+
+	require.Equal(t, etag, Etag("hello", 24), "identical inputs should give identical etags")
+	require.NotEqual(t, etag, Etag("hello", 25))
+	require.True(t, strings.HasPrefix(etag, CurrentVersion+"."))
+
+	weak := WeakEtag("hello", 24)
+	require.True(t, strings.HasPrefix(weak, `W/"`+CurrentVersion+"."))
+	require.Equal(t, weak, WeakEtag("hello", 24), "identical inputs should give identical etags")
+}
+
+func TestWeakEtag(t *testing.T) {
+	etag := WeakEtag("hello", 24)
+	require.Equal(t, `W/"`+Etag("hello", 24)+`"`, etag)
+	require.True(t, IsWeakEtag(etag))
+
+	require.False(t, IsWeakEtag(Etag("hello", 24)))
+	require.False(t, IsWeakEtag(`"`+Etag("hello", 24)+`"`))
+	require.False(t, IsWeakEtag(`W/"`))
+	require.False(t, IsWeakEtag(`W/abc`))
+	require.False(t, IsWeakEtag(""))
+	require.True(t, IsWeakEtag(`W/""`))
 }
 
 var hashtags = map[string]string{
@@ -131,6 +636,15 @@ var hashtags = map[string]string{
 	"#a":              "",
 	"#1":              "",
 	"foo#bar":         "",
+	"#:smile:":        "#:smile:",
+	"#win:tada:":      "#win:tada:",
+	"(#win:tada:)!":   "#win:tada:",
+	"##:+1:":          "#:+1:",
+	":emoji:":         "",
+	"#test:":          "#test",
+	"#:":              "",
+	"#::":             "",
+	"#a:smile:":       "",
 }
 
 func TestParseHashtags(t *testing.T) {
@@ -141,6 +655,35 @@ func TestParseHashtags(t *testing.T) {
 	}
 }
 
+func TestParseHashtagsWithOffsets(t *testing.T) {
+	for input, output := range hashtags {
+		matches, _ := ParseHashtagsWithOffsets(input)
+
+		tags := []string{}
+		for _, match := range matches {
+			require.Equal(t, match.Tag, input[match.Start:match.End], input)
+			tags = append(tags, match.Tag)
+		}
+		require.Equal(t, output, strings.Join(tags, " "), input)
+	}
+
+	text := "(#bug) in ##hüllo\twith #Mötley; fix"
+	matches, plain := ParseHashtagsWithOffsets(text)
+	require.Equal(t, []HashtagMatch{
+		{Tag: "#bug", Start: 1, End: 5},
+		{Tag: "#hüllo", Start: 11, End: 18},
+		{Tag: "#Mötley", Start: 24, End: 32},
+	}, matches)
+	require.Equal(t, "in with fix", plain)
+
+	_, expectedPlain := ParseHashtags(text)
+	require.Equal(t, expectedPlain, plain)
+
+	matches, plain = ParseHashtagsWithOffsets("  ")
+	require.Empty(t, matches)
+	require.Equal(t, "", plain)
+}
+
 func TestIsValidAlphaNum(t *testing.T) {
 	cases := []struct {
 		Input  string
@@ -200,6 +743,59 @@ func TestGetServerIpAddress(t *testing.T) {
 	if len(GetServerIpAddress()) == 0 {
 		t.Fatal("Should find local ip address")
 	}
+
+	require.Equal(t, "10.0.0.5", preferredIpAddress([]string{"2001:db8::1", "10.0.0.5", "10.0.0.6"}))
+	require.Equal(t, "2001:db8::1", preferredIpAddress([]string{"2001:db8::1", "2001:db8::2"}))
+	require.Equal(t, "", preferredIpAddress([]string{}))
+}
+
+func TestGetServerIpAddresses(t *testing.T) {
+	addresses := GetServerInterfaceAddresses()
+	require.NotEmpty(t, addresses)
+
+	for _, address := range addresses {
+		require.NotEmpty(t, address.Interface)
+
+		ip := net.ParseIP(address.Address)
+		require.NotNil(t, ip, address.Address)
+		require.False(t, ip.IsLoopback(), address.Address)
+		require.False(t, ip.IsLinkLocalUnicast(), address.Address)
+	}
+
+	require.Len(t, GetServerIpAddresses(), len(addresses))
+}
+
+func TestIsValidHexColor(t *testing.T) {
+	cases := []struct {
+		Input  string
+		Result bool
+	}{
+		{"#fff", true},
+		{"#FFF", true},
+		{"#2389d7", true},
+		{"#2389D7", true},
+		{"#2389d7cc", true},
+		{"#aBc123Ef", true},
+		{"", false},
+		{"#", false},
+		{"fff", false},
+		{"2389d7", false},
+		{"##2389d7", false},
+		{"#ff", false},
+		{"#ffff", false},
+		{"#2389d", false},
+		{"#2389d7c", false},
+		{"#2389d7ccc", false},
+		{"#ggg", false},
+		{"#2389z7", false},
+		{" #2389d7", false},
+		{"#2389d7\n", false},
+		{"rgb(0,0,0)", false},
+	}
+
+	for _, tc := range cases {
+		require.Equal(t, tc.Result, IsValidHexColor(tc.Input), "input=%q", tc.Input)
+	}
 }
 
 func TestIsValidAlphaNumHyphenUnderscore(t *testing.T) {
@@ -370,6 +966,11 @@ func TestIsValidId(t *testing.T) {
 	}
 }
 
+// checkNowhereNil is like NowhereNil, but logs the path to the first nil value it finds.
+func checkNowhereNil(t *testing.T, name string, value interface{}) bool {
+	return nowhereNil(t.Logf, name, value)
+}
+
 func TestNowhereNil(t *testing.T) {
 	t.Parallel()
 
@@ -546,7 +1147,261 @@ func TestNowhereNil(t *testing.T) {
 			}()
 
 			t.Parallel()
-			require.Equal(t, testCase.Expected, checkNowhereNil(t, "value", testCase.Value))
+			require.Equal(t, testCase.Expected, NowhereNil(testCase.Value))
+		})
+	}
+}
+
+func TestParseExtendedDuration(t *testing.T) {
+	for input, expected := range map[string]time.Duration{
+		"90d":    90 * 24 * time.Hour,
+		"2w":     14 * 24 * time.Hour,
+		"1w2d":   9 * 24 * time.Hour,
+		"1d12h":  36 * time.Hour,
+		"36h":    36 * time.Hour,
+		"1h30m":  90 * time.Minute,
+		" 7d ":   7 * 24 * time.Hour,
+		"0":      0,
+		"0d":     0,
+		"1d0.5h": 24*time.Hour + 30*time.Minute,
+	} {
+		duration, err := ParseExtendedDuration(input)
+		require.Nil(t, err, input)
+		require.Equal(t, expected, duration, input)
+	}
+
+	for _, input := range []string{"", "d", "90", "90 d", "1.5d", "3y", "1d-2h", "99999999999999d"} {
+		_, err := ParseExtendedDuration(input)
+		require.NotNil(t, err, input)
+	}
+}
+
+func TestBackoffSchedule(t *testing.T) {
+	defer func(r *mathrand.Rand) { backoffRand = r }(backoffRand)
+
+	backoffRand = mathrand.New(mathrand.NewSource(1))
+	schedule := BackoffSchedule(10, 100*time.Millisecond, 5*time.Second)
+	require.Len(t, schedule, 10)
+
+	expected := 100 * time.Millisecond
+	for i, delay := range schedule {
+		if expected > 5*time.Second {
+			expected = 5 * time.Second
+		}
+		require.True(t, delay >= expected/2 && delay <= expected, "delay %v of %v is outside of [%v, %v]", i, delay, expected/2, expected)
+		if i > 0 && expected < 5*time.Second {
+			require.True(t, delay >= schedule[i-1], "delay %v of %v is shorter than the previous one", i, delay)
+		}
+		expected *= 2
+	}
+
+	backoffRand = mathrand.New(mathrand.NewSource(1))
+	require.Equal(t, schedule, BackoffSchedule(10, 100*time.Millisecond, 5*time.Second), "the same seed should give the same schedule")
+
+	require.Empty(t, BackoffSchedule(0, time.Second, time.Minute))
+	require.Empty(t, BackoffSchedule(-1, time.Second, time.Minute))
+	for _, delay := range BackoffSchedule(100, time.Second, time.Minute) {
+		require.True(t, delay <= time.Minute, "delay %v is over the cap", delay)
+	}
+}
+
+func TestRetryWithBackoff(t *testing.T) {
+	calls := 0
+	err := RetryWithBackoff(3, time.Millisecond, 2*time.Millisecond, func() error {
+		calls++
+		return errors.New("unavailable")
+	})
+	require.EqualError(t, err, "unavailable")
+	require.Equal(t, 3, calls)
+
+	calls = 0
+	err = RetryWithBackoff(5, time.Millisecond, 2*time.Millisecond, func() error {
+		calls++
+		if calls < 2 {
+			return errors.New("unavailable")
+		}
+		return nil
+	})
+	require.Nil(t, err)
+	require.Equal(t, 2, calls)
+
+	for _, attempts := range []int{0, -3} {
+		calls = 0
+		err = RetryWithBackoff(attempts, time.Millisecond, 2*time.Millisecond, func() error {
+			calls++
+			return errors.New("unavailable")
 		})
+		require.EqualError(t, err, "unavailable")
+		require.Equal(t, 1, calls, "attempts %v", attempts)
+	}
+}
+
+func TestCopyLimitedWithChecksum(t *testing.T) {
+	data := []byte("The quick brown fox jumps over the lazy dog")
+	expectedSum := sha256.Sum256(data)
+
+	t.Run("under the limit", func(t *testing.T) {
+		var dst bytes.Buffer
+		written, sum, err := CopyLimitedWithChecksum(&dst, bytes.NewReader(data), 1024)
+		require.Nil(t, err)
+		require.Equal(t, int64(len(data)), written)
+		require.Equal(t, data, dst.Bytes())
+		require.Equal(t, hex.EncodeToString(expectedSum[:]), sum)
+		require.Equal(t, "d7a8fbb307d7809469ca9abcb0082e4f8d5651e46d3cdb762d02d0bf37c9e592", sum)
+	})
+
+	t.Run("exactly at the limit", func(t *testing.T) {
+		var dst bytes.Buffer
+		written, sum, err := CopyLimitedWithChecksum(&dst, bytes.NewReader(data), int64(len(data)))
+		require.Nil(t, err)
+		require.Equal(t, int64(len(data)), written)
+		require.Equal(t, hex.EncodeToString(expectedSum[:]), sum)
+	})
+
+	t.Run("over the limit", func(t *testing.T) {
+		var dst bytes.Buffer
+		written, sum, err := CopyLimitedWithChecksum(&dst, bytes.NewReader(data), 10)
+		require.NotNil(t, err)
+		require.Equal(t, int64(10), written)
+		require.Equal(t, 10, dst.Len(), "no more than the limit should be written")
+		require.Empty(t, sum)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		var dst bytes.Buffer
+		written, sum, err := CopyLimitedWithChecksum(&dst, bytes.NewReader(nil), 0)
+		require.Nil(t, err)
+		require.Zero(t, written)
+		require.Equal(t, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", sum)
+	})
+
+	t.Run("negative limit", func(t *testing.T) {
+		var dst bytes.Buffer
+		written, sum, err := CopyLimitedWithChecksum(&dst, bytes.NewReader(data), -1)
+		require.NotNil(t, err)
+		require.Zero(t, written)
+		require.Zero(t, dst.Len())
+		require.Empty(t, sum)
+	})
+}
+
+func TestSortChannelsStable(t *testing.T) {
+	a := &Channel{Id: "aaaaaaaaaaaaaaaaaaaaaaaaaa", CreateAt: 2000}
+	b := &Channel{Id: "bbbbbbbbbbbbbbbbbbbbbbbbbb", CreateAt: 2000}
+	c := &Channel{Id: "cccccccccccccccccccccccccc", CreateAt: 2000}
+	older := &Channel{Id: "zzzzzzzzzzzzzzzzzzzzzzzzzz", CreateAt: 1000}
+	expected := []*Channel{older, a, b, c}
+
+	for _, channels := range [][]*Channel{
+		{a, b, c, older},
+		{c, b, a, older},
+		{b, older, c, a},
+		{older, a, b, c},
+	} {
+		SortChannelsStable(channels)
+		require.Equal(t, expected, channels)
+	}
+
+	SortChannelsStable(nil)
+	SortChannelsStable([]*Channel{})
+}
+
+func TestFindSlugConflicts(t *testing.T) {
+	t.Run("unique slugs", func(t *testing.T) {
+		require.Empty(t, FindSlugConflicts([]string{"engineering", "sales", "support"}))
+		require.Empty(t, FindSlugConflicts(nil))
+	})
+
+	t.Run("exact duplicates", func(t *testing.T) {
+		require.Equal(t, map[string][]int{"sales": {1, 3}}, FindSlugConflicts([]string{"engineering", "sales", "support", "sales"}))
+	})
+
+	t.Run("case and whitespace variants", func(t *testing.T) {
+		require.Equal(t, map[string][]int{
+			"engineering": {0, 2, 4},
+			"sales":       {1, 3},
+		}, FindSlugConflicts([]string{"engineering", "Sales", " ENGINEERING", "sales ", "Engineering"}))
+	})
+
+	t.Run("blank slugs are ignored", func(t *testing.T) {
+		require.Empty(t, FindSlugConflicts([]string{"", "  ", "sales"}))
+	})
+}
+
+func TestDiffTeams(t *testing.T) {
+	old := &Team{
+		Id:             NewId(),
+		Name:           "engineering",
+		DisplayName:    "Engineering",
+		Type:           TEAM_OPEN,
+		Description:    "Builds things",
+		AllowedDomains: "example.com",
+	}
+
+	t.Run("no changes", func(t *testing.T) {
+		new := *old
+		new.UpdateAt = GetMillis()
+		require.Empty(t, DiffTeams(old, &new))
+	})
+
+	t.Run("single field", func(t *testing.T) {
+		new := *old
+		new.DisplayName = "Platform"
+		require.Equal(t, map[string][2]string{"display_name": {"Engineering", "Platform"}}, DiffTeams(old, &new))
+	})
+
+	t.Run("multiple fields", func(t *testing.T) {
+		new := *old
+		new.Type = TEAM_INVITE
+		new.Description = ""
+		new.AllowedDomains = "example.com,example.org"
+		require.Equal(t, map[string][2]string{
+			"type":            {TEAM_OPEN, TEAM_INVITE},
+			"description":     {"Builds things", ""},
+			"allowed_domains": {"example.com", "example.com,example.org"},
+		}, DiffTeams(old, &new))
+	})
+
+	t.Run("ignores immutable fields", func(t *testing.T) {
+		new := *old
+		new.Id = NewId()
+		new.Name = "renamed"
+		require.Empty(t, DiffTeams(old, &new))
+	})
+
+	t.Run("nil team", func(t *testing.T) {
+		require.Equal(t, map[string][2]string{
+			"display_name":    {"", "Engineering"},
+			"type":            {"", TEAM_OPEN},
+			"description":     {"", "Builds things"},
+			"allowed_domains": {"", "example.com"},
+		}, DiffTeams(nil, old))
+		require.Empty(t, DiffTeams(nil, nil))
+	})
+}
+
+func TestNormalizeTimezone(t *testing.T) {
+	cases := []struct {
+		Input  string
+		Result string
+	}{
+		{"UTC", "UTC"},
+		{"America/New_York", "America/New_York"},
+		{"Europe/Berlin", "Europe/Berlin"},
+		{"Asia/Kolkata", "Asia/Kolkata"},
+		{" America/Sao_Paulo\t", "America/Sao_Paulo"},
+	}
+
+	for _, tc := range cases {
+		result, err := NormalizeTimezone(tc.Input)
+		require.NoError(t, err, tc.Input)
+		require.Equal(t, tc.Result, result, tc.Input)
+		require.True(t, IsValidTimezone(tc.Input), tc.Input)
+	}
+
+	for _, input := range []string{"", "   ", "Local", "Mars/Olympus_Mons", "America/New York", "New_York", "../etc/passwd", "/etc/localtime", "utc+1"} {
+		_, err := NormalizeTimezone(input)
+		require.EqualError(t, err, fmt.Sprintf("invalid timezone %q", input), input)
+		require.False(t, IsValidTimezone(input), input)
 	}
 }
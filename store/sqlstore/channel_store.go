@@ -732,6 +732,32 @@ func (s SqlChannelStore) GetDeleted(teamId string, offset int, limit int) store.
 	})
 }
 
+func (s SqlChannelStore) GetDeletedBefore(teamId string, before int64) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var channels []*model.Channel
+
+		if _, err := s.GetReplica().Select(&channels, "SELECT * FROM Channels WHERE TeamId = :TeamId AND DeleteAt > 0 AND DeleteAt < :Before ORDER BY DeleteAt", map[string]interface{}{"TeamId": teamId, "Before": before}); err != nil {
+			result.Err = model.NewAppError("SqlChannelStore.GetDeletedBefore", "store.sql_channel.get_deleted_before.app_error", nil, "teamId="+teamId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = channels
+		}
+	})
+}
+
+// GetAllChannels returns a page of the public and private channels of every team, including archived ones,
+// ordered by id.
+func (s SqlChannelStore) GetAllChannels(offset int, limit int) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var channels []*model.Channel
+
+		if _, err := s.GetReplica().Select(&channels, "SELECT * FROM Channels WHERE Type IN ('O', 'P') ORDER BY Id LIMIT :Limit OFFSET :Offset", map[string]interface{}{"Limit": limit, "Offset": offset}); err != nil {
+			result.Err = model.NewAppError("SqlChannelStore.GetAllChannels", "store.sql_channel.get_all_channels.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = channels
+		}
+	})
+}
+
 func (s SqlChannelStore) SaveMember(member *model.ChannelMember) store.StoreChannel {
 	return store.Do(func(result *store.StoreResult) {
 		// Grab the channel we are saving this member to
@@ -246,3 +246,26 @@ func (s SqlFileInfoStore) PermanentDeleteBatch(endTime int64, limit int64) store
 		}
 	})
 }
+
+func (fs SqlFileInfoStore) GetStorageUsageForTeam(teamId string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		usage, err := fs.GetReplica().SelectInt(`
+			SELECT
+				COALESCE(SUM(FileInfo.Size), 0)
+			FROM
+				FileInfo,
+				Posts,
+				Channels
+			WHERE
+				FileInfo.PostId = Posts.Id
+				AND Posts.ChannelId = Channels.Id
+				AND Channels.TeamId = :TeamId
+				AND FileInfo.DeleteAt = 0`, map[string]interface{}{"TeamId": teamId})
+		if err != nil {
+			result.Err = model.NewAppError("SqlFileInfoStore.GetStorageUsageForTeam",
+				"store.sql_file_info.get_storage_usage_for_team.app_error", nil, "team_id="+teamId+", err="+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = usage
+		}
+	})
+}
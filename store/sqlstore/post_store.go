@@ -1112,6 +1112,30 @@ func (s *SqlPostStore) AnalyticsPostCount(teamId string, mustHaveFile bool, must
 	})
 }
 
+func (s *SqlPostStore) AnalyticsTeamPostActivity(teamId string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		query :=
+			`SELECT
+			    COUNT(Posts.Id) AS Posts,
+			    COALESCE(MAX(Posts.CreateAt), 0) AS LastPostAt
+			FROM
+			    Posts,
+			    Channels
+			WHERE
+			    Posts.ChannelId = Channels.Id
+			    AND Channels.TeamId = :TeamId
+			    AND Posts.DeleteAt = 0
+			    AND Posts.Type NOT LIKE '` + model.POST_SYSTEM_MESSAGE_PREFIX + `%'`
+
+		var activity model.TeamPostActivity
+		if err := s.GetReplica().SelectOne(&activity, query, map[string]interface{}{"TeamId": teamId}); err != nil {
+			result.Err = model.NewAppError("SqlPostStore.AnalyticsTeamPostActivity", "store.sql_post.analytics_team_post_activity.app_error", nil, "teamId="+teamId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = &activity
+		}
+	})
+}
+
 func (s *SqlPostStore) GetPostsCreatedAt(channelId string, time int64) store.StoreChannel {
 	return store.Do(func(result *store.StoreResult) {
 		query := `SELECT * FROM Posts WHERE CreateAt = :CreateAt AND ChannelId = :ChannelId`
@@ -7,6 +7,7 @@ import (
 	"database/sql"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/mattermost/mattermost-server/model"
 	"github.com/mattermost/mattermost-server/store"
@@ -33,6 +34,7 @@ func NewSqlTeamStore(sqlStore SqlStore) store.TeamStore {
 		table.ColMap("CompanyName").SetMaxSize(64)
 		table.ColMap("AllowedDomains").SetMaxSize(500)
 		table.ColMap("InviteId").SetMaxSize(32)
+		table.ColMap("Props").SetMaxSize(4000)
 
 		tablem := db.AddTableWithName(model.TeamMember{}, "TeamMembers").SetKeys(false, "TeamId", "UserId")
 		tablem.ColMap("TeamId").SetMaxSize(26)
@@ -122,6 +124,35 @@ func (s SqlTeamStore) UpdateDisplayName(name string, teamId string) store.StoreC
 	})
 }
 
+// UpdateName changes the name of the team. Update keeps the stored name, so that a team's URL doesn't change
+// by accident, which makes this the only way to rename a team.
+func (s SqlTeamStore) UpdateName(teamId string, name string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		if !model.IsValidTeamName(name) {
+			result.Err = model.NewAppError("SqlTeamStore.UpdateName", "model.team.is_valid.url.app_error", nil, "team_id="+teamId, http.StatusBadRequest)
+			return
+		}
+
+		sqlResult, err := s.GetMaster().Exec("UPDATE Teams SET Name = :Name, UpdateAt = :UpdateAt WHERE Id = :Id", map[string]interface{}{"Name": name, "UpdateAt": model.GetMillis(), "Id": teamId})
+		if err != nil {
+			if IsUniqueConstraintError(err, []string{"Name", "teams_name_key"}) {
+				result.Err = model.NewAppError("SqlTeamStore.UpdateName", "store.sql_team.save.domain_exists.app_error", nil, "team_id="+teamId+", "+err.Error(), http.StatusBadRequest)
+			} else {
+				result.Err = model.NewAppError("SqlTeamStore.UpdateName", "store.sql_team.update_name.app_error", nil, "team_id="+teamId+", "+err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		if count, err := sqlResult.RowsAffected(); err != nil {
+			result.Err = model.NewAppError("SqlTeamStore.UpdateName", "store.sql_team.update_name.app_error", nil, "team_id="+teamId+", "+err.Error(), http.StatusInternalServerError)
+		} else if count != 1 {
+			result.Err = model.NewAppError("SqlTeamStore.UpdateName", "store.sql_team.update.find.app_error", nil, "team_id="+teamId, http.StatusNotFound)
+		} else {
+			result.Data = teamId
+		}
+	})
+}
+
 func (s SqlTeamStore) Get(id string) store.StoreChannel {
 	return store.Do(func(result *store.StoreResult) {
 		if obj, err := s.GetReplica().Get(model.Team{}, id); err != nil {
@@ -199,6 +230,29 @@ func (s SqlTeamStore) SearchAll(term string) store.StoreChannel {
 	})
 }
 
+// SearchAllSubstring returns every team whose name or display name contains term, ignoring case. Unlike SearchAll,
+// which matches prefixes for the team search API, it's meant for admin tools looking for a team they only partly
+// remember.
+func (s SqlTeamStore) SearchAllSubstring(term string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var teams []*model.Team
+
+		likeTerm := strings.ToLower(term)
+		for _, c := range ignoreLikeSearchChar {
+			likeTerm = strings.Replace(likeTerm, c, "", -1)
+		}
+		for _, c := range escapeLikeSearchChar {
+			likeTerm = strings.Replace(likeTerm, c, "*"+c, -1)
+		}
+
+		if _, err := s.GetReplica().Select(&teams, "SELECT * FROM Teams WHERE LOWER(Name) LIKE :Term escape '*' OR LOWER(DisplayName) LIKE :Term escape '*'", map[string]interface{}{"Term": "%" + likeTerm + "%"}); err != nil {
+			result.Err = model.NewAppError("SqlTeamStore.SearchAllSubstring", "store.sql_team.search_all_team.app_error", nil, "term="+term+", "+err.Error(), http.StatusInternalServerError)
+		}
+
+		result.Data = teams
+	})
+}
+
 func (s SqlTeamStore) SearchOpen(term string) store.StoreChannel {
 	return store.Do(func(result *store.StoreResult) {
 		var teams []*model.Team
@@ -231,7 +285,7 @@ func (s SqlTeamStore) GetAll() store.StoreChannel {
 func (s SqlTeamStore) GetAllPage(offset int, limit int) store.StoreChannel {
 	return store.Do(func(result *store.StoreResult) {
 		var data []*model.Team
-		if _, err := s.GetReplica().Select(&data, "SELECT * FROM Teams LIMIT :Limit OFFSET :Offset", map[string]interface{}{"Offset": offset, "Limit": limit}); err != nil {
+		if _, err := s.GetReplica().Select(&data, "SELECT * FROM Teams ORDER BY Id LIMIT :Limit OFFSET :Offset", map[string]interface{}{"Offset": offset, "Limit": limit}); err != nil {
 			result.Err = model.NewAppError("SqlTeamStore.GetAllTeams", "store.sql_team.get_all.app_error", nil, err.Error(), http.StatusInternalServerError)
 		}
 
@@ -245,6 +299,35 @@ func (s SqlTeamStore) GetAllPage(offset int, limit int) store.StoreChannel {
 	})
 }
 
+// GetAllPageFiltered returns a page of teams in order of id like GetAllPage, leaving out archived teams unless
+// includeDeleted is set and, when teamType isn't empty, teams of any other type.
+func (s SqlTeamStore) GetAllPageFiltered(teamType string, includeDeleted bool, offset int, limit int) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		query := "SELECT * FROM Teams WHERE 1 = 1"
+		if !includeDeleted {
+			query += " AND DeleteAt = 0"
+		}
+		if teamType != "" {
+			query += " AND Type = :Type"
+		}
+		query += " ORDER BY Id LIMIT :Limit OFFSET :Offset"
+
+		var data []*model.Team
+		if _, err := s.GetReplica().Select(&data, query, map[string]interface{}{"Type": teamType, "Offset": offset, "Limit": limit}); err != nil {
+			result.Err = model.NewAppError("SqlTeamStore.GetAllPageFiltered", "store.sql_team.get_all_page_filtered.app_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		for _, team := range data {
+			if len(team.InviteId) == 0 {
+				team.InviteId = team.Id
+			}
+		}
+
+		result.Data = data
+	})
+}
+
 func (s SqlTeamStore) GetTeamsByUserId(userId string) store.StoreChannel {
 	return store.Do(func(result *store.StoreResult) {
 		var data []*model.Team
@@ -401,7 +484,7 @@ func (s SqlTeamStore) GetMember(teamId string, userId string) store.StoreChannel
 func (s SqlTeamStore) GetMembers(teamId string, offset int, limit int) store.StoreChannel {
 	return store.Do(func(result *store.StoreResult) {
 		var members []*model.TeamMember
-		_, err := s.GetReplica().Select(&members, "SELECT * FROM TeamMembers WHERE TeamId = :TeamId AND DeleteAt = 0 LIMIT :Limit OFFSET :Offset", map[string]interface{}{"TeamId": teamId, "Offset": offset, "Limit": limit})
+		_, err := s.GetReplica().Select(&members, "SELECT * FROM TeamMembers WHERE TeamId = :TeamId AND DeleteAt = 0 ORDER BY UserId LIMIT :Limit OFFSET :Offset", map[string]interface{}{"TeamId": teamId, "Offset": offset, "Limit": limit})
 		if err != nil {
 			result.Err = model.NewAppError("SqlTeamStore.GetMembers", "store.sql_team.get_members.app_error", nil, "teamId="+teamId+" "+err.Error(), http.StatusInternalServerError)
 		} else {
@@ -451,6 +534,42 @@ func (s SqlTeamStore) GetActiveMemberCount(teamId string) store.StoreChannel {
 	})
 }
 
+// CountActiveMembersByTeam returns the number of members that haven't left or been deactivated in every team
+// with at least one of them, keyed by team id.
+func (s SqlTeamStore) CountActiveMembersByTeam() store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var rows []struct {
+			TeamId string
+			Count  int64
+		}
+
+		query := `
+			SELECT
+				TeamMembers.TeamId AS TeamId,
+				COUNT(*) AS Count
+			FROM
+				TeamMembers
+				INNER JOIN Users ON Users.Id = TeamMembers.UserId
+			WHERE
+				TeamMembers.DeleteAt = 0
+				AND Users.DeleteAt = 0
+			GROUP BY
+				TeamMembers.TeamId`
+
+		if _, err := s.GetReplica().Select(&rows, query); err != nil {
+			result.Err = model.NewAppError("SqlTeamStore.CountActiveMembersByTeam", "store.sql_team.count_active_members_by_team.app_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		counts := make(map[string]int64, len(rows))
+		for _, row := range rows {
+			counts[row.TeamId] = row.Count
+		}
+
+		result.Data = counts
+	})
+}
+
 func (s SqlTeamStore) GetMembersByIds(teamId string, userIds []string) store.StoreChannel {
 	return store.Do(func(result *store.StoreResult) {
 		var members []*model.TeamMember
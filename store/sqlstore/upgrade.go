@@ -412,6 +412,8 @@ func UpgradeDatabaseToVersion49(sqlStore SqlStore) {
 }
 
 func UpgradeDatabaseToVersion410(sqlStore SqlStore) {
+	sqlStore.CreateColumnIfNotExists("Teams", "Props", "varchar(4000)", "varchar(4000)", "")
+
 	// TODO: Uncomment following condition when version 4.10.0 is released
 	//if shouldPerformUpgrade(sqlStore, VERSION_4_9_0, VERSION_4_10_0) {
 
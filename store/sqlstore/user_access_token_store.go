@@ -292,3 +292,37 @@ func (s SqlUserAccessTokenStore) updateTokenDisable(transaction *gorp.Transactio
 
 	return result
 }
+
+func (s SqlUserAccessTokenStore) CountActiveByTeam() store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var rows []struct {
+			TeamId string
+			Count  int64
+		}
+
+		query := `
+			SELECT
+				TeamMembers.TeamId AS TeamId,
+				COUNT(UserAccessTokens.Id) AS Count
+			FROM
+				TeamMembers
+				INNER JOIN UserAccessTokens ON UserAccessTokens.UserId = TeamMembers.UserId
+			WHERE
+				TeamMembers.DeleteAt = 0
+				AND UserAccessTokens.IsActive = TRUE
+			GROUP BY
+				TeamMembers.TeamId`
+
+		if _, err := s.GetReplica().Select(&rows, query); err != nil {
+			result.Err = model.NewAppError("SqlUserAccessTokenStore.CountActiveByTeam", "store.sql_user_access_token.count_active_by_team.app_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		counts := make(map[string]int64, len(rows))
+		for _, row := range rows {
+			counts[row.TeamId] = row.Count
+		}
+
+		result.Data = counts
+	})
+}
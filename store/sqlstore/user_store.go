@@ -774,6 +774,63 @@ func (us SqlUserStore) GetByEmail(email string) store.StoreChannel {
 	})
 }
 
+// GetByEmails returns the users with the given emails, ignoring case. Unlike GetProfileByIds, the users aren't
+// sanitized.
+func (us SqlUserStore) GetByEmails(emails []string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		users := []*model.User{}
+		if len(emails) == 0 {
+			result.Data = users
+			return
+		}
+
+		props := make(map[string]interface{})
+		emailQuery := ""
+		for index, email := range emails {
+			if len(emailQuery) > 0 {
+				emailQuery += ", "
+			}
+
+			props["email"+strconv.Itoa(index)] = strings.ToLower(email)
+			emailQuery += ":email" + strconv.Itoa(index)
+		}
+
+		if _, err := us.GetReplica().Select(&users, "SELECT * FROM Users WHERE Email IN ("+emailQuery+")", props); err != nil {
+			result.Err = model.NewAppError("SqlUserStore.GetByEmails", "store.sql_user.get_profiles.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = users
+		}
+	})
+}
+
+// GetByIds returns the users with the given ids. Unlike GetProfileByIds, the users aren't sanitized or cached.
+func (us SqlUserStore) GetByIds(ids []string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		users := []*model.User{}
+		if len(ids) == 0 {
+			result.Data = users
+			return
+		}
+
+		props := make(map[string]interface{})
+		idQuery := ""
+		for index, id := range ids {
+			if len(idQuery) > 0 {
+				idQuery += ", "
+			}
+
+			props["userId"+strconv.Itoa(index)] = id
+			idQuery += ":userId" + strconv.Itoa(index)
+		}
+
+		if _, err := us.GetReplica().Select(&users, "SELECT * FROM Users WHERE Id IN ("+idQuery+")", props); err != nil {
+			result.Err = model.NewAppError("SqlUserStore.GetByIds", "store.sql_user.get_profiles.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = users
+		}
+	})
+}
+
 func (us SqlUserStore) GetByAuth(authData *string, authService string) store.StoreChannel {
 	return store.Do(func(result *store.StoreResult) {
 		if authData == nil || *authData == "" {
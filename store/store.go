@@ -78,13 +78,16 @@ type TeamStore interface {
 	Save(team *model.Team) StoreChannel
 	Update(team *model.Team) StoreChannel
 	UpdateDisplayName(name string, teamId string) StoreChannel
+	UpdateName(teamId string, name string) StoreChannel
 	Get(id string) StoreChannel
 	GetByName(name string) StoreChannel
 	SearchByName(name string) StoreChannel
 	SearchAll(term string) StoreChannel
+	SearchAllSubstring(term string) StoreChannel
 	SearchOpen(term string) StoreChannel
 	GetAll() StoreChannel
 	GetAllPage(offset int, limit int) StoreChannel
+	GetAllPageFiltered(teamType string, includeDeleted bool, offset int, limit int) StoreChannel
 	GetAllTeamListing() StoreChannel
 	GetAllTeamPageListing(offset int, limit int) StoreChannel
 	GetTeamsByUserId(userId string) StoreChannel
@@ -98,6 +101,7 @@ type TeamStore interface {
 	GetMembersByIds(teamId string, userIds []string) StoreChannel
 	GetTotalMemberCount(teamId string) StoreChannel
 	GetActiveMemberCount(teamId string) StoreChannel
+	CountActiveMembersByTeam() StoreChannel
 	GetTeamsForUser(userId string) StoreChannel
 	GetChannelUnreadsForAllTeams(excludeTeamId, userId string) StoreChannel
 	GetChannelUnreadsForTeam(teamId, userId string) StoreChannel
@@ -126,6 +130,8 @@ type ChannelStore interface {
 	GetByNameIncludeDeleted(team_id string, name string, allowFromCache bool) StoreChannel
 	GetDeletedByName(team_id string, name string) StoreChannel
 	GetDeleted(team_id string, offset int, limit int) StoreChannel
+	GetDeletedBefore(teamId string, before int64) StoreChannel
+	GetAllChannels(offset int, limit int) StoreChannel
 	GetChannels(teamId string, userId string) StoreChannel
 	GetMoreChannels(teamId string, userId string, offset int, limit int) StoreChannel
 	GetPublicChannelsForTeam(teamId string, offset int, limit int) StoreChannel
@@ -191,6 +197,7 @@ type PostStore interface {
 	AnalyticsUserCountsWithPostsByDay(teamId string) StoreChannel
 	AnalyticsPostCountsByDay(teamId string) StoreChannel
 	AnalyticsPostCount(teamId string, mustHaveFile bool, mustHaveHashtag bool) StoreChannel
+	AnalyticsTeamPostActivity(teamId string) StoreChannel
 	ClearCaches()
 	InvalidateLastPostTimeCache(channelId string)
 	GetPostsCreatedAt(channelId string, time int64) StoreChannel
@@ -227,6 +234,8 @@ type UserStore interface {
 	GetProfileByIds(userId []string, allowFromCache bool) StoreChannel
 	InvalidatProfileCacheForUser(userId string)
 	GetByEmail(email string) StoreChannel
+	GetByEmails(emails []string) StoreChannel
+	GetByIds(ids []string) StoreChannel
 	GetByAuth(authData *string, authService string) StoreChannel
 	GetAllUsingAuthService(authService string) StoreChannel
 	GetByUsername(username string) StoreChannel
@@ -426,6 +435,7 @@ type FileInfoStore interface {
 	DeleteForPost(postId string) StoreChannel
 	PermanentDelete(fileId string) StoreChannel
 	PermanentDeleteBatch(endTime int64, limit int64) StoreChannel
+	GetStorageUsageForTeam(teamId string) StoreChannel
 	ClearCaches()
 }
 
@@ -463,6 +473,7 @@ type UserAccessTokenStore interface {
 	Search(term string) StoreChannel
 	UpdateTokenEnable(tokenId string) StoreChannel
 	UpdateTokenDisable(tokenId string) StoreChannel
+	CountActiveByTeam() StoreChannel
 }
 
 type PluginStore interface {
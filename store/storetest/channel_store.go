@@ -29,6 +29,8 @@ func TestChannelStore(t *testing.T, ss store.Store) {
 	t.Run("GetByNames", func(t *testing.T) { testChannelStoreGetByNames(t, ss) })
 	t.Run("GetDeletedByName", func(t *testing.T) { testChannelStoreGetDeletedByName(t, ss) })
 	t.Run("GetDeleted", func(t *testing.T) { testChannelStoreGetDeleted(t, ss) })
+	t.Run("GetDeletedBefore", func(t *testing.T) { testChannelStoreGetDeletedBefore(t, ss) })
+	t.Run("GetAllChannels", func(t *testing.T) { testChannelStoreGetAllChannels(t, ss) })
 	t.Run("ChannelMemberStore", func(t *testing.T) { testChannelMemberStore(t, ss) })
 	t.Run("ChannelDeleteMemberStore", func(t *testing.T) { testChannelDeleteMemberStore(t, ss) })
 	t.Run("GetChannels", func(t *testing.T) { testChannelStoreGetChannels(t, ss) })
@@ -2186,3 +2188,102 @@ func testChannelStoreMaxChannelsPerTeam(t *testing.T, ss store.Store) {
 	result = <-ss.Channel().Save(channel, 1)
 	assert.Nil(t, result.Err)
 }
+
+func testChannelStoreGetDeletedBefore(t *testing.T, ss store.Store) {
+	teamId := model.NewId()
+	now := model.GetMillis()
+
+	old := store.Must(ss.Channel().Save(&model.Channel{
+		TeamId:      teamId,
+		DisplayName: "Old",
+		Name:        "zz" + model.NewId() + "b",
+		Type:        model.CHANNEL_OPEN,
+		DeleteAt:    now - 1000*60*60*24*400,
+	}, -1)).(*model.Channel)
+
+	store.Must(ss.Channel().Save(&model.Channel{
+		TeamId:      teamId,
+		DisplayName: "Recent",
+		Name:        "zz" + model.NewId() + "b",
+		Type:        model.CHANNEL_PRIVATE,
+		DeleteAt:    now,
+	}, -1))
+
+	store.Must(ss.Channel().Save(&model.Channel{
+		TeamId:      teamId,
+		DisplayName: "Active",
+		Name:        "zz" + model.NewId() + "b",
+		Type:        model.CHANNEL_OPEN,
+	}, -1))
+
+	store.Must(ss.Channel().Save(&model.Channel{
+		TeamId:      model.NewId(),
+		DisplayName: "Other Team",
+		Name:        "zz" + model.NewId() + "b",
+		Type:        model.CHANNEL_OPEN,
+		DeleteAt:    old.DeleteAt,
+	}, -1))
+
+	result := <-ss.Channel().GetDeletedBefore(teamId, now-1000*60*60*24*365)
+	if result.Err != nil {
+		t.Fatal(result.Err)
+	}
+	channels := result.Data.([]*model.Channel)
+	if len(channels) != 1 || channels[0].Id != old.Id {
+		t.Fatal("expected only the old archived channel")
+	}
+
+	result = <-ss.Channel().GetDeletedBefore(teamId, now+1)
+	if result.Err != nil {
+		t.Fatal(result.Err)
+	}
+	if channels := result.Data.([]*model.Channel); len(channels) != 2 {
+		t.Fatalf("expected both archived channels, got %v", len(channels))
+	}
+}
+
+func testChannelStoreGetAllChannels(t *testing.T, ss store.Store) {
+	openChannel := store.Must(ss.Channel().Save(&model.Channel{
+		TeamId:      model.NewId(),
+		DisplayName: "Open",
+		Name:        "zz" + model.NewId() + "b",
+		Type:        model.CHANNEL_OPEN,
+	}, -1)).(*model.Channel)
+
+	archived := store.Must(ss.Channel().Save(&model.Channel{
+		TeamId:      model.NewId(),
+		DisplayName: "Archived",
+		Name:        "zz" + model.NewId() + "b",
+		Type:        model.CHANNEL_PRIVATE,
+		DeleteAt:    model.GetMillis(),
+	}, -1)).(*model.Channel)
+
+	u1 := store.Must(ss.User().Save(&model.User{Email: model.NewId(), Nickname: model.NewId()})).(*model.User)
+	u2 := store.Must(ss.User().Save(&model.User{Email: model.NewId(), Nickname: model.NewId()})).(*model.User)
+	direct := store.Must(ss.Channel().CreateDirectChannel(u1.Id, u2.Id)).(*model.Channel)
+
+	found := map[string]bool{}
+	for offset := 0; ; offset += 100 {
+		result := <-ss.Channel().GetAllChannels(offset, 100)
+		if result.Err != nil {
+			t.Fatal(result.Err)
+		}
+		channels := result.Data.([]*model.Channel)
+		if len(channels) > 100 {
+			t.Fatal("returned more channels than the limit")
+		}
+		for _, channel := range channels {
+			found[channel.Id] = true
+		}
+		if len(channels) < 100 {
+			break
+		}
+	}
+
+	if !found[openChannel.Id] || !found[archived.Id] {
+		t.Fatal("should have returned the open and archived channels")
+	}
+	if found[direct.Id] {
+		t.Fatal("should not have returned the direct channel")
+	}
+}
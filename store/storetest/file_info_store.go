@@ -19,6 +19,7 @@ func TestFileInfoStore(t *testing.T, ss store.Store) {
 	t.Run("FileInfoDeleteForPost", func(t *testing.T) { testFileInfoDeleteForPost(t, ss) })
 	t.Run("FileInfoPermanentDelete", func(t *testing.T) { testFileInfoPermanentDelete(t, ss) })
 	t.Run("FileInfoPermanentDeleteBatch", func(t *testing.T) { testFileInfoPermanentDeleteBatch(t, ss) })
+	t.Run("FileInfoGetStorageUsageForTeam", func(t *testing.T) { testFileInfoGetStorageUsageForTeam(t, ss) })
 }
 
 func testFileInfoSaveGet(t *testing.T, ss store.Store) {
@@ -294,3 +295,50 @@ func testFileInfoPermanentDeleteBatch(t *testing.T, ss store.Store) {
 		t.Fatal("Expected 3 fileInfos")
 	}
 }
+
+func testFileInfoGetStorageUsageForTeam(t *testing.T, ss store.Store) {
+	teamId := model.NewId()
+
+	channel := store.Must(ss.Channel().Save(&model.Channel{
+		TeamId:      teamId,
+		DisplayName: "Storage",
+		Name:        "zz" + model.NewId() + "b",
+		Type:        model.CHANNEL_OPEN,
+	}, -1)).(*model.Channel)
+
+	post := store.Must(ss.Post().Save(&model.Post{
+		ChannelId: channel.Id,
+		UserId:    model.NewId(),
+		Message:   "files",
+	})).(*model.Post)
+
+	otherPost := store.Must(ss.Post().Save(&model.Post{
+		ChannelId: model.NewId(),
+		UserId:    model.NewId(),
+		Message:   "files elsewhere",
+	})).(*model.Post)
+
+	for _, info := range []*model.FileInfo{
+		{CreatorId: post.UserId, PostId: post.Id, Path: "file1.txt", Size: 1000},
+		{CreatorId: post.UserId, PostId: post.Id, Path: "file2.txt", Size: 234},
+		{CreatorId: post.UserId, PostId: post.Id, Path: "deleted.txt", Size: 5000, DeleteAt: 123},
+		{CreatorId: otherPost.UserId, PostId: otherPost.Id, Path: "other.txt", Size: 7000},
+	} {
+		info = store.Must(ss.FileInfo().Save(info)).(*model.FileInfo)
+		defer func(id string) {
+			<-ss.FileInfo().PermanentDelete(id)
+		}(info.Id)
+	}
+
+	if result := <-ss.FileInfo().GetStorageUsageForTeam(teamId); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if usage := result.Data.(int64); usage != 1234 {
+		t.Fatalf("expected 1234 bytes to be used, got %v", usage)
+	}
+
+	if result := <-ss.FileInfo().GetStorageUsageForTeam(model.NewId()); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if usage := result.Data.(int64); usage != 0 {
+		t.Fatalf("expected no bytes to be used, got %v", usage)
+	}
+}
@@ -162,6 +162,22 @@ func (_m *ChannelStore) GetAllChannelMembersNotifyPropsForChannel(channelId stri
 	return r0
 }
 
+// GetAllChannels provides a mock function with given fields: offset, limit
+func (_m *ChannelStore) GetAllChannels(offset int, limit int) store.StoreChannel {
+	ret := _m.Called(offset, limit)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(int, int) store.StoreChannel); ok {
+		r0 = rf(offset, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
 // GetByName provides a mock function with given fields: team_id, name, allowFromCache
 func (_m *ChannelStore) GetByName(team_id string, name string, allowFromCache bool) store.StoreChannel {
 	ret := _m.Called(team_id, name, allowFromCache)
@@ -274,6 +290,22 @@ func (_m *ChannelStore) GetDeleted(team_id string, offset int, limit int) store.
 	return r0
 }
 
+// GetDeletedBefore provides a mock function with given fields: teamId, before
+func (_m *ChannelStore) GetDeletedBefore(teamId string, before int64) store.StoreChannel {
+	ret := _m.Called(teamId, before)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string, int64) store.StoreChannel); ok {
+		r0 = rf(teamId, before)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
 // GetDeletedByName provides a mock function with given fields: team_id, name
 func (_m *ChannelStore) GetDeletedByName(team_id string, name string) store.StoreChannel {
 	ret := _m.Called(team_id, name)
@@ -45,6 +45,22 @@ func (_m *PostStore) AnalyticsPostCountsByDay(teamId string) store.StoreChannel
 	return r0
 }
 
+// AnalyticsTeamPostActivity provides a mock function with given fields: teamId
+func (_m *PostStore) AnalyticsTeamPostActivity(teamId string) store.StoreChannel {
+	ret := _m.Called(teamId)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string) store.StoreChannel); ok {
+		r0 = rf(teamId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
 // AnalyticsUserCountsWithPostsByDay provides a mock function with given fields: teamId
 func (_m *PostStore) AnalyticsUserCountsWithPostsByDay(teamId string) store.StoreChannel {
 	ret := _m.Called(teamId)
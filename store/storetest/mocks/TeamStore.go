@@ -29,6 +29,22 @@ func (_m *TeamStore) AnalyticsTeamCount() store.StoreChannel {
 	return r0
 }
 
+// CountActiveMembersByTeam provides a mock function with given fields:
+func (_m *TeamStore) CountActiveMembersByTeam() store.StoreChannel {
+	ret := _m.Called()
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func() store.StoreChannel); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
 // Get provides a mock function with given fields: id
 func (_m *TeamStore) Get(id string) store.StoreChannel {
 	ret := _m.Called(id)
@@ -93,6 +109,22 @@ func (_m *TeamStore) GetAllPage(offset int, limit int) store.StoreChannel {
 	return r0
 }
 
+// GetAllPageFiltered provides a mock function with given fields: teamType, includeDeleted, offset, limit
+func (_m *TeamStore) GetAllPageFiltered(teamType string, includeDeleted bool, offset int, limit int) store.StoreChannel {
+	ret := _m.Called(teamType, includeDeleted, offset, limit)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string, bool, int, int) store.StoreChannel); ok {
+		r0 = rf(teamType, includeDeleted, offset, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
 // GetAllTeamListing provides a mock function with given fields:
 func (_m *TeamStore) GetAllTeamListing() store.StoreChannel {
 	ret := _m.Called()
@@ -397,6 +429,22 @@ func (_m *TeamStore) SearchAll(term string) store.StoreChannel {
 	return r0
 }
 
+// SearchAllSubstring provides a mock function with given fields: term
+func (_m *TeamStore) SearchAllSubstring(term string) store.StoreChannel {
+	ret := _m.Called(term)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string) store.StoreChannel); ok {
+		r0 = rf(term)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
 // SearchByName provides a mock function with given fields: name
 func (_m *TeamStore) SearchByName(name string) store.StoreChannel {
 	ret := _m.Called(name)
@@ -492,3 +540,19 @@ func (_m *TeamStore) UpdateMember(member *model.TeamMember) store.StoreChannel {
 
 	return r0
 }
+
+// UpdateName provides a mock function with given fields: teamId, name
+func (_m *TeamStore) UpdateName(teamId string, name string) store.StoreChannel {
+	ret := _m.Called(teamId, name)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string, string) store.StoreChannel); ok {
+		r0 = rf(teamId, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
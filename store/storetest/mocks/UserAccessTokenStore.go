@@ -13,6 +13,22 @@ type UserAccessTokenStore struct {
 	mock.Mock
 }
 
+// CountActiveByTeam provides a mock function with given fields:
+func (_m *UserAccessTokenStore) CountActiveByTeam() store.StoreChannel {
+	ret := _m.Called()
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func() store.StoreChannel); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
 // Delete provides a mock function with given fields: tokenId
 func (_m *UserAccessTokenStore) Delete(tokenId string) store.StoreChannel {
 	ret := _m.Called(tokenId)
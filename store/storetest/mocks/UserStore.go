@@ -194,6 +194,38 @@ func (_m *UserStore) GetByEmail(email string) store.StoreChannel {
 	return r0
 }
 
+// GetByEmails provides a mock function with given fields: emails
+func (_m *UserStore) GetByEmails(emails []string) store.StoreChannel {
+	ret := _m.Called(emails)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func([]string) store.StoreChannel); ok {
+		r0 = rf(emails)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// GetByIds provides a mock function with given fields: ids
+func (_m *UserStore) GetByIds(ids []string) store.StoreChannel {
+	ret := _m.Called(ids)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func([]string) store.StoreChannel); ok {
+		r0 = rf(ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
 // GetByUsername provides a mock function with given fields: username
 func (_m *UserStore) GetByUsername(username string) store.StoreChannel {
 	ret := _m.Called(username)
@@ -34,6 +34,7 @@ func TestPostStore(t *testing.T, ss store.Store) {
 	t.Run("Search", func(t *testing.T) { testPostStoreSearch(t, ss) })
 	t.Run("UserCountsWithPostsByDay", func(t *testing.T) { testUserCountsWithPostsByDay(t, ss) })
 	t.Run("PostCountsByDay", func(t *testing.T) { testPostCountsByDay(t, ss) })
+	t.Run("TeamPostActivity", func(t *testing.T) { testPostStoreTeamPostActivity(t, ss) })
 	t.Run("GetFlaggedPostsForTeam", func(t *testing.T) { testPostStoreGetFlaggedPostsForTeam(t, ss) })
 	t.Run("GetFlaggedPosts", func(t *testing.T) { testPostStoreGetFlaggedPosts(t, ss) })
 	t.Run("GetFlaggedPostsForChannel", func(t *testing.T) { testPostStoreGetFlaggedPostsForChannel(t, ss) })
@@ -1119,6 +1120,63 @@ func testPostCountsByDay(t *testing.T, ss store.Store) {
 	}
 }
 
+func testPostStoreTeamPostActivity(t *testing.T, ss store.Store) {
+	t1 := &model.Team{}
+	t1.DisplayName = "DisplayName"
+	t1.Name = "zz" + model.NewId() + "b"
+	t1.Email = model.NewId() + "@nowhere.com"
+	t1.Type = model.TEAM_OPEN
+	t1 = store.Must(ss.Team().Save(t1)).(*model.Team)
+
+	if r1 := <-ss.Post().AnalyticsTeamPostActivity(t1.Id); r1.Err != nil {
+		t.Fatal(r1.Err)
+	} else if activity := r1.Data.(*model.TeamPostActivity); activity.Posts != 0 || activity.LastPostAt != 0 {
+		t.Fatal("a team without posts should have no activity", activity)
+	}
+
+	c1 := &model.Channel{}
+	c1.TeamId = t1.Id
+	c1.DisplayName = "Channel1"
+	c1.Name = "zz" + model.NewId() + "b"
+	c1.Type = model.CHANNEL_OPEN
+	c1 = store.Must(ss.Channel().Save(c1, -1)).(*model.Channel)
+
+	o1 := &model.Post{}
+	o1.ChannelId = c1.Id
+	o1.UserId = model.NewId()
+	o1.CreateAt = model.GetMillis() - 10000
+	o1.Message = "zz" + model.NewId() + "b"
+	o1 = store.Must(ss.Post().Save(o1)).(*model.Post)
+
+	o2 := &model.Post{}
+	o2.ChannelId = c1.Id
+	o2.UserId = model.NewId()
+	o2.CreateAt = o1.CreateAt + 1000
+	o2.Message = "zz" + model.NewId() + "b"
+	o2.Type = model.POST_JOIN_CHANNEL
+	o2 = store.Must(ss.Post().Save(o2)).(*model.Post)
+
+	o3 := &model.Post{}
+	o3.ChannelId = c1.Id
+	o3.UserId = model.NewId()
+	o3.CreateAt = o1.CreateAt + 2000
+	o3.Message = "zz" + model.NewId() + "b"
+	o3 = store.Must(ss.Post().Save(o3)).(*model.Post)
+	store.Must(ss.Post().Delete(o3.Id, model.GetMillis()))
+
+	if r1 := <-ss.Post().AnalyticsTeamPostActivity(t1.Id); r1.Err != nil {
+		t.Fatal(r1.Err)
+	} else {
+		activity := r1.Data.(*model.TeamPostActivity)
+		if activity.Posts != 1 {
+			t.Fatal("system messages and deleted posts shouldn't be counted", activity.Posts)
+		}
+		if activity.LastPostAt != o1.CreateAt {
+			t.Fatal("the last post should be the latest user post", activity.LastPostAt)
+		}
+	}
+}
+
 func testPostStoreGetFlaggedPostsForTeam(t *testing.T, ss store.Store) {
 	c1 := &model.Channel{}
 	c1.TeamId = model.NewId()
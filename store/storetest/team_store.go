@@ -4,6 +4,7 @@
 package storetest
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -15,15 +16,19 @@ func TestTeamStore(t *testing.T, ss store.Store) {
 	t.Run("Save", func(t *testing.T) { testTeamStoreSave(t, ss) })
 	t.Run("Update", func(t *testing.T) { testTeamStoreUpdate(t, ss) })
 	t.Run("UpdateDisplayName", func(t *testing.T) { testTeamStoreUpdateDisplayName(t, ss) })
+	t.Run("UpdateName", func(t *testing.T) { testTeamStoreUpdateName(t, ss) })
 	t.Run("Get", func(t *testing.T) { testTeamStoreGet(t, ss) })
 	t.Run("GetByName", func(t *testing.T) { testTeamStoreGetByName(t, ss) })
 	t.Run("SearchByName", func(t *testing.T) { testTeamStoreSearchByName(t, ss) })
 	t.Run("SearchAll", func(t *testing.T) { testTeamStoreSearchAll(t, ss) })
+	t.Run("SearchAllSubstring", func(t *testing.T) { testTeamStoreSearchAllSubstring(t, ss) })
 	t.Run("SearchOpen", func(t *testing.T) { testTeamStoreSearchOpen(t, ss) })
 	t.Run("GetByIniviteId", func(t *testing.T) { testTeamStoreGetByIniviteId(t, ss) })
 	t.Run("ByUserId", func(t *testing.T) { testTeamStoreByUserId(t, ss) })
 	t.Run("GetAllTeamListing", func(t *testing.T) { testGetAllTeamListing(t, ss) })
 	t.Run("GetAllTeamPageListing", func(t *testing.T) { testGetAllTeamPageListing(t, ss) })
+	t.Run("GetAllPage", func(t *testing.T) { testTeamStoreGetAllPage(t, ss) })
+	t.Run("GetAllPageFiltered", func(t *testing.T) { testTeamStoreGetAllPageFiltered(t, ss) })
 	t.Run("Delete", func(t *testing.T) { testDelete(t, ss) })
 	t.Run("TeamCount", func(t *testing.T) { testTeamCount(t, ss) })
 	t.Run("TeamMembers", func(t *testing.T) { testTeamMembers(t, ss) })
@@ -104,6 +109,50 @@ func testTeamStoreUpdateDisplayName(t *testing.T, ss store.Store) {
 	}
 }
 
+func testTeamStoreUpdateName(t *testing.T, ss store.Store) {
+	o1 := &model.Team{}
+	o1.DisplayName = "Display Name"
+	o1.Name = "z-z-z" + model.NewId() + "b"
+	o1.Email = model.NewId() + "@nowhere.com"
+	o1.Type = model.TEAM_OPEN
+	o1 = store.Must(ss.Team().Save(o1)).(*model.Team)
+
+	o2 := &model.Team{}
+	o2.DisplayName = "Display Name"
+	o2.Name = "z-z-z" + model.NewId() + "b"
+	o2.Email = model.NewId() + "@nowhere.com"
+	o2.Type = model.TEAM_OPEN
+	o2 = store.Must(ss.Team().Save(o2)).(*model.Team)
+
+	newName := "z-z-z" + model.NewId() + "b"
+	if err := (<-ss.Team().UpdateName(o1.Id, newName)).Err; err != nil {
+		t.Fatal(err)
+	}
+
+	ro1 := store.Must(ss.Team().Get(o1.Id)).(*model.Team)
+	if ro1.Name != newName {
+		t.Fatal("name should have been updated")
+	}
+	if ro1.DisplayName != o1.DisplayName {
+		t.Fatal("display name shouldn't have changed")
+	}
+	if ro1.UpdateAt < o1.UpdateAt {
+		t.Fatal("update at should have been bumped")
+	}
+
+	if err := (<-ss.Team().UpdateName(o2.Id, newName)).Err; err == nil {
+		t.Fatal("shouldn't be able to take the name of another team")
+	}
+
+	if err := (<-ss.Team().UpdateName(o2.Id, "Not Valid!")).Err; err == nil {
+		t.Fatal("shouldn't be able to set an invalid name")
+	}
+
+	if err := (<-ss.Team().UpdateName(model.NewId(), "z-z-z"+model.NewId()+"b")).Err; err == nil {
+		t.Fatal("shouldn't be able to rename a missing team")
+	}
+}
+
 func testTeamStoreGet(t *testing.T, ss store.Store) {
 	o1 := model.Team{}
 	o1.DisplayName = "DisplayName"
@@ -222,6 +271,60 @@ func testTeamStoreSearchAll(t *testing.T, ss store.Store) {
 	}
 }
 
+func testTeamStoreSearchAllSubstring(t *testing.T, ss store.Store) {
+	o1 := model.Team{}
+	o1.DisplayName = "ADisplayName" + model.NewId()
+	o1.Name = "zz" + model.NewId() + "a"
+	o1.Email = model.NewId() + "@nowhere.com"
+	o1.Type = model.TEAM_OPEN
+
+	if err := (<-ss.Team().Save(&o1)).Err; err != nil {
+		t.Fatal(err)
+	}
+
+	p2 := model.Team{}
+	p2.DisplayName = "BDisplayName" + model.NewId()
+	p2.Name = "b" + model.NewId() + "b"
+	p2.Email = model.NewId() + "@nowhere.com"
+	p2.Type = model.TEAM_INVITE
+
+	if err := (<-ss.Team().Save(&p2)).Err; err != nil {
+		t.Fatal(err)
+	}
+
+	r1 := <-ss.Team().SearchAllSubstring(strings.ToUpper(p2.Name[1:10]))
+	if r1.Err != nil {
+		t.Fatal(r1.Err)
+	}
+	if len(r1.Data.([]*model.Team)) != 1 || r1.Data.([]*model.Team)[0].Id != p2.Id {
+		t.Fatal("should have matched part of the name ignoring case")
+	}
+
+	r1 = <-ss.Team().SearchAllSubstring(strings.ToLower(o1.DisplayName[2:]))
+	if r1.Err != nil {
+		t.Fatal(r1.Err)
+	}
+	if len(r1.Data.([]*model.Team)) != 1 || r1.Data.([]*model.Team)[0].Id != o1.Id {
+		t.Fatal("should have matched part of the display name ignoring case")
+	}
+
+	r1 = <-ss.Team().SearchAllSubstring("junk")
+	if r1.Err != nil {
+		t.Fatal(r1.Err)
+	}
+	if len(r1.Data.([]*model.Team)) != 0 {
+		t.Fatal("should have not returned a team")
+	}
+
+	r1 = <-ss.Team().SearchAllSubstring("%")
+	if r1.Err != nil {
+		t.Fatal(r1.Err)
+	}
+	if len(r1.Data.([]*model.Team)) != 0 {
+		t.Fatal("should have escaped the wildcard")
+	}
+}
+
 func testTeamStoreSearchOpen(t *testing.T, ss store.Store) {
 	o1 := model.Team{}
 	o1.DisplayName = "ADisplayName" + model.NewId()
@@ -432,6 +535,79 @@ func testGetAllTeamListing(t *testing.T, ss store.Store) {
 	}
 }
 
+func testTeamStoreGetAllPage(t *testing.T, ss store.Store) {
+	for i := 0; i < 3; i++ {
+		team := model.Team{
+			DisplayName: "DisplayName",
+			Name:        "z-z-z" + model.NewId() + "b",
+			Email:       model.NewId() + "@nowhere.com",
+			Type:        model.TEAM_OPEN,
+		}
+		store.Must(ss.Team().Save(&team))
+	}
+
+	// Teams are paged in order of id, so that paging through them visits each one once
+	teams := store.Must(ss.Team().GetAllPage(0, 10000)).([]*model.Team)
+	if len(teams) < 3 {
+		t.Fatal("should have returned all the teams")
+	}
+	for i := 1; i < len(teams); i++ {
+		if teams[i-1].Id >= teams[i].Id {
+			t.Fatal("teams should be ordered by id")
+		}
+	}
+
+	first := store.Must(ss.Team().GetAllPage(0, 1)).([]*model.Team)
+	second := store.Must(ss.Team().GetAllPage(1, 1)).([]*model.Team)
+	if len(first) != 1 || len(second) != 1 || first[0].Id >= second[0].Id {
+		t.Fatal("pages should follow each other in order of id")
+	}
+}
+
+func testTeamStoreGetAllPageFiltered(t *testing.T, ss store.Store) {
+	newTeam := func(teamType string) *model.Team {
+		team := &model.Team{
+			DisplayName: "DisplayName",
+			Name:        "z-z-z" + model.NewId() + "b",
+			Email:       model.NewId() + "@nowhere.com",
+			Type:        teamType,
+		}
+		return store.Must(ss.Team().Save(team)).(*model.Team)
+	}
+
+	open := newTeam(model.TEAM_OPEN)
+	invite := newTeam(model.TEAM_INVITE)
+	archived := newTeam(model.TEAM_OPEN)
+	archived.DeleteAt = model.GetMillis()
+	store.Must(ss.Team().Update(archived))
+
+	ids := func(teamType string, includeDeleted bool) map[string]bool {
+		teams := store.Must(ss.Team().GetAllPageFiltered(teamType, includeDeleted, 0, 10000)).([]*model.Team)
+		found := map[string]bool{}
+		for i, team := range teams {
+			if i > 0 && teams[i-1].Id >= team.Id {
+				t.Fatal("teams should be ordered by id")
+			}
+			found[team.Id] = true
+		}
+		return found
+	}
+
+	if found := ids("", false); !found[open.Id] || !found[invite.Id] || found[archived.Id] {
+		t.Fatal("should have returned the active teams of every type")
+	}
+	if found := ids(model.TEAM_OPEN, true); !found[open.Id] || found[invite.Id] || !found[archived.Id] {
+		t.Fatal("should have returned the open teams, including archived ones")
+	}
+	if found := ids(model.TEAM_INVITE, false); found[open.Id] || !found[invite.Id] || found[archived.Id] {
+		t.Fatal("should have returned the active invite only teams")
+	}
+
+	if teams := store.Must(ss.Team().GetAllPageFiltered("", true, 0, 1)).([]*model.Team); len(teams) != 1 {
+		t.Fatal("should have returned a single team")
+	}
+}
+
 func testGetAllTeamPageListing(t *testing.T, ss store.Store) {
 	o1 := model.Team{}
 	o1.DisplayName = "DisplayName"
@@ -584,6 +760,10 @@ func testTeamMembers(t *testing.T, ss store.Store) {
 		if len(ms) != 2 {
 			t.Fatal()
 		}
+
+		if ms[0].UserId >= ms[1].UserId {
+			t.Fatal("members should be ordered by user id")
+		}
 	}
 
 	if r1 := <-ss.Team().GetMembers(teamId2, 0, 100); r1.Err != nil {
@@ -906,6 +1086,20 @@ func testTeamStoreMemberCount(t *testing.T, ss store.Store) {
 			t.Fatal("wrong count")
 		}
 	}
+
+	teamId2 := model.NewId()
+	store.Must(ss.Team().SaveMember(&model.TeamMember{TeamId: teamId2, UserId: u1.Id}, -1))
+	store.Must(ss.Team().SaveMember(&model.TeamMember{TeamId: teamId2, UserId: u2.Id}, -1))
+	store.Must(ss.Team().SaveMember(&model.TeamMember{TeamId: model.NewId(), UserId: u2.Id}, -1))
+
+	if result := <-ss.Team().CountActiveMembersByTeam(); result.Err != nil {
+		t.Fatal(result.Err)
+	} else {
+		counts := result.Data.(map[string]int64)
+		if counts[teamId1] != 1 || counts[teamId2] != 1 {
+			t.Fatal("wrong counts")
+		}
+	}
 }
 
 func testGetChannelUnreadsForAllTeams(t *testing.T, ss store.Store) {
@@ -14,6 +14,7 @@ func TestUserAccessTokenStore(t *testing.T, ss store.Store) {
 	t.Run("UserAccessTokenSaveGetDelete", func(t *testing.T) { testUserAccessTokenSaveGetDelete(t, ss) })
 	t.Run("UserAccessTokenDisableEnable", func(t *testing.T) { testUserAccessTokenDisableEnable(t, ss) })
 	t.Run("UserAccessTokenSearch", func(t *testing.T) { testUserAccessTokenSearch(t, ss) })
+	t.Run("UserAccessTokenCountActiveByTeam", func(t *testing.T) { testUserAccessTokenCountActiveByTeam(t, ss) })
 }
 
 func testUserAccessTokenSaveGetDelete(t *testing.T, ss store.Store) {
@@ -173,3 +174,32 @@ func testUserAccessTokenSearch(t *testing.T, ss store.Store) {
 		t.Fatal("received incorrect number of tokens after search")
 	}
 }
+
+func testUserAccessTokenCountActiveByTeam(t *testing.T, ss store.Store) {
+	teamId1 := model.NewId()
+	teamId2 := model.NewId()
+	userId1 := model.NewId()
+	userId2 := model.NewId()
+
+	store.Must(ss.Team().SaveMember(&model.TeamMember{TeamId: teamId1, UserId: userId1}, -1))
+	store.Must(ss.Team().SaveMember(&model.TeamMember{TeamId: teamId1, UserId: userId2}, -1))
+	store.Must(ss.Team().SaveMember(&model.TeamMember{TeamId: teamId2, UserId: userId2}, -1))
+
+	store.Must(ss.UserAccessToken().Save(&model.UserAccessToken{Token: model.NewId(), UserId: userId1, Description: "token1"}))
+	store.Must(ss.UserAccessToken().Save(&model.UserAccessToken{Token: model.NewId(), UserId: userId2, Description: "token2"}))
+	disabled := store.Must(ss.UserAccessToken().Save(&model.UserAccessToken{Token: model.NewId(), UserId: userId2, Description: "token3"})).(*model.UserAccessToken)
+	store.Must(ss.UserAccessToken().UpdateTokenDisable(disabled.Id))
+
+	result := <-ss.UserAccessToken().CountActiveByTeam()
+	if result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	counts := result.Data.(map[string]int64)
+	if counts[teamId1] != 2 {
+		t.Fatalf("expected 2 active tokens for team1, got %v", counts[teamId1])
+	}
+	if counts[teamId2] != 1 {
+		t.Fatalf("expected 1 active token for team2, got %v", counts[teamId2])
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/mattermost/mattermost-server/model"
 	"github.com/mattermost/mattermost-server/store"
@@ -33,6 +34,8 @@ func TestUserStore(t *testing.T, ss store.Store) {
 	t.Run("GetProfilesByUsernames", func(t *testing.T) { testUserStoreGetProfilesByUsernames(t, ss) })
 	t.Run("GetSystemAdminProfiles", func(t *testing.T) { testUserStoreGetSystemAdminProfiles(t, ss) })
 	t.Run("GetByEmail", func(t *testing.T) { testUserStoreGetByEmail(t, ss) })
+	t.Run("GetByEmails", func(t *testing.T) { testUserStoreGetByEmails(t, ss) })
+	t.Run("GetByIds", func(t *testing.T) { testUserStoreGetByIds(t, ss) })
 	t.Run("GetByAuthData", func(t *testing.T) { testUserStoreGetByAuthData(t, ss) })
 	t.Run("GetByUsername", func(t *testing.T) { testUserStoreGetByUsername(t, ss) })
 	t.Run("GetForLogin", func(t *testing.T) { testUserStoreGetForLogin(t, ss) })
@@ -1030,6 +1033,50 @@ func testUserStoreGetByEmail(t *testing.T, ss store.Store) {
 	}
 }
 
+func testUserStoreGetByEmails(t *testing.T, ss store.Store) {
+	u1 := &model.User{}
+	u1.Email = model.NewId() + "@example.com"
+	u1.Password = "password"
+	store.Must(ss.User().Save(u1))
+
+	u2 := &model.User{}
+	u2.Email = model.NewId() + "@example.com"
+	store.Must(ss.User().Save(u2))
+
+	users := store.Must(ss.User().GetByEmails([]string{strings.ToUpper(u1.Email), u2.Email, model.NewId() + "@example.com"})).([]*model.User)
+	require.Len(t, users, 2)
+	for _, user := range users {
+		require.Contains(t, []string{u1.Id, u2.Id}, user.Id)
+		if user.Id == u1.Id {
+			require.NotEmpty(t, user.Password, "users shouldn't be sanitized")
+		}
+	}
+
+	require.Empty(t, store.Must(ss.User().GetByEmails([]string{})).([]*model.User))
+}
+
+func testUserStoreGetByIds(t *testing.T, ss store.Store) {
+	u1 := &model.User{}
+	u1.Email = model.NewId()
+	u1.Password = "password"
+	store.Must(ss.User().Save(u1))
+
+	u2 := &model.User{}
+	u2.Email = model.NewId()
+	store.Must(ss.User().Save(u2))
+
+	users := store.Must(ss.User().GetByIds([]string{u1.Id, u2.Id, model.NewId()})).([]*model.User)
+	require.Len(t, users, 2)
+	for _, user := range users {
+		require.Contains(t, []string{u1.Id, u2.Id}, user.Id)
+		if user.Id == u1.Id {
+			require.NotEmpty(t, user.Password, "users shouldn't be sanitized")
+		}
+	}
+
+	require.Empty(t, store.Must(ss.User().GetByIds([]string{})).([]*model.User))
+}
+
 func testUserStoreGetByAuthData(t *testing.T, ss store.Store) {
 	teamId := model.NewId()
 
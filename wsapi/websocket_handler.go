@@ -29,6 +29,7 @@ func (wh webSocketHandler) ServeWebSocket(conn *app.WebConn, r *model.WebSocketR
 	if sessionErr != nil {
 		l4g.Error(utils.T("api.web_socket_handler.log.error"), "websocket", r.Action, r.Seq, conn.UserId, sessionErr.SystemMessage(utils.T), sessionErr.Error())
 		sessionErr.DetailedError = ""
+		sessionErr.Params = nil
 		errResp := model.NewWebSocketError(r.Seq, sessionErr)
 
 		conn.Send <- errResp
@@ -45,6 +46,7 @@ func (wh webSocketHandler) ServeWebSocket(conn *app.WebConn, r *model.WebSocketR
 	if data, err = wh.handlerFunc(r); err != nil {
 		l4g.Error(utils.T("api.web_socket_handler.log.error"), "websocket", r.Action, r.Seq, r.Session.UserId, err.SystemMessage(utils.T), err.DetailedError)
 		err.DetailedError = ""
+		err.Params = nil
 		errResp := model.NewWebSocketError(r.Seq, err)
 
 		conn.Send <- errResp